@@ -0,0 +1,163 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/keystore"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+const usage = `Usage: dexcon-keytool <command> [options]
+
+Commands:
+  generate -out <keyfile>             generate a new key
+  import   -hex <hexfile> -out <keyfile>
+                                      import a hex encoded private key
+  export   -key <keyfile>             print the private key in hex
+  inspect  -key <keyfile>             print node ID and public key
+
+The password is read from the file given by -password-file, or from the
+first line of stdin.
+`
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "error: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+func readPassword(path string) string {
+	var line string
+	if path != "" {
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			fatal("unable to read password file: %s", err)
+		}
+		line = strings.SplitN(string(buf), "\n", 2)[0]
+	} else {
+		fmt.Fprint(os.Stderr, "Password: ")
+		var err error
+		line, err = bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fatal("unable to read password: %s", err)
+		}
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+func printInfo(nID types.NodeID, pubKey []byte) {
+	fmt.Printf("node ID:    %s\n", hex.EncodeToString(nID.Hash[:]))
+	fmt.Printf("public key: %s\n", hex.EncodeToString(pubKey))
+}
+
+func writeKey(path, passwordFile string, prvKey *ecdsa.PrivateKey,
+	lightKDF bool) {
+	if _, err := os.Stat(path); err == nil {
+		fatal("%s already exists", path)
+	}
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if lightKDF {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	err := keystore.WriteKeyFile(
+		path, prvKey, readPassword(passwordFile), scryptN, scryptP)
+	if err != nil {
+		fatal("unable to write key file: %s", err)
+	}
+	printInfo(types.NewNodeID(prvKey.PublicKey()), prvKey.PublicKey().Bytes())
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	out := fs.String("out", "", "path of the key file to write")
+	keyFile := fs.String("key", "", "path of the key file to read")
+	hexFile := fs.String("hex", "", "path of the hex encoded private key")
+	passwordFile := fs.String("password-file", "",
+		"path of the file containing the password")
+	lightKDF := fs.Bool("light-kdf", false,
+		"use weaker scrypt parameters, for testing only")
+	fs.Parse(os.Args[2:])
+
+	switch cmd {
+	case "generate":
+		if *out == "" {
+			fatal("-out should be specified")
+		}
+		prvKey, err := ecdsa.NewPrivateKey()
+		if err != nil {
+			fatal("unable to generate key: %s", err)
+		}
+		writeKey(*out, *passwordFile, prvKey, *lightKDF)
+	case "import":
+		if *out == "" || *hexFile == "" {
+			fatal("-hex and -out should be specified")
+		}
+		buf, err := ioutil.ReadFile(*hexFile)
+		if err != nil {
+			fatal("unable to read hex file: %s", err)
+		}
+		b, err := hex.DecodeString(strings.TrimSpace(string(buf)))
+		if err != nil {
+			fatal("invalid hex: %s", err)
+		}
+		prvKey, err := ecdsa.NewPrivateKeyFromBytes(b)
+		if err != nil {
+			fatal("invalid private key: %s", err)
+		}
+		writeKey(*out, *passwordFile, prvKey, *lightKDF)
+	case "export":
+		if *keyFile == "" {
+			fatal("-key should be specified")
+		}
+		prvKey, err := keystore.ReadKeyFile(*keyFile, readPassword(*passwordFile))
+		if err != nil {
+			fatal("unable to read key file: %s", err)
+		}
+		fmt.Println(hex.EncodeToString(prvKey.Bytes()))
+	case "inspect":
+		if *keyFile == "" {
+			fatal("-key should be specified")
+		}
+		data, err := ioutil.ReadFile(*keyFile)
+		if err != nil {
+			fatal("unable to read key file: %s", err)
+		}
+		info, err := keystore.Inspect(data)
+		if err != nil {
+			fatal("invalid key file: %s", err)
+		}
+		printInfo(info.NodeID, info.PublicKey)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/keystore"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/remote"
+)
+
+var network = flag.String("network", "unix", "network to listen, unix or tcp")
+var address = flag.String("address", "", "address to listen")
+var keyFile = flag.String("key", "", "path to the file of hex encoded private key")
+var passwordFile = flag.String("password-file", "",
+	"path to the password of key file, the key file is treated as an "+
+		"encrypted key file created by dexcon-keytool if specified")
+var dkgKeyDir = flag.String("dkg-keys", "",
+	"path to the directory to keep DKG private shares")
+
+func loadKey(path string) (*ecdsa.PrivateKey, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if *passwordFile != "" {
+		password, err := ioutil.ReadFile(*passwordFile)
+		if err != nil {
+			return nil, err
+		}
+		return keystore.DecryptKey(
+			buf, strings.SplitN(string(password), "\n", 2)[0])
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(string(buf)))
+	if err != nil {
+		return nil, err
+	}
+	return ecdsa.NewPrivateKeyFromBytes(b)
+}
+
+// checkAddress refuses TCP addresses other than loopback ones, there is no
+// authentication in the protocol.
+func checkAddress(network, address string) error {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("only loopback address is allowed: %s", address)
+	}
+	return nil
+}
+
+func main() {
+	flag.Parse()
+	if *address == "" || *keyFile == "" {
+		fmt.Fprintln(os.Stderr, "error: address and key should be specified")
+		os.Exit(1)
+	}
+	prvKey, err := loadKey(*keyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: unable to load key: %s\n", err)
+		os.Exit(1)
+	}
+	if err := checkAddress(*network, *address); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+	// Only the owner could access the socket and files created from now on.
+	syscall.Umask(0077)
+	var dkgKeys remote.DKGKeyStore
+	if *dkgKeyDir != "" {
+		if dkgKeys, err = remote.NewDKGKeyDir(*dkgKeyDir); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	l, err := net.Listen(*network, *address)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: unable to listen: %s\n", err)
+		os.Exit(1)
+	}
+	logger := common.NewCustomLogger(log.New(os.Stderr, "", log.LstdFlags))
+	server := remote.NewServer(prvKey, dkgKeys, logger)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		server.Close()
+	}()
+	log.Printf("serving node %s at %s %s",
+		hex.EncodeToString(prvKey.PublicKey().Bytes()), *network, *address)
+	if err := server.Serve(l); err != nil {
+		log.Println("server stopped:", err)
+	}
+}
@@ -45,6 +45,10 @@ var (
 const maxResultCache = 100
 const settingLimit = 3
 
+// maxVoteCachePositions is the count of positions to keep votes for serving
+// pull requests from peers.
+const maxVoteCachePositions = 128
+
 // genValidLeader generate a validLeader function for agreement modules.
 func genValidLeader(
 	mgr *agreementMgr) validLeaderFn {
@@ -52,7 +56,7 @@ func genValidLeader(
 		if block.Timestamp.After(time.Now()) {
 			return false, nil
 		}
-		if block.Position.Round >= DKGDelayRound {
+		if block.Position.Round >= mgr.params.DKGDelayRound {
 			if mgr.recv.npks == nil {
 				return false, nil
 			}
@@ -63,7 +67,8 @@ func genValidLeader(
 				return false, ErrBlockTooOld
 			}
 		}
-		if !utils.VerifyCRSSignature(block, crs, mgr.recv.npks) {
+		if !utils.VerifyCRSSignature(
+			block, crs, mgr.recv.npks, mgr.params.DKGDelayRound) {
 			return false, ErrIncorrectCRSSignature
 		}
 		if err := mgr.bcModule.sanityCheck(block); err != nil {
@@ -87,15 +92,17 @@ func genValidLeader(
 type agreementMgrConfig struct {
 	utils.RoundBasedConfig
 
-	notarySetSize uint32
-	lambdaBA      time.Duration
-	crs           common.Hash
+	notarySetSize    uint32
+	lambdaBA         time.Duration
+	minBlockInterval time.Duration
+	crs              common.Hash
 }
 
 func (c *agreementMgrConfig) from(
 	round uint64, config *types.Config, crs common.Hash) {
 	c.notarySetSize = config.NotarySetSize
 	c.lambdaBA = config.LambdaBA
+	c.minBlockInterval = config.MinBlockInterval
 	c.crs = crs
 	c.SetupRoundBasedFields(round, config)
 }
@@ -126,6 +133,7 @@ type agreementMgr struct {
 	logger            common.Logger
 	cache             *utils.NodeSetCache
 	signer            *utils.Signer
+	params            types.Params
 	bcModule          *blockChain
 	ctx               context.Context
 	configs           []agreementMgrConfig
@@ -134,6 +142,7 @@ type agreementMgr struct {
 	processedBAResult map[types.Position]struct{}
 	voteFilter        *utils.VoteFilter
 	settingCache      *lru.Cache
+	voteCache         *voteCache
 	curRoundSetting   *baRoundSetting
 	waitGroup         sync.WaitGroup
 	isRunning         bool
@@ -151,11 +160,13 @@ func newAgreementMgr(con *Consensus) (mgr *agreementMgr, err error) {
 		logger:            con.logger,
 		cache:             con.nodeSetCache,
 		signer:            con.signer,
+		params:            con.params,
 		bcModule:          con.bcModule,
 		ctx:               con.ctx,
 		processedBAResult: make(map[types.Position]struct{}, maxResultCache),
 		voteFilter:        utils.NewVoteFilter(),
 		settingCache:      settingCache,
+		voteCache:         newVoteCache(maxVoteCachePositions),
 	}
 	mgr.recv = &consensusBAReceiver{
 		consensus:     con,
@@ -171,7 +182,9 @@ func (mgr *agreementMgr) prepare() {
 		mgr.recv,
 		newLeaderSelector(genValidLeader(mgr), mgr.logger),
 		mgr.signer,
+		mgr.params,
 		mgr.logger)
+	agr.sigCache = mgr.con.sigCache
 	setting := mgr.generateSetting(round)
 	if setting == nil {
 		mgr.logger.Warn("Unable to prepare init setting", "round", round)
@@ -182,7 +195,7 @@ func (mgr *agreementMgr) prepare() {
 	// Hacky way to make agreement module self contained.
 	mgr.recv.agreementModule = agr
 	mgr.baModule = agr
-	if round >= DKGDelayRound {
+	if round >= mgr.params.DKGDelayRound {
 		if _, exist := setting.dkgSet[mgr.ID]; exist {
 			mgr.logger.Debug("Preparing signer and npks.", "round", round)
 			npk, signer, err := mgr.con.cfgModule.getDKGInfo(round, false)
@@ -302,6 +315,7 @@ func (mgr *agreementMgr) processVote(v *types.Vote) (err error) {
 	if err = mgr.baModule.processVote(v); err == nil {
 		mgr.baModule.updateFilter(mgr.voteFilter)
 		mgr.voteFilter.AddVote(v)
+		mgr.voteCache.add(v)
 	}
 	if err == ErrSkipButNoError {
 		err = nil
@@ -309,6 +323,11 @@ func (mgr *agreementMgr) processVote(v *types.Vote) (err error) {
 	return
 }
 
+// votes returns cached votes of a position.
+func (mgr *agreementMgr) votes(pos types.Position) []*types.Vote {
+	return mgr.voteCache.get(pos)
+}
+
 func (mgr *agreementMgr) processBlock(b *types.Block) error {
 	if err := mgr.checkProposer(b.Position.Round, b.ProposerID); err != nil {
 		return err
@@ -347,7 +366,7 @@ func (mgr *agreementMgr) processAgreementResult(
 	}
 	if result.Position == aID && !mgr.baModule.confirmed() {
 		mgr.logger.Info("Syncing BA", "position", result.Position)
-		if result.Position.Round >= DKGDelayRound {
+		if result.Position.Round >= mgr.params.DKGDelayRound {
 			return mgr.baModule.processAgreementResult(result)
 		}
 		for key := range result.Votes {
@@ -357,7 +376,7 @@ func (mgr *agreementMgr) processAgreementResult(
 		}
 	} else if result.Position.Newer(aID) {
 		mgr.logger.Info("Fast syncing BA", "position", result.Position)
-		if result.Position.Round < DKGDelayRound {
+		if result.Position.Round < mgr.params.DKGDelayRound {
 			mgr.logger.Debug("Calling Network.PullBlocks for fast syncing BA",
 				"hash", result.BlockHash)
 			mgr.network.PullBlocks(common.Hashes{result.BlockHash})
@@ -381,7 +400,7 @@ func (mgr *agreementMgr) processAgreementResult(
 		mgr.baModule.restart(
 			setting.dkgSet, setting.threshold,
 			result.Position, leader, setting.crs)
-		if result.Position.Round >= DKGDelayRound {
+		if result.Position.Round >= mgr.params.DKGDelayRound {
 			return mgr.baModule.processAgreementResult(result)
 		}
 	}
@@ -417,7 +436,7 @@ func (mgr *agreementMgr) generateSetting(round uint64) *baRoundSetting {
 		return nil
 	}
 	var dkgSet map[types.NodeID]struct{}
-	if round >= DKGDelayRound {
+	if round >= mgr.params.DKGDelayRound {
 		_, qualidifed, err := typesDKG.CalcQualifyNodes(
 			mgr.gov.DKGMasterPublicKeys(round),
 			mgr.gov.DKGComplaints(round),
@@ -507,7 +526,7 @@ Loop:
 		mgr.voteFilter = utils.NewVoteFilter()
 		mgr.voteFilter.Position.Round = currentRound
 		mgr.recv.emptyBlockHashMap = &sync.Map{}
-		if currentRound >= DKGDelayRound && mgr.recv.isNotary {
+		if currentRound >= mgr.params.DKGDelayRound && mgr.recv.isNotary {
 			var err error
 			mgr.recv.npks, mgr.recv.psigSigner, err =
 				mgr.con.cfgModule.getDKGInfo(currentRound, false)
@@ -538,6 +557,11 @@ func (mgr *agreementMgr) baRoutineForOneRound(
 	agr := mgr.baModule
 	recv := mgr.recv
 	oldPos := agr.agreementID()
+	// Position and period of agreement after last state transition.
+	var (
+		lastPos    types.Position
+		lastPeriod uint64
+	)
 	restart := func(restartPos types.Position) (breakLoop bool, err error) {
 		if !isStop(restartPos) {
 			if restartPos.Height+1 >= mgr.config(setting.round).RoundEndHeight() {
@@ -600,6 +624,11 @@ func (mgr *agreementMgr) baRoutineForOneRound(
 		if err != nil {
 			return
 		}
+		if nb, ok := mgr.app.(*nonBlocking); ok {
+			// Slow down if the application is falling behind.
+			nextTime = nextTime.Add(nb.throttle(
+				mgr.config(setting.round).minBlockInterval))
+		}
 		time.Sleep(nextTime.Sub(time.Now()))
 		setting.ticker.Restart()
 		agr.restart(setting.dkgSet, setting.threshold, nextPos, leader, setting.crs)
@@ -652,6 +681,16 @@ Loop:
 				"error", err)
 			break Loop
 		}
+		if pos, period, _ := agr.status(); pos != lastPos ||
+			period != lastPeriod {
+			if pos == lastPos {
+				mgr.con.events.publish(&BAPeriodAdvancedEvent{
+					Position: pos,
+					Period:   period,
+				})
+			}
+			lastPos, lastPeriod = pos, period
+		}
 		if agr.pullVotes() {
 			pos := agr.agreementID()
 			mgr.logger.Debug("Calling Network.PullVotes for syncing votes",
@@ -674,3 +713,48 @@ Loop:
 	}
 	return nil
 }
+
+// voteCache keeps votes of recent positions, the oldest position is purged
+// when the count of positions exceeds the limit.
+type voteCache struct {
+	lock      sync.RWMutex
+	limit     int
+	votes     map[types.Position]map[types.VoteHeader]*types.Vote
+	positions []types.Position
+}
+
+func newVoteCache(limit int) *voteCache {
+	return &voteCache{
+		limit: limit,
+		votes: make(map[types.Position]map[types.VoteHeader]*types.Vote),
+	}
+}
+
+func (c *voteCache) add(v *types.Vote) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	votes, exists := c.votes[v.Position]
+	if !exists {
+		if len(c.positions) >= c.limit {
+			delete(c.votes, c.positions[0])
+			c.positions = c.positions[1:]
+		}
+		votes = make(map[types.VoteHeader]*types.Vote)
+		c.votes[v.Position] = votes
+		c.positions = append(c.positions, v.Position)
+	}
+	if _, exists := votes[v.VoteHeader]; exists {
+		return
+	}
+	votes[v.VoteHeader] = v.Clone()
+}
+
+func (c *voteCache) get(pos types.Position) []*types.Vote {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	votes := make([]*types.Vote, 0, len(c.votes[pos]))
+	for _, v := range c.votes[pos] {
+		votes = append(votes, v.Clone())
+	}
+	return votes
+}
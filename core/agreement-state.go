@@ -44,6 +44,28 @@ const (
 	stateSleep
 )
 
+func (s agreementStateType) String() string {
+	switch s {
+	case stateFast:
+		return "fast"
+	case stateFastVote:
+		return "fast-vote"
+	case stateInitial:
+		return "initial"
+	case statePreCommit:
+		return "pre-commit"
+	case stateCommit:
+		return "commit"
+	case stateForward:
+		return "forward"
+	case statePullVote:
+		return "pull-vote"
+	case stateSleep:
+		return "sleep"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
 type agreementState interface {
 	state() agreementStateType
 	nextState() (agreementState, error)
@@ -74,7 +74,8 @@ func (s *AgreementStateTestSuite) proposeBlock(
 		Position:   types.Position{Height: types.GenesisHeight},
 		Hash:       common.NewRandomHash(),
 	}
-	s.Require().NoError(s.signers[s.ID].SignCRS(block, leader.hashCRS))
+	s.Require().NoError(
+		s.signers[s.ID].SignCRS(block, leader.hashCRS, DKGDelayRound))
 	s.Require().NoError(s.signers[s.ID].SignBlock(block))
 	s.block[block.Hash] = block
 	return block
@@ -124,6 +125,7 @@ func (s *AgreementStateTestSuite) newAgreement(numNode int) *agreement {
 		},
 		leader,
 		s.signers[s.ID],
+		types.DefaultParams(),
 		logger,
 	)
 	agreement.restart(notarySet,
@@ -217,7 +219,7 @@ func (s *AgreementStateTestSuite) TestPreCommitState() {
 		signer := utils.NewSigner(prv)
 		blocks[i].ProposerID = types.NewNodeID(prv.PublicKey())
 		s.Require().NoError(signer.SignCRS(
-			blocks[i], a.data.leader.hashCRS))
+			blocks[i], a.data.leader.hashCRS, DKGDelayRound))
 		s.Require().NoError(signer.SignBlock(blocks[i]))
 		s.Require().NoError(a.processBlock(blocks[i]))
 	}
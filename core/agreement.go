@@ -131,7 +131,10 @@ type agreement struct {
 	candidateBlock         map[common.Hash]*types.Block
 	fastForward            chan uint64
 	signer                 *utils.Signer
+	params                 types.Params
+	sigCache               *signatureCache
 	logger                 common.Logger
+	startTime              time.Time
 }
 
 // newAgreement creates a agreement instance.
@@ -140,6 +143,7 @@ func newAgreement(
 	recv agreementReceiver,
 	leader *leaderSelector,
 	signer *utils.Signer,
+	params types.Params,
 	logger common.Logger) *agreement {
 	agreement := &agreement{
 		data: &agreementData{
@@ -152,6 +156,7 @@ func newAgreement(
 		candidateBlock:         make(map[common.Hash]*types.Block),
 		fastForward:            make(chan uint64, 1),
 		signer:                 signer,
+		params:                 params,
 		logger:                 logger,
 	}
 	agreement.stop()
@@ -197,6 +202,7 @@ func (a *agreement) restart(
 		a.state = newFastState(a.data)
 		a.notarySet = notarySet
 		a.candidateBlock = make(map[common.Hash]*types.Block)
+		a.startTime = time.Now()
 		a.aID.Store(struct {
 			pos    types.Position
 			leader types.NodeID
@@ -237,7 +243,7 @@ func (a *agreement) restart(
 				continue
 			} else if pending.block.Position == aID {
 				if result == nil ||
-					result.Position.Round < DKGDelayRound ||
+					result.Position.Round < a.params.DKGDelayRound ||
 					result.BlockHash == pending.block.Hash {
 					replayBlock = append(replayBlock, pending.block)
 				}
@@ -257,7 +263,8 @@ func (a *agreement) restart(
 			if aID.Newer(pending.vote.Position) {
 				continue
 			} else if pending.vote.Position == aID {
-				if result == nil || result.Position.Round < DKGDelayRound {
+				if result == nil ||
+					result.Position.Round < a.params.DKGDelayRound {
 					replayVote = append(replayVote, pending.vote)
 				}
 			} else if pending.receivedTime.After(expireTime) {
@@ -345,6 +352,15 @@ func (a *agreement) leader() types.NodeID {
 	}).leader
 }
 
+// status returns the position, period and state of current agreement.
+func (a *agreement) status() (types.Position, uint64, agreementStateType) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	a.data.lock.RLock()
+	defer a.data.lock.RUnlock()
+	return a.agreementID(), a.data.period, a.state.state()
+}
+
 // nextState is called at the specific clock time.
 func (a *agreement) nextState() (err error) {
 	a.lock.Lock()
@@ -361,7 +377,7 @@ func (a *agreement) sanityCheck(vote *types.Vote) error {
 	if vote.Type >= types.MaxVoteType {
 		return ErrInvalidVote
 	}
-	ok, err := utils.VerifyVoteSignature(vote)
+	ok, err := a.sigCache.verifyVoteSignature(vote)
 	if err != nil {
 		return err
 	}
@@ -656,7 +672,7 @@ func (a *agreement) processBlock(block *types.Block) error {
 	if checkSkip() {
 		return nil
 	}
-	if err := utils.VerifyBlockSignature(block); err != nil {
+	if err := a.sigCache.verifyBlockSignature(block); err != nil {
 		return err
 	}
 
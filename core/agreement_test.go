@@ -88,7 +88,7 @@ func (s *AgreementTestSuite) proposeBlock(
 	}
 	signer, exist := s.signers[block.ProposerID]
 	s.Require().True(exist)
-	s.Require().NoError(signer.SignCRS(block, crs))
+	s.Require().NoError(signer.SignCRS(block, crs, DKGDelayRound))
 	s.Require().NoError(signer.SignBlock(block))
 	s.block[block.Hash] = block
 
@@ -161,6 +161,7 @@ func (s *AgreementTestSuite) newAgreement(
 		},
 		leader,
 		s.signers[s.ID],
+		types.DefaultParams(),
 		logger,
 	)
 	agreement.restart(notarySet, utils.GetBAThreshold(&types.Config{
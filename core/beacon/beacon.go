@@ -0,0 +1,118 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+// Package beacon provides verifiable randomness derived from the randomness
+// of finalized blocks, which is the threshold signature of a block hash
+// signed by the DKG set of that round.
+package beacon
+
+import (
+	"fmt"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// Errors for beacon.
+var (
+	ErrRandomnessNotAvailable = fmt.Errorf(
+		"randomness is not available before DKGDelayRound")
+	ErrIncorrectRandomness = fmt.Errorf(
+		"incorrect randomness")
+)
+
+// Proof proves that a randomness is generated for a finalized block, it could
+// be verified by anyone knowing the group public key of that round.
+type Proof struct {
+	Position   types.Position `json:"position"`
+	BlockHash  common.Hash    `json:"block_hash"`
+	Randomness []byte         `json:"randomness"`
+}
+
+// NewProof creates a proof from a finalized block.
+func NewProof(b *types.Block) *Proof {
+	return &Proof{
+		Position:   b.Position,
+		BlockHash:  b.Hash,
+		Randomness: common.CopyBytes(b.Randomness),
+	}
+}
+
+// Verify verifies the proof with the group public key of its round, ex.
+// typesDKG.GroupPublicKey.
+func (p *Proof) Verify(gpk core.TSigVerifier) bool {
+	return gpk.VerifySignature(p.BlockHash, crypto.Signature{
+		Type:      "bls",
+		Signature: p.Randomness,
+	})
+}
+
+// NewSource creates a source of random numbers from the randomness in the
+// proof, 'label' separates sources for different purposes.
+func (p *Proof) NewSource(label string) *Source {
+	return NewSource(p.Randomness, label)
+}
+
+// Verifier verifies randomness with group public keys from governance.
+type Verifier struct {
+	cache  *core.TSigVerifierCache
+	params types.Params
+}
+
+// NewVerifier creates a Verifier instance.
+func NewVerifier(cache *core.TSigVerifierCache, params types.Params) *Verifier {
+	return &Verifier{
+		cache:  cache,
+		params: params,
+	}
+}
+
+// Verify checks if the randomness is the threshold signature of the block
+// hash by the DKG set of that round.
+func (v *Verifier) Verify(
+	round uint64, blockHash common.Hash, randomness []byte) error {
+	// Randomness of rounds before DKGDelayRound is a fixed value.
+	if round < v.params.DKGDelayRound {
+		return ErrRandomnessNotAvailable
+	}
+	verifier, ok, err := v.cache.UpdateAndGet(round)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return core.ErrTSigNotReady
+	}
+	if !verifier.VerifySignature(blockHash, crypto.Signature{
+		Type:      "bls",
+		Signature: randomness,
+	}) {
+		return ErrIncorrectRandomness
+	}
+	return nil
+}
+
+// VerifyBlock checks the randomness of a finalized block.
+func (v *Verifier) VerifyBlock(b *types.Block) error {
+	return v.Verify(b.Position.Round, b.Hash, b.Randomness)
+}
+
+// VerifyProof checks the randomness in a proof.
+func (v *Verifier) VerifyProof(p *Proof) error {
+	return v.Verify(p.Position.Round, p.BlockHash, p.Randomness)
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package beacon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	typesDKG "github.com/dexon-foundation/dexon-consensus/core/types/dkg"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+type BeaconTestSuite struct {
+	suite.Suite
+}
+
+func (s *BeaconTestSuite) TestVerify() {
+	var (
+		round  = uint64(1)
+		params = types.DefaultParams()
+	)
+	prvKeys, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	gov, err := test.NewGovernance(test.NewState(params, pubKeys,
+		100*time.Millisecond, &common.NullLogger{}, true),
+		params.ConfigRoundShift)
+	s.Require().NoError(err)
+	v := NewVerifier(core.NewTSigVerifierCache(gov, 5), params)
+	b := &types.Block{
+		Hash:     common.NewRandomHash(),
+		Position: types.Position{Round: round, Height: 10},
+	}
+	// DKG is not ready yet.
+	s.Equal(core.ErrTSigNotReady, v.VerifyBlock(b))
+	gs, err := test.PrepareDKG(gov, round, prvKeys)
+	s.Require().NoError(err)
+	sig, err := gs.Sign(b.Hash)
+	s.Require().NoError(err)
+	b.Randomness = sig.Signature
+	s.Require().NoError(v.VerifyBlock(b))
+	proof := NewProof(b)
+	s.Require().NoError(v.VerifyProof(proof))
+	// Third parties could verify the proof with group public key.
+	gpk, err := typesDKG.NewGroupPublicKey(round,
+		gov.DKGMasterPublicKeys(round),
+		gov.DKGComplaints(round),
+		utils.GetDKGThreshold(gov.Configuration(round)))
+	s.Require().NoError(err)
+	s.True(proof.Verify(gpk))
+	// Randomness for another block.
+	proof.BlockHash = common.NewRandomHash()
+	s.Equal(ErrIncorrectRandomness, v.VerifyProof(proof))
+	s.False(proof.Verify(gpk))
+	proof.BlockHash = b.Hash
+	// The same randomness generates the same source.
+	s.Equal(proof.NewSource("lottery").Uint64(),
+		NewSource(b.Randomness, "lottery").Uint64())
+	// Randomness before DKGDelayRound is not random.
+	b.Position.Round = 0
+	b.Randomness = params.NoRand
+	s.Equal(ErrRandomnessNotAvailable, v.VerifyBlock(b))
+}
+
+func TestBeacon(t *testing.T) {
+	suite.Run(t, new(BeaconTestSuite))
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package beacon
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+)
+
+// Source is a deterministic generator of random numbers seeded by a
+// randomness. Sources created from the same randomness and label always
+// generate the same sequence, the i-th 32 bytes are:
+//
+//	keccak256(keccak256(label, randomness), uint64(i) in big endian)
+//
+// It's not safe for concurrent use.
+type Source struct {
+	seed    common.Hash
+	counter uint64
+	buf     []byte
+}
+
+// NewSource creates a Source instance, 'label' separates sources for
+// different purposes from the same randomness.
+func NewSource(randomness []byte, label string) *Source {
+	return &Source{
+		seed: crypto.Keccak256Hash([]byte(label), randomness),
+	}
+}
+
+func (s *Source) fill() {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, s.counter)
+	s.counter++
+	block := crypto.Keccak256Hash(s.seed[:], counter)
+	s.buf = append(s.buf, block[:]...)
+}
+
+// Read implements io.Reader interface, it always fills p and returns no error.
+func (s *Source) Read(p []byte) (int, error) {
+	for len(s.buf) < len(p) {
+		s.fill()
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// Uint64 returns a random uint64.
+func (s *Source) Uint64() uint64 {
+	b := make([]byte, 8)
+	s.Read(b)
+	return binary.BigEndian.Uint64(b)
+}
+
+// Uniform returns a uniform random number in [0, n), it panics if n == 0.
+func (s *Source) Uniform(n uint64) uint64 {
+	if n == 0 {
+		panic("invalid argument to Uniform")
+	}
+	// Reject values beyond the largest multiple of n to avoid modulo bias.
+	limit := math.MaxUint64 - math.MaxUint64%n
+	for {
+		if v := s.Uint64(); v < limit {
+			return v % n
+		}
+	}
+}
+
+// Shuffle shuffles n elements with Fisher-Yates algorithm, swap swaps
+// elements with indexes i and j.
+func (s *Source) Shuffle(n int, swap func(i, j int)) {
+	if n < 0 {
+		panic("invalid argument to Shuffle")
+	}
+	for i := n - 1; i > 0; i-- {
+		swap(i, int(s.Uniform(uint64(i+1))))
+	}
+}
+
+// Perm returns a random permutation of [0, n).
+func (s *Source) Perm(n int) []int {
+	m := make([]int, n)
+	for i := range m {
+		m[i] = i
+	}
+	s.Shuffle(n, func(i, j int) { m[i], m[j] = m[j], m[i] })
+	return m
+}
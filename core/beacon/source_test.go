@@ -0,0 +1,110 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package beacon
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+)
+
+type SourceTestSuite struct {
+	suite.Suite
+}
+
+func (s *SourceTestSuite) TestDeterministic() {
+	randomness := common.NewRandomHash().Bytes()
+	s1 := NewSource(randomness, "test")
+	s2 := NewSource(randomness, "test")
+	for i := 0; i < 100; i++ {
+		s.Equal(s1.Uint64(), s2.Uint64())
+	}
+	// Reading in different sizes should get the same stream.
+	s1 = NewSource(randomness, "test")
+	s2 = NewSource(randomness, "test")
+	b1 := make([]byte, 100)
+	s1.Read(b1)
+	b2 := make([]byte, 100)
+	s2.Read(b2[:7])
+	s2.Read(b2[7:40])
+	s2.Read(b2[40:])
+	s.Equal(b1, b2)
+	// Different labels should get different streams.
+	s1 = NewSource(randomness, "test")
+	s2 = NewSource(randomness, "test2")
+	s.NotEqual(s1.Uint64(), s2.Uint64())
+	// Different randomness should get different streams.
+	s1 = NewSource(randomness, "test")
+	s2 = NewSource(common.NewRandomHash().Bytes(), "test")
+	s.NotEqual(s1.Uint64(), s2.Uint64())
+}
+
+func (s *SourceTestSuite) TestUniform() {
+	var (
+		src    = NewSource(common.NewRandomHash().Bytes(), "test")
+		n      = uint64(10)
+		rounds = 10000
+		counts = make([]int, n)
+	)
+	s.Panics(func() { src.Uniform(0) })
+	s.Equal(uint64(0), src.Uniform(1))
+	for i := 0; i < rounds; i++ {
+		v := src.Uniform(n)
+		s.Require().True(v < n)
+		counts[v]++
+	}
+	// Each value is expected to appear 1000 times.
+	for _, c := range counts {
+		s.True(c > 800 && c < 1200, "count: %d", c)
+	}
+}
+
+func (s *SourceTestSuite) TestShuffle() {
+	src := NewSource(common.NewRandomHash().Bytes(), "test")
+	perm := src.Perm(100)
+	s.Len(perm, 100)
+	sorted := append([]int{}, perm...)
+	sort.Ints(sorted)
+	for i, v := range sorted {
+		s.Equal(i, v)
+	}
+	s.False(sort.IntsAreSorted(perm))
+	s.Empty(src.Perm(0))
+	s.Panics(func() { src.Shuffle(-1, func(i, j int) {}) })
+	// The position of the first element should be uniform.
+	counts := make([]int, 4)
+	for i := 0; i < 4000; i++ {
+		xs := []int{0, 1, 2, 3}
+		src.Shuffle(len(xs), func(i, j int) { xs[i], xs[j] = xs[j], xs[i] })
+		for idx, x := range xs {
+			if x == 0 {
+				counts[idx]++
+			}
+		}
+	}
+	for _, c := range counts {
+		s.True(c > 800 && c < 1200, "count: %d", c)
+	}
+}
+
+func TestSource(t *testing.T) {
+	suite.Run(t, new(SourceTestSuite))
+}
@@ -144,15 +144,20 @@ type blockChain struct {
 	configs             []blockChainConfig
 	pendingBlocks       pendingBlockRecords
 	confirmedBlocks     types.BlocksByPosition
+	confirmedTime       map[uint64]time.Time
 	dMoment             time.Time
+	params              types.Params
+	metrics             Metrics
+	events              *eventBus
+	sigCache            *signatureCache
 
 	// Do not access this variable besides processAgreementResult.
 	lastPosition types.Position
 }
 
-func newBlockChain(nID types.NodeID, dMoment time.Time, initBlock *types.Block,
-	app Application, vGetter tsigVerifierGetter, signer *utils.Signer,
-	logger common.Logger) *blockChain {
+func newBlockChain(nID types.NodeID, dMoment time.Time, params types.Params,
+	initBlock *types.Block, app Application, vGetter tsigVerifierGetter,
+	signer *utils.Signer, logger common.Logger) *blockChain {
 	return &blockChain{
 		ID:            nID,
 		lastConfirmed: initBlock,
@@ -162,8 +167,12 @@ func newBlockChain(nID types.NodeID, dMoment time.Time, initBlock *types.Block,
 		app:           app,
 		logger:        logger,
 		dMoment:       dMoment,
+		params:        params,
+		metrics:       NopMetrics{},
+		events:        newEventBus(),
 		pendingRandomnesses: make(
 			map[types.Position][]byte),
+		confirmedTime: make(map[uint64]time.Time),
 	}
 }
 
@@ -229,12 +238,18 @@ func (bc *blockChain) extractBlocks() (ret []*types.Block) {
 	defer bc.lock.Unlock()
 	for len(bc.confirmedBlocks) > 0 {
 		c := bc.confirmedBlocks[0]
-		if c.Position.Round >= DKGDelayRound &&
+		if c.Position.Round >= bc.params.DKGDelayRound &&
 			len(c.Randomness) == 0 &&
 			!bc.setRandomnessFromPending(c) {
 			break
 		}
 		c, bc.confirmedBlocks = bc.confirmedBlocks[0], bc.confirmedBlocks[1:]
+		if t, exist := bc.confirmedTime[c.Position.Height]; exist {
+			if c.Position.Round >= bc.params.DKGDelayRound {
+				bc.metrics.ObserveRandomnessLatency(c.Position, time.Since(t))
+			}
+			delete(bc.confirmedTime, c.Position.Height)
+		}
 		ret = append(ret, c)
 		bc.lastDelivered = c
 	}
@@ -280,7 +295,7 @@ func (bc *blockChain) sanityCheck(b *types.Block) error {
 		tipConfig.minBlockInterval)) {
 		return ErrInvalidTimestamp
 	}
-	if err := utils.VerifyBlockSignature(b); err != nil {
+	if err := bc.sigCache.verifyBlockSignature(b); err != nil {
 		return err
 	}
 	return nil
@@ -323,7 +338,7 @@ func (bc *blockChain) addEmptyBlock(position types.Position) (
 // addBlock should be called when the block is confirmed by BA, we won't perform
 // sanity check against this block, it's ok to add block with skipping height.
 func (bc *blockChain) addBlock(b *types.Block) error {
-	if b.Position.Round >= DKGDelayRound &&
+	if b.Position.Round >= bc.params.DKGDelayRound &&
 		len(b.Randomness) == 0 &&
 		!bc.setRandomnessFromPending(b) {
 		return ErrMissingRandomness
@@ -399,7 +414,7 @@ func (bc *blockChain) pendingBlocksWithoutRandomness() []*types.Block {
 	defer bc.lock.RUnlock()
 	blocks := make([]*types.Block, 0)
 	for _, b := range bc.confirmedBlocks {
-		if b.Position.Round < DKGDelayRound ||
+		if b.Position.Round < bc.params.DKGDelayRound ||
 			len(b.Randomness) > 0 ||
 			bc.setRandomnessFromPending(b) {
 			continue
@@ -407,7 +422,7 @@ func (bc *blockChain) pendingBlocksWithoutRandomness() []*types.Block {
 		blocks = append(blocks, b)
 	}
 	for _, r := range bc.pendingBlocks {
-		if r.position.Round < DKGDelayRound {
+		if r.position.Round < bc.params.DKGDelayRound {
 			continue
 		}
 		if r.block != nil &&
@@ -504,8 +519,11 @@ func (bc *blockChain) purgeConfig() {
 
 func (bc *blockChain) verifyRandomness(
 	blockHash common.Hash, round uint64, randomness []byte) (bool, error) {
-	if round < DKGDelayRound {
-		return bytes.Compare(randomness, NoRand) == 0, nil
+	if round < bc.params.DKGDelayRound {
+		return bytes.Compare(randomness, bc.params.NoRand) == 0, nil
+	}
+	if bc.sigCache.isRandomnessVerified(blockHash, randomness) {
+		return true, nil
 	}
 	v, ok, err := bc.vGetter.UpdateAndGet(round)
 	if err != nil {
@@ -514,9 +532,13 @@ func (bc *blockChain) verifyRandomness(
 	if !ok {
 		return false, ErrTSigNotReady
 	}
-	return v.VerifySignature(blockHash, crypto.Signature{
+	if !v.VerifySignature(blockHash, crypto.Signature{
 		Type:      "bls",
-		Signature: randomness}), nil
+		Signature: randomness}) {
+		return false, nil
+	}
+	bc.sigCache.addRandomness(blockHash, randomness)
+	return true, nil
 }
 
 func (bc *blockChain) prepareBlock(position types.Position,
@@ -629,8 +651,12 @@ func (bc *blockChain) confirmBlock(b *types.Block) {
 	}
 	bc.logger.Debug("Calling Application.BlockConfirmed", "block", b)
 	bc.app.BlockConfirmed(*b)
+	if bc.events.interested(EventBlockConfirmed) {
+		bc.events.publish(&BlockConfirmedEvent{Block: b.Clone()})
+	}
 	bc.lastConfirmed = b
 	bc.confirmedBlocks = append(bc.confirmedBlocks, b)
+	bc.confirmedTime[b.Position.Height] = time.Now()
 	bc.purgeConfig()
 }
 
@@ -644,7 +670,7 @@ func (bc *blockChain) setRandomnessFromPending(b *types.Block) bool {
 }
 
 func (bc *blockChain) processAgreementResult(result *types.AgreementResult) error {
-	if result.Position.Round < DKGDelayRound {
+	if result.Position.Round < bc.params.DKGDelayRound {
 		return nil
 	}
 	if !result.Position.Newer(bc.lastPosition) {
@@ -669,7 +695,7 @@ func (bc *blockChain) processAgreementResult(result *types.AgreementResult) erro
 }
 
 func (bc *blockChain) addBlockRandomness(pos types.Position, rand []byte) {
-	if pos.Round < DKGDelayRound {
+	if pos.Round < bc.params.DKGDelayRound {
 		return
 	}
 	bc.lock.Lock()
@@ -150,8 +150,9 @@ func (s *BlockChainTestSuite) newBlockChain(initB *types.Block,
 	if initB != nil {
 		initHeight = initB.Position.Height
 	}
-	bc = newBlockChain(s.nID, s.dMoment, initB, test.NewApp(0, nil, nil),
-		&testTSigVerifierGetter{}, s.signer, &common.NullLogger{})
+	bc = newBlockChain(s.nID, s.dMoment, types.DefaultParams(), initB,
+		test.NewApp(0, nil, nil), &testTSigVerifierGetter{}, s.signer,
+		&common.NullLogger{})
 	// Provide the genesis round event.
 	s.Require().NoError(bc.notifyRoundEvents([]utils.RoundEventParam{
 		utils.RoundEventParam{
@@ -86,6 +86,8 @@ type configurationChain struct {
 	dkgCtx       context.Context
 	dkgCtxCancel context.CancelFunc
 	dkgRunning   bool
+	metrics      Metrics
+	remoteSigner DKGSigner
 }
 
 func newConfigurationChain(
@@ -108,6 +110,7 @@ func newConfigurationChain(
 		cache:       cache,
 		db:          dbInst,
 		pendingPsig: make(map[common.Hash][]*typesDKG.PartialSignature),
+		metrics:     NopMetrics{},
 	}
 	configurationChain.initDKGPhasesFunc()
 	return configurationChain
@@ -396,9 +399,20 @@ func (cc *configurationChain) runDKGPhaseNine(round uint64, reset uint64) error
 	if err != nil {
 		return err
 	}
-	// Save private shares to DB.
-	if err =
+	if cc.remoteSigner != nil {
+		// Hand over private shares instead of keeping them.
+		if err = cc.remoteSigner.PutDKGPrivateKey(
+			round, reset, *signer.privateKey); err != nil {
+			return err
+		}
+		signer = &dkgShareSecret{
+			remote: cc.remoteSigner,
+			round:  round,
+			reset:  reset,
+		}
+	} else if err =
 		cc.db.PutDKGPrivateKey(round, reset, *signer.privateKey); err != nil {
+		// Save private shares to DB.
 		return err
 	}
 	cc.dkg.proposeSuccess()
@@ -499,7 +513,9 @@ func (cc *configurationChain) runDKG(
 				default:
 				}
 
-				err := cc.dkgRunPhases[cc.dkg.step](round, reset)
+				step, start := cc.dkg.step, time.Now()
+				err := cc.dkgRunPhases[step](round, reset)
+				cc.metrics.ObserveDKGPhase(round, reset, step, time.Since(start))
 				if err == nil || err == ErrSkipButNoError {
 					err = nil
 					cc.dkg.step++
@@ -535,6 +551,22 @@ func (cc *configurationChain) runDKG(
 	return dkgError
 }
 
+// dkgStatus returns the status of the registered DKG protocol, the returned
+// DKGStatus would be nil if no DKG protocol is registered.
+func (cc *configurationChain) dkgStatus() *DKGStatus {
+	cc.dkgLock.RLock()
+	defer cc.dkgLock.RUnlock()
+	if cc.dkg == nil {
+		return nil
+	}
+	return &DKGStatus{
+		Round:   cc.dkg.round,
+		Reset:   cc.dkg.reset,
+		Step:    cc.dkg.step,
+		Running: cc.dkgRunning,
+	}
+}
+
 func (cc *configurationChain) isDKGFinal(round uint64) bool {
 	if !cc.gov.IsDKGFinal(round) {
 		return false
@@ -616,7 +648,17 @@ func (cc *configurationChain) recoverDKGInfo(
 			cc.npks[round] = npks
 		}()
 	}
-	if !signerExists && !ignoreSigner {
+	if !signerExists && !ignoreSigner && cc.remoteSigner != nil {
+		// Private shares are kept by remote signer.
+		reset := cc.gov.DKGResetCount(round)
+		cc.dkgResult.Lock()
+		defer cc.dkgResult.Unlock()
+		cc.dkgSigner[round] = &dkgShareSecret{
+			remote: cc.remoteSigner,
+			round:  round,
+			reset:  reset,
+		}
+	} else if !signerExists && !ignoreSigner {
 		reset := cc.gov.DKGResetCount(round)
 		// Check if we have private shares in DB.
 		prvKey, err := cc.db.GetDKGPrivateKey(round, reset)
@@ -665,11 +707,15 @@ func (cc *configurationChain) preparePartialSignature(
 	if signer == nil {
 		return nil, ErrDKGNotReady
 	}
+	psig, err := signer.sign(hash)
+	if err != nil {
+		return nil, err
+	}
 	return &typesDKG.PartialSignature{
 		ProposerID:       cc.ID,
 		Round:            round,
 		Hash:             hash,
-		PartialSignature: signer.sign(hash),
+		PartialSignature: psig,
 	}, nil
 }
 
@@ -45,6 +45,38 @@ type ConfigurationChainTestSuite struct {
 	dkgIDs  map[types.NodeID]dkg.ID
 	signers map[types.NodeID]*utils.Signer
 	pubKeys []crypto.PublicKey
+
+	// newRemoteSigner creates the DKGSigner of each node in runDKG if set.
+	newRemoteSigner func() DKGSigner
+}
+
+// memDKGSigner is a DKGSigner keeping DKG private shares in memory.
+type memDKGSigner struct {
+	lock    sync.Mutex
+	prvKeys map[[2]uint64]dkg.PrivateKey
+}
+
+func newMemDKGSigner() DKGSigner {
+	return &memDKGSigner{prvKeys: make(map[[2]uint64]dkg.PrivateKey)}
+}
+
+func (m *memDKGSigner) PutDKGPrivateKey(
+	round, reset uint64, prvKey dkg.PrivateKey) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.prvKeys[[2]uint64{round, reset}] = prvKey
+	return nil
+}
+
+func (m *memDKGSigner) SignDKG(
+	round, reset uint64, hash common.Hash) (crypto.Signature, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	prvKey, exist := m.prvKeys[[2]uint64{round, reset}]
+	if !exist {
+		return crypto.Signature{}, db.ErrDKGPrivateKeyDoesNotExist
+	}
+	return prvKey.Sign(hash)
 }
 
 type testCCGlobalReceiver struct {
@@ -252,7 +284,7 @@ func (s *ConfigurationChainTestSuite) runDKG(
 
 	for _, nID := range s.nIDs {
 		evts[nID] = newTestEvent()
-		gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+		gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 			s.pubKeys, 100*time.Millisecond, &common.NullLogger{}, true,
 		), ConfigRoundShift)
 		s.Require().NoError(err)
@@ -262,6 +294,9 @@ func (s *ConfigurationChainTestSuite) runDKG(
 		cfgChains[nID] = newConfigurationChain(nID,
 			newTestCCReceiver(nID, recv), gov, cache, dbInst,
 			&common.NullLogger{})
+		if s.newRemoteSigner != nil {
+			cfgChains[nID].remoteSigner = s.newRemoteSigner()
+		}
 		recv.nodes[nID] = cfgChains[nID]
 		recv.govs[nID] = gov
 	}
@@ -383,7 +418,7 @@ func (s *ConfigurationChainTestSuite) TestDKGMasterPublicKeyDelayAdd() {
 	delayNode := s.nIDs[0]
 
 	for _, nID := range s.nIDs {
-		state := test.NewState(DKGDelayRound,
+		state := test.NewState(types.DefaultParams(),
 			s.pubKeys, 100*time.Millisecond, &common.NullLogger{}, true)
 		gov, err := test.NewGovernance(state, ConfigRoundShift)
 		s.Require().NoError(err)
@@ -455,7 +490,7 @@ func (s *ConfigurationChainTestSuite) TestDKGComplaintDelayAdd() {
 	recv := newTestCCGlobalReceiver(s)
 	recvs := make(map[types.NodeID]*testCCReceiver)
 	for _, nID := range s.nIDs {
-		state := test.NewState(DKGDelayRound,
+		state := test.NewState(types.DefaultParams(),
 			s.pubKeys, 100*time.Millisecond, &common.NullLogger{}, true)
 		gov, err := test.NewGovernance(state, ConfigRoundShift)
 		s.Require().NoError(err)
@@ -661,6 +696,42 @@ func (s *ConfigurationChainTestSuite) TestDKGSignerRecoverFromDB() {
 	}
 }
 
+func (s *ConfigurationChainTestSuite) TestDKGRemoteSigner() {
+	k := 2
+	n := 7
+	round := DKGDelayRound
+	reset := uint64(0)
+	s.newRemoteSigner = newMemDKGSigner
+	defer func() { s.newRemoteSigner = nil }()
+	cfgChains := s.runDKG(k, n, round, reset)
+	hash := crypto.Keccak256Hash([]byte("Hash1"))
+	for nID, cc := range cfgChains {
+		// Private shares are handed over to remote signer.
+		s.Require().Nil(cc.dkgSigner[round].privateKey)
+		_, err := cc.db.GetDKGPrivateKey(round, reset)
+		s.Require().Equal(db.ErrDKGPrivateKeyDoesNotExist, err)
+		psig1, err := cc.preparePartialSignature(round, hash)
+		s.Require().NoError(err)
+		s.Require().True(cc.npks[round].PublicKeys[nID].VerifySignature(
+			hash, crypto.Signature(psig1.PartialSignature)))
+		// A cloned configurationChain should sign via remote signer, too.
+		clonedCC := newConfigurationChain(
+			cc.ID, cc.recv, cc.gov, cc.cache, cc.db, cc.logger,
+		)
+		clonedCC.remoteSigner = cc.remoteSigner
+		psig2, err := clonedCC.preparePartialSignature(round, hash)
+		s.Require().NoError(err)
+		s.Require().Equal(psig1.PartialSignature, psig2.PartialSignature)
+		// Signing fails without the share.
+		clonedCC = newConfigurationChain(
+			cc.ID, cc.recv, cc.gov, cc.cache, cc.db, cc.logger,
+		)
+		clonedCC.remoteSigner = newMemDKGSigner()
+		_, err = clonedCC.preparePartialSignature(round, hash)
+		s.Require().Equal(db.ErrDKGPrivateKeyDoesNotExist, err)
+	}
+}
+
 func (s *ConfigurationChainTestSuite) TestDKGPhasesSnapShot() {
 	k := 2
 	n := 7
@@ -680,7 +751,7 @@ func (s *ConfigurationChainTestSuite) TestDKGAbort() {
 	round := DKGDelayRound
 	reset := uint64(0)
 	s.setupNodes(n)
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		s.pubKeys, 100*time.Millisecond, &common.NullLogger{}, true,
 	), ConfigRoundShift)
 	s.Require().NoError(err)
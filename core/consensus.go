@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"runtime"
 	"sync"
 	"time"
 
@@ -55,6 +56,10 @@ var (
 		"randomness of block is incorrect")
 	ErrCannotVerifyBlockRandomness = fmt.Errorf(
 		"cannot verify block randomness")
+	ErrIncorrectCompactionChainTip = fmt.Errorf(
+		"compaction chain tip in db is incorrect")
+	ErrAppQueueFull = fmt.Errorf(
+		"application queue is full")
 )
 
 type selfAgreementResult types.AgreementResult
@@ -91,7 +96,8 @@ func (recv *consensusBAReceiver) emptyBlockHash(pos types.Position) (
 
 func (recv *consensusBAReceiver) VerifyPartialSignature(vote *types.Vote) (
 	bool, bool) {
-	if vote.Position.Round >= DKGDelayRound && vote.BlockHash != types.SkipBlockHash {
+	if vote.Position.Round >= recv.consensus.params.DKGDelayRound &&
+		vote.BlockHash != types.SkipBlockHash {
 		if vote.Type == types.VoteCom || vote.Type == types.VoteFastCom {
 			if recv.npks == nil {
 				recv.consensus.logger.Debug(
@@ -139,19 +145,25 @@ func (recv *consensusBAReceiver) ProposeVote(vote *types.Vote) {
 	if recv.psigSigner != nil &&
 		vote.BlockHash != types.SkipBlockHash {
 		if vote.Type == types.VoteCom || vote.Type == types.VoteFastCom {
+			hash := vote.BlockHash
 			if vote.BlockHash == types.NullBlockHash {
-				hash, err := recv.emptyBlockHash(vote.Position)
-				if err != nil {
+				var err error
+				if hash, err = recv.emptyBlockHash(vote.Position); err != nil {
 					recv.consensus.logger.Error(
 						"Failed to propose vote for empty block",
 						"position", vote.Position,
 						"error", err)
 					return
 				}
-				vote.PartialSignature = recv.psigSigner.sign(hash)
-			} else {
-				vote.PartialSignature = recv.psigSigner.sign(vote.BlockHash)
 			}
+			psig, err := recv.psigSigner.sign(hash)
+			if err != nil {
+				recv.consensus.logger.Error("Failed to sign partial signature",
+					"position", vote.Position,
+					"error", err)
+				return
+			}
+			vote.PartialSignature = psig
 		}
 	}
 	if err := recv.agreementModule.prepareVote(vote); err != nil {
@@ -165,6 +177,7 @@ func (recv *consensusBAReceiver) ProposeVote(vote *types.Vote) {
 				"vote", vote)
 			return
 		}
+		recv.consensus.baMgr.voteCache.add(vote)
 		recv.consensus.logger.Debug("Calling Network.BroadcastVote",
 			"vote", vote)
 		recv.consensus.network.BroadcastVote(vote)
@@ -257,6 +270,9 @@ func (recv *consensusBAReceiver) ConfirmBlock(
 		}
 	}
 
+	if len(votes) > 0 {
+		recv.reportConfirmMetrics(aID, votes)
+	}
 	if len(votes) == 0 && len(block.Randomness) == 0 {
 		recv.consensus.logger.Error("No votes to recover randomness",
 			"block", block)
@@ -268,7 +284,7 @@ func (recv *consensusBAReceiver) ConfirmBlock(
 			if vote.BlockHash != hash {
 				continue
 			}
-			if block.Position.Round >= DKGDelayRound {
+			if block.Position.Round >= recv.consensus.params.DKGDelayRound {
 				ID, exist := recv.npks.IDMap[vote.ProposerID]
 				if !exist {
 					continue
@@ -279,7 +295,7 @@ func (recv *consensusBAReceiver) ConfirmBlock(
 				voteList = append(voteList, *vote)
 			}
 		}
-		if block.Position.Round >= DKGDelayRound {
+		if block.Position.Round >= recv.consensus.params.DKGDelayRound {
 			rand, err := cryptoDKG.RecoverSignature(psigs, IDs)
 			if err != nil {
 				recv.consensus.logger.Warn("Unable to recover randomness",
@@ -289,7 +305,7 @@ func (recv *consensusBAReceiver) ConfirmBlock(
 				block.Randomness = rand.Signature[:]
 			}
 		} else {
-			block.Randomness = NoRand
+			block.Randomness = recv.consensus.params.NoRand
 		}
 
 		if recv.isNotary {
@@ -311,7 +327,7 @@ func (recv *consensusBAReceiver) ConfirmBlock(
 				recv.consensus.bcModule.addBlockRandomness(
 					block.Position, block.Randomness)
 			}
-			if block.Position.Round >= DKGDelayRound {
+			if block.Position.Round >= recv.consensus.params.DKGDelayRound {
 				recv.consensus.logger.Debug(
 					"Broadcast finalized block",
 					"block", block)
@@ -384,6 +400,21 @@ CleanChannelLoop:
 	recv.restartNotary <- block.Position
 }
 
+// reportConfirmMetrics should be called with agreementModule.lock held.
+func (recv *consensusBAReceiver) reportConfirmMetrics(
+	pos types.Position, votes map[types.NodeID]*types.Vote) {
+	metrics := recv.consensus.metrics
+	// All votes to confirm a block are in the same period and type.
+	for _, vote := range votes {
+		metrics.ObserveBAPeriod(pos, vote.Period)
+		metrics.ReportBAConfirmPath(pos, vote.Type == types.VoteFastCom)
+		break
+	}
+	if start := recv.agreementModule.startTime; !start.IsZero() {
+		metrics.ObserveBAConfirmLatency(pos, time.Since(start))
+	}
+}
+
 func (recv *consensusBAReceiver) PullBlocks(hashes common.Hashes) {
 	if !recv.isNotary {
 		return
@@ -394,6 +425,10 @@ func (recv *consensusBAReceiver) PullBlocks(hashes common.Hashes) {
 
 func (recv *consensusBAReceiver) ReportForkVote(v1, v2 *types.Vote) {
 	recv.consensus.gov.ReportForkVote(v1, v2)
+	recv.consensus.events.publish(&ForkVoteEvent{
+		Vote1: v1.Clone(),
+		Vote2: v2.Clone(),
+	})
 }
 
 func (recv *consensusBAReceiver) ReportForkBlock(b1, b2 *types.Block) {
@@ -532,6 +567,7 @@ type Consensus struct {
 	// Misc.
 	bcModule                 *blockChain
 	dMoment                  time.Time
+	params                   types.Params
 	nodeSetCache             *utils.NodeSetCache
 	tsigVerifierCache        *TSigVerifierCache
 	lock                     sync.RWMutex
@@ -540,11 +576,17 @@ type Consensus struct {
 	event                    *common.Event
 	roundEvent               *utils.RoundEvent
 	logger                   common.Logger
+	metrics                  *metricsProxy
+	events                   *eventBus
+	sigCache                 *signatureCache
+	verifier                 *verifierPool
 	resetDeliveryGuardTicker chan struct{}
 	msgChan                  chan types.Msg
 	priorityMsgChan          chan interface{}
 	waitGroup                sync.WaitGroup
 	processBlockChan         chan *types.Block
+	errLock                  sync.Mutex
+	err                      error
 
 	// Context of Dummy receiver during switching from syncer.
 	dummyCancel    context.CancelFunc
@@ -555,6 +597,7 @@ type Consensus struct {
 // NewConsensus construct an Consensus instance.
 func NewConsensus(
 	dMoment time.Time,
+	params types.Params,
 	app Application,
 	gov Governance,
 	db db.Database,
@@ -562,13 +605,14 @@ func NewConsensus(
 	prv crypto.PrivateKey,
 	logger common.Logger) *Consensus {
 	return newConsensusForRound(
-		nil, dMoment, app, gov, db, network, prv, logger, true)
+		nil, dMoment, params, app, gov, db, network, prv, logger, true)
 }
 
 // NewConsensusForSimulation creates an instance of Consensus for simulation,
 // the only difference with NewConsensus is nonblocking of app.
 func NewConsensusForSimulation(
 	dMoment time.Time,
+	params types.Params,
 	app Application,
 	gov Governance,
 	db db.Database,
@@ -576,7 +620,7 @@ func NewConsensusForSimulation(
 	prv crypto.PrivateKey,
 	logger common.Logger) *Consensus {
 	return newConsensusForRound(
-		nil, dMoment, app, gov, db, network, prv, logger, false)
+		nil, dMoment, params, app, gov, db, network, prv, logger, false)
 }
 
 // NewConsensusFromSyncer constructs an Consensus instance from information
@@ -592,6 +636,7 @@ func NewConsensusFromSyncer(
 	initBlock *types.Block,
 	startWithEmpty bool,
 	dMoment time.Time,
+	params types.Params,
 	app Application,
 	gov Governance,
 	db db.Database,
@@ -600,8 +645,11 @@ func NewConsensusFromSyncer(
 	confirmedBlocks []*types.Block,
 	cachedMessages []types.Msg,
 	logger common.Logger) (*Consensus, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
 	// Setup Consensus instance.
-	con := newConsensusForRound(initBlock, dMoment, app, gov, db,
+	con := newConsensusForRound(initBlock, dMoment, params, app, gov, db,
 		networkModule, prv, logger, true)
 	// Launch a dummy receiver before we start receiving from network module.
 	con.dummyMsgBuffer = cachedMessages
@@ -637,10 +685,100 @@ func NewConsensusFromSyncer(
 	return con, nil
 }
 
+// NewConsensusFromDB constructs a Consensus instance from the compaction chain
+// tip stored in database.
+//
+// It's designed for restarting a node whose database is still intact. All
+// blocks up to that tip should already be delivered to the application, the
+// newly created instance would rejoin BA from the next height.
+func NewConsensusFromDB(
+	dMoment time.Time,
+	params types.Params,
+	app Application,
+	gov Governance,
+	dbInst db.Database,
+	networkModule Network,
+	prv crypto.PrivateKey,
+	logger common.Logger) (*Consensus, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	tipHash, tipHeight := dbInst.GetCompactionChainTipInfo()
+	if tipHeight == 0 {
+		// Nothing is delivered yet, bootstrap from genesis.
+		return newConsensusForRound(nil, dMoment, params, app, gov, dbInst,
+			networkModule, prv, logger, true), nil
+	}
+	initBlock, err := dbInst.GetBlock(tipHash)
+	if err != nil {
+		return nil, err
+	}
+	if initBlock.Position.Height != tipHeight {
+		logger.Error("Mismatched compaction chain tip",
+			"block", &initBlock,
+			"height", tipHeight)
+		return nil, ErrIncorrectCompactionChainTip
+	}
+	con := newConsensusForRound(&initBlock, dMoment, params, app, gov,
+		dbInst, networkModule, prv, logger, true)
+	if initBlock.Position.Round >= con.params.DKGDelayRound {
+		// Make sure the group public key of the tip round could be restored
+		// from governance, and the tip block in DB is finalized by it.
+		ok, err := con.bcModule.verifyRandomness(
+			initBlock.Hash, initBlock.Position.Round, initBlock.Randomness)
+		if err == nil && !ok {
+			err = ErrIncorrectBlockRandomness
+		}
+		if err != nil {
+			con.Stop()
+			return nil, err
+		}
+	}
+	return con, nil
+}
+
+// NewConsensusWithGovernanceV2 constructs a Consensus instance like
+// NewConsensusFromDB, with governance accessed via GovernanceV2 interface.
+//
+// The state required to bootstrap from the compaction chain tip is fetched
+// before constructing, an error is returned if it's not available after
+// retrying according to 'config'. Failed requests of a running instance are
+// retried until 'ctx' is done.
+func NewConsensusWithGovernanceV2(
+	ctx context.Context,
+	dMoment time.Time,
+	params types.Params,
+	app Application,
+	gov GovernanceV2,
+	config RetryConfig,
+	dbInst db.Database,
+	networkModule Network,
+	prv crypto.PrivateKey,
+	logger common.Logger) (*Consensus, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	round := uint64(0)
+	if tipHash, tipHeight := dbInst.GetCompactionChainTipInfo(); tipHeight > 0 {
+		tip, err := dbInst.GetBlock(tipHash)
+		if err != nil {
+			return nil, err
+		}
+		round = tip.Position.Round
+	}
+	adapter, err := PrepareGovernanceV2(ctx, gov, round, config, logger)
+	if err != nil {
+		return nil, err
+	}
+	return NewConsensusFromDB(dMoment, params, app, adapter, dbInst,
+		networkModule, prv, logger)
+}
+
 // newConsensusForRound creates a Consensus instance.
 func newConsensusForRound(
 	initBlock *types.Block,
 	dMoment time.Time,
+	params types.Params,
 	app Application,
 	gov Governance,
 	db db.Database,
@@ -648,7 +786,10 @@ func newConsensusForRound(
 	prv crypto.PrivateKey,
 	logger common.Logger,
 	usingNonBlocking bool) *Consensus {
-	// TODO(w): load latest blockHeight from DB, and use config at that height.
+	// Constructors not returning errors would panic on invalid parameters.
+	if err := params.Validate(); err != nil {
+		panic(err)
+	}
 	nodeSetCache := utils.NewNodeSetCache(gov)
 	// Setup signer module.
 	signer := utils.NewSigner(prv)
@@ -683,15 +824,26 @@ func newConsensusForRound(
 			if err != nil {
 				return crypto.Signature{}, err
 			}
-			return crypto.Signature(signer.sign(hash)), nil
+			psig, err := signer.sign(hash)
+			return crypto.Signature(psig), err
 		})
 	appModule := app
 	if usingNonBlocking {
 		appModule = newNonBlocking(app, debugApp)
 	}
 	tsigVerifierCache := NewTSigVerifierCache(gov, 7)
-	bcModule := newBlockChain(ID, dMoment, initBlock, appModule,
+	bcModule := newBlockChain(ID, dMoment, params, initBlock, appModule,
 		tsigVerifierCache, signer, logger)
+	// All modules report into the same proxy, which is safe to be replaced by
+	// SetMetrics while they are running.
+	metrics := newMetricsProxy()
+	bcModule.metrics = metrics
+	cfgModule.metrics = metrics
+	if nb, ok := appModule.(*nonBlocking); ok {
+		nb.setMetrics(metrics)
+	}
+	sigCache := newSignatureCache(signatureCacheSize)
+	bcModule.sigCache = sigCache
 	// Construct Consensus instance.
 	con := &Consensus{
 		ID:                       ID,
@@ -705,20 +857,30 @@ func newConsensusForRound(
 		cfgModule:                cfgModule,
 		bcModule:                 bcModule,
 		dMoment:                  dMoment,
+		params:                   params,
 		nodeSetCache:             nodeSetCache,
 		tsigVerifierCache:        tsigVerifierCache,
 		signer:                   signer,
 		event:                    common.NewEvent(),
 		logger:                   logger,
+		metrics:                  metrics,
+		events:                   newEventBus(),
+		sigCache:                 sigCache,
 		resetDeliveryGuardTicker: make(chan struct{}),
 		msgChan:                  make(chan types.Msg, 1024),
 		priorityMsgChan:          make(chan interface{}, 1024),
 		processBlockChan:         make(chan *types.Block, 1024),
 	}
+	bcModule.events = con.events
+	if nb, ok := appModule.(*nonBlocking); ok {
+		nb.setFatalHandler(con.fail)
+	}
+	con.verifier = newVerifierPool(runtime.NumCPU(), cap(con.msgChan),
+		sigCache, bcModule.verifyRandomness)
 	con.ctx, con.ctxCancel = context.WithCancel(context.Background())
 	var err error
 	con.roundEvent, err = utils.NewRoundEvent(con.ctx, gov, logger, initPos,
-		ConfigRoundShift)
+		params)
 	if err != nil {
 		panic(err)
 	}
@@ -745,7 +907,7 @@ func (con *Consensus) prepare(initBlock *types.Block) (err error) {
 		initRound = initBlock.Position.Round
 	}
 	if initRound == 0 {
-		if DKGDelayRound == 0 {
+		if con.params.DKGDelayRound == 0 {
 			panic("not implemented yet")
 		}
 	}
@@ -771,6 +933,8 @@ func (con *Consensus) prepare(initBlock *types.Block) (err error) {
 			if e.Reset == 0 {
 				continue
 			}
+			con.metrics.ReportDKGReset(e.Round+1, e.Reset)
+			con.events.publish(&DKGResetEvent{Round: e.Round + 1, Reset: e.Reset})
 			con.nodeSetCache.Purge(e.Round + 1)
 			con.tsigVerifierCache.Purge(e.Round + 1)
 		}
@@ -807,7 +971,7 @@ func (con *Consensus) prepare(initBlock *types.Block) (err error) {
 		e := evts[len(evts)-1]
 		defer elapse("reset-DKG", e)()
 		nextRound := e.Round + 1
-		if nextRound < DKGDelayRound {
+		if nextRound < con.params.DKGDelayRound {
 			return
 		}
 		curNotarySet, err := con.nodeSetCache.GetNotarySet(e.Round)
@@ -835,7 +999,7 @@ func (con *Consensus) prepare(initBlock *types.Block) (err error) {
 		// would be done by the notary set in previous round.
 		e := evts[len(evts)-1]
 		defer elapse("propose-CRS", e)()
-		if e.Reset != 0 || e.Round < DKGDelayRound {
+		if e.Reset != 0 || e.Round < con.params.DKGDelayRound {
 			return
 		}
 		if curNotarySet, err := con.nodeSetCache.GetNotarySet(e.Round); err != nil {
@@ -945,7 +1109,7 @@ func (con *Consensus) prepare(initBlock *types.Block) (err error) {
 		// Register a routine to register next DKG.
 		con.event.RegisterHeight(e.NextDKGRegisterHeight(), func(uint64) {
 			nextRound := e.Round + 1
-			if nextRound < DKGDelayRound {
+			if nextRound < con.params.DKGDelayRound {
 				con.logger.Info("Skip runDKG for round",
 					"round", nextRound,
 					"reset", e.Reset)
@@ -1006,6 +1170,55 @@ func (con *Consensus) prepare(initBlock *types.Block) (err error) {
 	return
 }
 
+// SetMetrics sets the Metrics instance for modules to report statistics into.
+// It's safe to be called at any time, statistics reported before that are
+// dropped.
+func (con *Consensus) SetMetrics(metrics Metrics) {
+	con.metrics.set(metrics)
+}
+
+// SetAppQueuePolicy sets the capacity of the queue of BlockConfirmed and
+// BlockDelivered events to the application, and the policy to apply when it's
+// full. It should be called before Run and takes no effect for instances
+// created by NewConsensusForSimulation.
+func (con *Consensus) SetAppQueuePolicy(capacity int, policy AppQueuePolicy) {
+	if nb, ok := con.app.(*nonBlocking); ok {
+		nb.setQueuePolicy(capacity, policy)
+	}
+}
+
+// SetVerifierWorkers sets the count of workers verifying signatures of
+// incoming messages in parallel, the default is the count of CPUs. It should be
+// called before Run.
+func (con *Consensus) SetVerifierWorkers(workers int) {
+	con.verifier = newVerifierPool(workers, cap(con.msgChan), con.sigCache,
+		con.bcModule.verifyRandomness)
+}
+
+// SetDKGSigner sets the DKGSigner keeping DKG private shares of this node.
+// Shares recovered by DKG protocol are handed over to it instead of being
+// saved in the database, and signed with via it. It should be called before
+// Run.
+func (con *Consensus) SetDKGSigner(signer DKGSigner) {
+	con.cfgModule.remoteSigner = signer
+}
+
+// SetSlashingProtection sets the slashing protection consulted before signing
+// votes and blocks, it should belong to the same node and be called before Run.
+func (con *Consensus) SetSlashingProtection(
+	sp *utils.SlashingProtection) error {
+	return con.signer.SetSlashingProtection(sp)
+}
+
+// Subscribe registers a subscription for events of given types, all types of
+// events would be received when no type is given. Events are dropped when the
+// buffer of size 'bufferSize' is full, DefaultSubscriptionBufferSize would be
+// used when 'bufferSize' is not positive.
+func (con *Consensus) Subscribe(
+	bufferSize int, eventTypes ...EventType) *Subscription {
+	return con.events.subscribe(bufferSize, eventTypes)
+}
+
 // Run starts running DEXON Consensus.
 func (con *Consensus) Run() {
 	// There may have emptys block in blockchain added by force sync.
@@ -1015,6 +1228,11 @@ func (con *Consensus) Run() {
 	// Launch network handler.
 	con.logger.Debug("Calling Network.ReceiveChan")
 	con.waitGroup.Add(1)
+	go func() {
+		defer con.waitGroup.Done()
+		con.verifier.run(con.ctx, con.msgChan)
+	}()
+	con.waitGroup.Add(1)
 	go con.deliverNetworkMsg()
 	con.waitGroup.Add(1)
 	go con.processMsg()
@@ -1058,7 +1276,7 @@ func (con *Consensus) generateBlockRandomness(blocks []*types.Block) {
 	con.logger.Debug("Start generating block randomness", "blocks", blocks)
 	isNotarySet := make(map[uint64]bool)
 	for _, block := range blocks {
-		if block.Position.Round < DKGDelayRound {
+		if block.Position.Round < con.params.DKGDelayRound {
 			continue
 		}
 		doRun, exist := isNotarySet[block.Position.Round]
@@ -1143,11 +1361,14 @@ func (con *Consensus) runDKG(
 			con.dkgReady.Broadcast()
 			con.dkgRunning = 2
 		}()
+		con.events.publish(&DKGStartedEvent{Round: round, Reset: reset})
 		if err :=
 			con.cfgModule.runDKG(
 				round, reset,
 				con.event, dkgBeginHeight, dkgHeight); err != nil {
 			con.logger.Error("Failed to runDKG", "error", err)
+		} else {
+			con.events.publish(&DKGSucceededEvent{Round: round, Reset: reset})
 		}
 	}()
 }
@@ -1183,10 +1404,34 @@ func (con *Consensus) runCRS(round uint64, hash common.Hash, reset bool) {
 					"crs", hex.EncodeToString(crs))
 				con.gov.ProposeCRS(round+1, crs)
 			}
+			con.events.publish(&CRSProposedEvent{
+				Round:     round + 1,
+				SignedCRS: crs,
+			})
 		}
 	}
 }
 
+// Err returns the fatal error which stopped this instance, ex.
+// ErrAppQueueFull. It's nil if the instance is running or stopped by Stop.
+// Stop should still be called to release resources after a fatal error.
+func (con *Consensus) Err() error {
+	con.errLock.Lock()
+	defer con.errLock.Unlock()
+	return con.err
+}
+
+// fail stops all routines of this instance with a fatal error.
+func (con *Consensus) fail(err error) {
+	con.errLock.Lock()
+	if con.err == nil {
+		con.err = err
+	}
+	con.errLock.Unlock()
+	con.logger.Error("Consensus stopped by fatal error", "error", err)
+	con.ctxCancel()
+}
+
 // Stop the Consensus core.
 func (con *Consensus) Stop() {
 	con.ctxCancel()
@@ -1196,6 +1441,7 @@ func (con *Consensus) Stop() {
 	if nbApp, ok := con.app.(*nonBlocking); ok {
 		nbApp.wait()
 	}
+	con.events.close()
 }
 
 func (con *Consensus) deliverNetworkMsg() {
@@ -1209,15 +1455,10 @@ func (con *Consensus) deliverNetworkMsg() {
 		}
 		select {
 		case msg := <-recv:
-		innerLoop:
-			for {
-				select {
-				case con.msgChan <- msg:
-					break innerLoop
-				case <-time.After(500 * time.Millisecond):
-					con.logger.Debug("internal message channel is full",
-						"pending", msg)
-				}
+			// Signatures are verified in parallel, the verified messages
+			// would be forwarded to con.msgChan in order.
+			if !con.verifier.submit(con.ctx, msg) {
+				return
 			}
 		case <-con.ctx.Done():
 			return
@@ -1248,6 +1489,8 @@ MessageLoop:
 				return
 			}
 		}
+		con.metrics.ReportQueueDepth(QueueMsg, len(con.msgChan))
+		con.metrics.ReportQueueDepth(QueuePriorityMsg, len(con.priorityMsgChan))
 		switch val := msg.(type) {
 		case *selfAgreementResult:
 			con.baMgr.touchAgreementResult((*types.AgreementResult)(val))
@@ -1303,7 +1546,8 @@ MessageLoop:
 						con.network.ReportBadPeerChan() <- peer
 						continue MessageLoop
 					}
-					if err := utils.VerifyBlockSignature(val); err != nil {
+					if err := con.sigCache.verifyBlockSignature(
+						val); err != nil {
 						con.logger.Error("VerifyBlockSignature failed",
 							"block", val,
 							"error", err)
@@ -1367,6 +1611,13 @@ MessageLoop:
 	}
 }
 
+// GetVotes returns votes of a position received or proposed recently by
+// agreement manager, it could be used to answer vote pulling requests from
+// peers.
+func (con *Consensus) GetVotes(pos types.Position) []*types.Vote {
+	return con.baMgr.votes(pos)
+}
+
 // ProcessVote is the entry point to submit ont vote to a Consensus instance.
 func (con *Consensus) ProcessVote(vote *types.Vote) (err error) {
 	err = con.baMgr.processVote(vote)
@@ -1384,7 +1635,8 @@ func (con *Consensus) ProcessAgreementResult(
 	if err != nil {
 		return err
 	}
-	if err := VerifyAgreementResult(rand, notarySet); err != nil {
+	if err := VerifyAgreementResult(
+		rand, notarySet, con.params.DKGDelayRound); err != nil {
 		con.baMgr.untouchAgreementResult(rand)
 		return err
 	}
@@ -1418,26 +1670,28 @@ func (con *Consensus) preProcessBlock(b *types.Block) (err error) {
 }
 
 func (con *Consensus) processFinalizedBlock(b *types.Block) (err error) {
-	if b.Position.Round < DKGDelayRound {
-		return
-	}
-	if err = utils.VerifyBlockSignature(b); err != nil {
+	if b.Position.Round < con.params.DKGDelayRound {
 		return
 	}
-	verifier, ok, err := con.tsigVerifierCache.UpdateAndGet(b.Position.Round)
-	if err != nil {
-		return
-	}
-	if !ok {
-		err = ErrCannotVerifyBlockRandomness
+	if err = con.sigCache.verifyBlockSignature(b); err != nil {
 		return
 	}
-	if !verifier.VerifySignature(b.Hash, crypto.Signature{
-		Type:      "bls",
-		Signature: b.Randomness,
-	}) {
-		err = ErrIncorrectBlockRandomness
-		return
+	if !con.sigCache.isRandomnessVerified(b.Hash, b.Randomness) {
+		verifier, ok, err := con.tsigVerifierCache.UpdateAndGet(
+			b.Position.Round)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrCannotVerifyBlockRandomness
+		}
+		if !verifier.VerifySignature(b.Hash, crypto.Signature{
+			Type:      "bls",
+			Signature: b.Randomness,
+		}) {
+			return ErrIncorrectBlockRandomness
+		}
+		con.sigCache.addRandomness(b.Hash, b.Randomness)
 	}
 	err = con.baMgr.processFinalizedBlock(b)
 	if err == nil && con.debugApp != nil {
@@ -1480,15 +1734,26 @@ func (con *Consensus) deliverBlock(b *types.Block) {
 	case con.resetDeliveryGuardTicker <- struct{}{}:
 	default:
 	}
-	if err := con.db.PutBlock(*b); err != nil {
+	// Write the block and the tip of compaction chain together, or they
+	// might mismatch after a crash.
+	batch := con.db.NewBatch()
+	if err := batch.PutBlock(*b); err != nil {
 		panic(err)
 	}
-	if err := con.db.PutCompactionChainTipInfo(b.Hash,
+	if err := batch.PutCompactionChainTipInfo(b.Hash,
 		b.Position.Height); err != nil {
 		panic(err)
 	}
+	if err := batch.Commit(); err != nil {
+		panic(err)
+	}
 	con.logger.Debug("Calling Application.BlockDelivered", "block", b)
 	con.app.BlockDelivered(b.Hash, b.Position, common.CopyBytes(b.Randomness))
+	con.events.publish(&BlockDeliveredEvent{
+		Hash:       b.Hash,
+		Position:   b.Position,
+		Randomness: common.CopyBytes(b.Randomness),
+	})
 	if con.debugApp != nil {
 		con.debugApp.BlockReady(b.Hash)
 	}
@@ -1503,17 +1768,64 @@ func (con *Consensus) deliverFinalizedBlocks() error {
 }
 
 func (con *Consensus) deliverFinalizedBlocksWithoutLock() (err error) {
+	prevBlock := con.bcModule.lastDeliveredBlock()
 	deliveredBlocks := con.bcModule.extractBlocks()
 	con.logger.Debug("Last blocks in compaction chain",
 		"delivered", con.bcModule.lastDeliveredBlock(),
 		"pending", con.bcModule.lastPendingBlock())
 	for _, b := range deliveredBlocks {
 		con.deliverBlock(b)
+		if prevBlock != nil && prevBlock.Position.Round != b.Position.Round {
+			con.publishRoundSwitched(prevBlock.Position.Round, b)
+		}
+		prevBlock = b
 		con.event.NotifyHeight(b.Position.Height)
 	}
 	return
 }
 
+// publishRoundSwitched publishes events when 'b' is the first block of a round.
+func (con *Consensus) publishRoundSwitched(prevRound uint64, b *types.Block) {
+	round := b.Position.Round
+	con.events.publish(&RoundSwitchedEvent{
+		Round:       round,
+		BeginHeight: b.Position.Height,
+	})
+	if !con.events.interested(EventNotarySetChanged) {
+		return
+	}
+	prevSet, err := con.nodeSetCache.GetNotarySet(prevRound)
+	if err != nil {
+		con.logger.Error("Failed to get notary set",
+			"round", prevRound,
+			"error", err)
+		return
+	}
+	notarySet, err := con.nodeSetCache.GetNotarySet(round)
+	if err != nil {
+		con.logger.Error("Failed to get notary set",
+			"round", round,
+			"error", err)
+		return
+	}
+	changed := len(prevSet) != len(notarySet)
+	for nID := range notarySet {
+		if _, exist := prevSet[nID]; !exist {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+	_, isNotary := notarySet[con.ID]
+	con.events.publish(&NotarySetChangedEvent{
+		Round:     round,
+		NotarySet: notarySet,
+		IsNotary:  isNotary,
+	})
+}
+
 func (con *Consensus) processBlockLoop() {
 	for {
 		select {
@@ -1564,7 +1876,7 @@ func (con *Consensus) proposeBlock(position types.Position) (
 			"position", &b.Position)
 		return nil, ErrCRSNotReady
 	}
-	if err = con.signer.SignCRS(b, crs); err != nil {
+	if err = con.signer.SignCRS(b, crs, con.params.DKGDelayRound); err != nil {
 		return nil, err
 	}
 	return b, nil
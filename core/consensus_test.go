@@ -228,8 +228,8 @@ func (s *ConsensusTestSuite) prepareConsensus(
 	s.Require().NoError(err)
 	nID := types.NewNodeID(prvKey.PublicKey())
 	network := conn.newNetwork(nID)
-	con := NewConsensus(
-		dMoment, app, gov, dbInst, network, prvKey, &common.NullLogger{})
+	con := NewConsensus(dMoment, types.DefaultParams(), app, gov, dbInst,
+		network, prvKey, &common.NullLogger{})
 	conn.setCon(nID, con)
 	return app, con
 }
@@ -245,8 +245,8 @@ func (s *ConsensusTestSuite) prepareConsensusWithDB(
 	app := test.NewApp(0, nil, nil)
 	nID := types.NewNodeID(prvKey.PublicKey())
 	network := conn.newNetwork(nID)
-	con := NewConsensus(
-		dMoment, app, gov, dbInst, network, prvKey, &common.NullLogger{})
+	con := NewConsensus(dMoment, types.DefaultParams(), app, gov, dbInst,
+		network, prvKey, &common.NullLogger{})
 	conn.setCon(nID, con)
 	return app, con
 }
@@ -255,7 +255,7 @@ func (s *ConsensusTestSuite) TestRegisteredDKGRecover() {
 	conn := s.newNetworkConnection()
 	prvKeys, pubKeys, err := test.NewKeys(1)
 	s.Require().NoError(err)
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		pubKeys, time.Second, &common.NullLogger{}, true), ConfigRoundShift)
 	s.Require().NoError(err)
 	gov.State().RequestChange(test.StateChangeRoundLength, uint64(200))
@@ -293,7 +293,7 @@ func (s *ConsensusTestSuite) TestDKGCRS() {
 	conn := s.newNetworkConnection()
 	prvKeys, pubKeys, err := test.NewKeys(n)
 	s.Require().NoError(err)
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		pubKeys, lambda, &common.NullLogger{}, true), ConfigRoundShift)
 	s.Require().NoError(err)
 	gov.State().RequestChange(test.StateChangeRoundLength, uint64(200))
@@ -347,7 +347,7 @@ func (s *ConsensusTestSuite) TestSyncBA() {
 	conn := s.newNetworkConnection()
 	prvKeys, pubKeys, err := test.NewKeys(4)
 	s.Require().NoError(err)
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		pubKeys, lambdaBA, &common.NullLogger{}, true), ConfigRoundShift)
 	s.Require().NoError(err)
 	prvKey := prvKeys[0]
@@ -392,7 +392,7 @@ func (s *ConsensusTestSuite) TestInitialHeightEventTriggered() {
 	prvKeys, pubKeys, err := test.NewKeys(4)
 	s.Require().NoError(err)
 	// Prepare a governance instance, whose DKG-reset-count for round 2 is 1.
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		pubKeys, time.Second, &common.NullLogger{}, true), ConfigRoundShift)
 	gov.State().RequestChange(test.StateChangeRoundLength, uint64(100))
 	s.Require().NoError(err)
@@ -417,6 +417,7 @@ func (s *ConsensusTestSuite) TestInitialHeightEventTriggered() {
 		initBlock,
 		false,
 		time.Now().UTC(),
+		types.DefaultParams(),
 		test.NewApp(0, nil, nil),
 		gov,
 		dbInst,
@@ -433,6 +434,255 @@ func (s *ConsensusTestSuite) TestInitialHeightEventTriggered() {
 	s.Require().Equal(con.bcModule.configs[0].RoundEndHeight(), uint64(301))
 }
 
+func (s *ConsensusTestSuite) TestNewConsensusFromDB() {
+	prvKeys, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
+		pubKeys, time.Second, &common.NullLogger{}, true), ConfigRoundShift)
+	s.Require().NoError(err)
+	gov.State().RequestChange(test.StateChangeRoundLength, uint64(100))
+	prvKey := prvKeys[0]
+	nID := types.NewNodeID(prvKey.PublicKey())
+	conn := s.newNetworkConnection()
+	newCon := func(dbInst db.Database) (*Consensus, error) {
+		return NewConsensusFromDB(
+			time.Now().UTC(),
+			types.DefaultParams(),
+			test.NewApp(0, nil, nil),
+			gov,
+			dbInst,
+			conn.newNetwork(nID),
+			prvKey,
+			&common.NullLogger{},
+		)
+	}
+	// An empty database should bootstrap from genesis.
+	dbInst, err := db.NewMemBackedDB()
+	s.Require().NoError(err)
+	con, err := newCon(dbInst)
+	s.Require().NoError(err)
+	s.Require().Nil(con.bcModule.lastDeliveredBlock())
+	con.Stop()
+	// Prepare a compaction chain in round 0.
+	var blocks []*types.Block
+	for h := types.GenesisHeight; h <= 10; h++ {
+		b := &types.Block{
+			Hash:       common.NewRandomHash(),
+			Position:   types.Position{Round: 0, Height: h},
+			Timestamp:  time.Now().UTC(),
+			Randomness: NoRand,
+		}
+		if len(blocks) > 0 {
+			b.ParentHash = blocks[len(blocks)-1].Hash
+		}
+		s.Require().NoError(dbInst.PutBlock(*b))
+		s.Require().NoError(dbInst.PutCompactionChainTipInfo(
+			b.Hash, b.Position.Height))
+		blocks = append(blocks, b)
+	}
+	tip := blocks[len(blocks)-1]
+	con, err = newCon(dbInst)
+	s.Require().NoError(err)
+	defer con.Stop()
+	s.Require().Equal(con.bcModule.lastDeliveredBlock().Hash, tip.Hash)
+	s.Require().Equal(con.bcModule.tipRound(), uint64(0))
+	height, _ := con.bcModule.nextBlock()
+	s.Require().Equal(height, tip.Position.Height+1)
+	// Tip info pointing to a block in different height should be rejected.
+	dbInst, err = db.NewMemBackedDB()
+	s.Require().NoError(err)
+	for _, b := range blocks[:3] {
+		s.Require().NoError(dbInst.PutBlock(*b))
+	}
+	s.Require().NoError(dbInst.PutCompactionChainTipInfo(blocks[0].Hash, 1))
+	s.Require().NoError(dbInst.PutCompactionChainTipInfo(blocks[0].Hash, 2))
+	_, err = newCon(dbInst)
+	s.Require().Equal(err, ErrIncorrectCompactionChainTip)
+	// Randomness of the tip block should be verified when its round is not
+	// before DKGDelayRound.
+	gs, err := test.PrepareDKG(gov, DKGDelayRound, prvKeys)
+	s.Require().NoError(err)
+	for h := tip.Position.Height + 1; h <= 101; h++ {
+		b := &types.Block{
+			ParentHash: blocks[len(blocks)-1].Hash,
+			Hash:       common.NewRandomHash(),
+			Position:   types.Position{Round: 0, Height: h},
+			Timestamp:  time.Now().UTC(),
+			Randomness: NoRand,
+		}
+		blocks = append(blocks, b)
+	}
+	// Height 101 is the first block of round 1.
+	tip = blocks[len(blocks)-1]
+	tip.Position.Round = DKGDelayRound
+	sig, err := gs.Sign(tip.Hash)
+	s.Require().NoError(err)
+	tip.Randomness = sig.Signature
+	newDB := func(tip *types.Block) db.Database {
+		dbInst, err := db.NewMemBackedDB()
+		s.Require().NoError(err)
+		for _, b := range blocks[:len(blocks)-1] {
+			s.Require().NoError(dbInst.PutBlock(*b))
+			s.Require().NoError(dbInst.PutCompactionChainTipInfo(
+				b.Hash, b.Position.Height))
+		}
+		s.Require().NoError(dbInst.PutBlock(*tip))
+		s.Require().NoError(dbInst.PutCompactionChainTipInfo(
+			tip.Hash, tip.Position.Height))
+		return dbInst
+	}
+	con, err = newCon(newDB(tip))
+	s.Require().NoError(err)
+	defer con.Stop()
+	s.Require().Equal(con.bcModule.lastDeliveredBlock().Hash, tip.Hash)
+	s.Require().Equal(con.bcModule.tipRound(), DKGDelayRound)
+	// Tampered randomness should be rejected.
+	tampered := tip.Clone()
+	sig, err = gs.Sign(common.NewRandomHash())
+	s.Require().NoError(err)
+	tampered.Randomness = sig.Signature
+	_, err = newCon(newDB(tampered))
+	s.Require().Equal(ErrIncorrectBlockRandomness, err)
+}
+
+func (s *ConsensusTestSuite) TestParams() {
+	// Two instances with different parameters could co-exist in the same
+	// process.
+	var (
+		req      = s.Require()
+		hash     = common.NewRandomHash()
+		conn     = s.newNetworkConnection()
+		networks = []types.Params{
+			types.DefaultParams(),
+			types.Params{
+				ConfigRoundShift: 3,
+				DKGDelayRound:    2,
+				NoRand:           []byte("devnet-norand"),
+			},
+		}
+		cons = make([]*Consensus, 0, len(networks))
+	)
+	for _, params := range networks {
+		prvKeys, pubKeys, err := test.NewKeys(4)
+		req.NoError(err)
+		gov, err := test.NewGovernance(test.NewState(params,
+			pubKeys, time.Second, &common.NullLogger{}, true),
+			params.ConfigRoundShift)
+		req.NoError(err)
+		dbInst, err := db.NewMemBackedDB()
+		req.NoError(err)
+		nID := types.NewNodeID(prvKeys[0].PublicKey())
+		con := NewConsensus(time.Now().UTC(), params,
+			test.NewApp(0, nil, nil), gov, dbInst, conn.newNetwork(nID),
+			prvKeys[0], &common.NullLogger{})
+		defer con.Stop()
+		cons = append(cons, con)
+	}
+	for i, con := range cons {
+		req.Equal(networks[i], con.baMgr.params)
+		// Round 0 is before DKGDelayRound for both instances.
+		ok, err := con.bcModule.verifyRandomness(hash, 0, networks[i].NoRand)
+		req.NoError(err)
+		req.True(ok)
+		ok, err = con.bcModule.verifyRandomness(
+			hash, 0, networks[1-i].NoRand)
+		req.NoError(err)
+		req.False(ok)
+	}
+	// Round 1 requires randomness only for the default one.
+	ok, err := cons[1].bcModule.verifyRandomness(hash, 1, networks[1].NoRand)
+	req.NoError(err)
+	req.True(ok)
+	_, err = cons[0].bcModule.verifyRandomness(hash, 1, networks[0].NoRand)
+	req.Error(err)
+}
+
+func (s *ConsensusTestSuite) TestStatus() {
+	prvKeys, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
+		pubKeys, time.Second, &common.NullLogger{}, true), ConfigRoundShift)
+	s.Require().NoError(err)
+	gov.State().RequestChange(test.StateChangeRoundLength, uint64(100))
+	conn := s.newNetworkConnection()
+	prvKey := prvKeys[0]
+	nID := types.NewNodeID(prvKey.PublicKey())
+	dbInst, err := db.NewMemBackedDB()
+	s.Require().NoError(err)
+	con := NewConsensus(time.Now().UTC(), types.DefaultParams(),
+		test.NewApp(0, nil, nil), gov, dbInst, conn.newNetwork(nID), prvKey,
+		&common.NullLogger{})
+	defer con.Stop()
+	status := con.Status()
+	s.Require().Equal(uint64(0), status.Round)
+	s.Require().Equal(types.GenesisHeight, status.Height)
+	s.Require().False(status.BA.Running)
+	s.Require().True(status.InNotarySet)
+	s.Require().True(status.InNextNotarySet)
+	s.Require().Equal(uint64(1), status.DKG.Round)
+	s.Require().Equal(uint64(0), status.DKG.Reset)
+	s.Require().False(status.DKG.Running)
+	s.Require().False(status.DKG.Final)
+	s.Require().Nil(status.LastDelivered)
+	s.Require().Nil(status.LastPending)
+	// Restart the agreement module manually.
+	notarySet, err := con.nodeSetCache.GetNotarySet(0)
+	s.Require().NoError(err)
+	pos := types.Position{Round: 0, Height: types.GenesisHeight}
+	con.baMgr.baModule.restart(notarySet, 3, pos, nID, common.Hash{})
+	status = con.Status()
+	s.Require().True(status.BA.Running)
+	s.Require().Equal(pos, status.BA.Position)
+	s.Require().Equal(uint64(2), status.BA.Period)
+	s.Require().Equal(stateFast.String(), status.BA.State)
+}
+
+func (s *ConsensusTestSuite) TestSubscribe() {
+	conn := s.newNetworkConnection()
+	prvKeys, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
+		pubKeys, time.Second, &common.NullLogger{}, true), ConfigRoundShift)
+	s.Require().NoError(err)
+	_, con := s.prepareConsensus(time.Now().UTC(), gov, prvKeys[0], conn)
+	sub := con.Subscribe(1, EventBlockConfirmed)
+	b, err := con.bcModule.addEmptyBlock(types.Position{
+		Height: types.GenesisHeight,
+	})
+	s.Require().NoError(err)
+	evt := (<-sub.Chan()).(*BlockConfirmedEvent)
+	s.Require().Equal(b.Hash, evt.Block.Hash)
+	s.Require().Equal(b.Position, evt.Block.Position)
+	// Subscriptions are closed when consensus is stopped.
+	con.Stop()
+	_, ok := <-sub.Chan()
+	s.Require().False(ok)
+}
+
+func (s *ConsensusTestSuite) TestFatalError() {
+	conn := s.newNetworkConnection()
+	prvKeys, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
+		pubKeys, time.Second, &common.NullLogger{}, true), ConfigRoundShift)
+	s.Require().NoError(err)
+	_, con := s.prepareConsensus(time.Now().UTC(), gov, prvKeys[0], conn)
+	defer con.Stop()
+	s.Require().NoError(con.Err())
+	// Errors reported by the application queue should stop the instance.
+	nb, ok := con.app.(*nonBlocking)
+	s.Require().True(ok)
+	nb.eventsChange.L.Lock()
+	nb.fail(ErrAppQueueFull)
+	nb.eventsChange.L.Unlock()
+	select {
+	case <-con.ctx.Done():
+	case <-time.After(5 * time.Second):
+		s.FailNow("consensus is not stopped")
+	}
+	s.Require().Equal(ErrAppQueueFull, con.Err())
+}
+
 func TestConsensus(t *testing.T) {
 	suite.Run(t, new(ConsensusTestSuite))
 }
@@ -17,25 +17,21 @@
 
 package core
 
-import "github.com/dexon-foundation/dexon-consensus/core/utils"
+import (
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
 
-// ConfigRoundShift refers to the difference between block's round and config
-// round derived from its state.
+// ConfigRoundShift is the default value of types.Params.ConfigRoundShift.
 //
 // For example, when round shift is 2, a block in round 0 should derive config
 // for round 2.
-const ConfigRoundShift uint64 = 2
+const ConfigRoundShift = types.DefaultConfigRoundShift
 
-// DKGDelayRound refers to the round that first DKG is run.
+// DKGDelayRound is the default value of types.Params.DKGDelayRound.
 //
 // For example, when delay round is 1, new DKG will run at round 1. Round 0 will
 // have neither DKG nor CRS.
-const DKGDelayRound uint64 = 1
+const DKGDelayRound = types.DefaultDKGDelayRound
 
-// NoRand is the magic placeholder for randomness field in blocks for blocks
-// proposed before DKGDelayRound.
-var NoRand = []byte("norand")
-
-func init() {
-	utils.SetDKGDelayRound(DKGDelayRound)
-}
+// NoRand is the default value of types.Params.NoRand.
+var NoRand = []byte(types.DefaultNoRand)
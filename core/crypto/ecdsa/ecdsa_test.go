@@ -81,6 +81,24 @@ func (s *ETHCryptoTestSuite) TestSigToPub() {
 	s.Equal(pubkey, prv.PublicKey())
 }
 
+func (s *ETHCryptoTestSuite) TestPrivateKeyBytes() {
+	prv, err := NewPrivateKey()
+	s.Require().NoError(err)
+	b := prv.Bytes()
+	s.Len(b, 32)
+	prv2, err := NewPrivateKeyFromBytes(b)
+	s.Require().NoError(err)
+	s.Equal(prv.PublicKey(), prv2.PublicKey())
+	hash := common.NewRandomHash()
+	sig, err := prv2.Sign(hash)
+	s.Require().NoError(err)
+	s.True(prv.PublicKey().VerifySignature(hash, sig))
+
+	// Invalid length should be rejected.
+	_, err = NewPrivateKeyFromBytes(b[1:])
+	s.Error(err)
+}
+
 func TestCrypto(t *testing.T) {
 	suite.Run(t, new(ETHCryptoTestSuite))
 }
@@ -0,0 +1,283 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+// Package keystore implements a password encrypted file format for ecdsa
+// node keys.
+//
+// The private key is encrypted by AES-256-GCM, and the key of AES is derived
+// from the password by scrypt. The node ID and public key are stored in plain
+// text, so a key file could be inspected without the password.
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// Errors for keystore.
+var (
+	ErrUnsupportedVersion = fmt.Errorf(
+		"unsupported keystore version")
+	ErrUnsupportedCipher = fmt.Errorf(
+		"unsupported keystore cipher")
+	ErrUnsupportedKDF = fmt.Errorf(
+		"unsupported keystore key derivation function")
+	ErrDecrypt = fmt.Errorf(
+		"could not decrypt key with given password")
+	ErrPublicKeyMismatch = fmt.Errorf(
+		"decrypted key does not match the public key in keystore")
+)
+
+// Parameters of scrypt.
+const (
+	// StandardScryptN is the N parameter of scrypt, which takes about 1
+	// second to derive a key on a modern processor.
+	StandardScryptN = 1 << 18
+	// StandardScryptP is the P parameter of scrypt.
+	StandardScryptP = 1
+	// LightScryptN is the N parameter of scrypt for testing, which is
+	// much faster but insecure.
+	LightScryptN = 1 << 12
+	// LightScryptP is the P parameter of scrypt for testing.
+	LightScryptP = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+)
+
+const (
+	version    = 1
+	cipherName = "aes-256-gcm"
+	kdfName    = "scrypt"
+)
+
+type kdfParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+type cryptoJSON struct {
+	Cipher     string    `json:"cipher"`
+	CipherText string    `json:"ciphertext"`
+	Nonce      string    `json:"nonce"`
+	KDF        string    `json:"kdf"`
+	KDFParams  kdfParams `json:"kdfparams"`
+}
+
+type keyJSON struct {
+	Version   int        `json:"version"`
+	NodeID    string     `json:"node_id"`
+	PublicKey string     `json:"public_key"`
+	Crypto    cryptoJSON `json:"crypto"`
+}
+
+// Info is the unencrypted information in a key file.
+type Info struct {
+	NodeID    types.NodeID
+	PublicKey []byte
+}
+
+func newAEAD(password string, params kdfParams) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := scrypt.Key(
+		[]byte(password), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptKey encrypts a private key with password, scryptN and scryptP are
+// the parameters of scrypt.
+func EncryptKey(
+	prvKey *ecdsa.PrivateKey, password string, scryptN, scryptP int) (
+	[]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params := kdfParams{
+		N:     scryptN,
+		R:     scryptR,
+		P:     scryptP,
+		DKLen: scryptDKLen,
+		Salt:  hex.EncodeToString(salt),
+	}
+	aead, err := newAEAD(password, params)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	pubKey := prvKey.PublicKey()
+	nID := types.NewNodeID(pubKey)
+	// The public key is authenticated along with the private key, any
+	// modification to it would fail the decryption.
+	cipherText := aead.Seal(nil, nonce, prvKey.Bytes(), pubKey.Bytes())
+	return json.MarshalIndent(&keyJSON{
+		Version:   version,
+		NodeID:    hex.EncodeToString(nID.Hash[:]),
+		PublicKey: hex.EncodeToString(pubKey.Bytes()),
+		Crypto: cryptoJSON{
+			Cipher:     cipherName,
+			CipherText: hex.EncodeToString(cipherText),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        kdfName,
+			KDFParams:  params,
+		},
+	}, "", "  ")
+}
+
+func unmarshal(data []byte) (*keyJSON, []byte, error) {
+	k := &keyJSON{}
+	if err := json.Unmarshal(data, k); err != nil {
+		return nil, nil, err
+	}
+	if k.Version != version {
+		return nil, nil, ErrUnsupportedVersion
+	}
+	pubKey, err := hex.DecodeString(k.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return k, pubKey, nil
+}
+
+// DecryptKey decrypts a private key from the content of a key file.
+func DecryptKey(data []byte, password string) (*ecdsa.PrivateKey, error) {
+	k, pubKey, err := unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if k.Crypto.Cipher != cipherName {
+		return nil, ErrUnsupportedCipher
+	}
+	if k.Crypto.KDF != kdfName {
+		return nil, ErrUnsupportedKDF
+	}
+	aead, err := newAEAD(password, k.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(k.Crypto.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	cipherText, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	plainText, err := aead.Open(nil, nonce, cipherText, pubKey)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	prvKey, err := ecdsa.NewPrivateKeyFromBytes(plainText)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(prvKey.PublicKey().Bytes(), pubKey) {
+		return nil, ErrPublicKeyMismatch
+	}
+	return prvKey, nil
+}
+
+// Inspect returns the unencrypted information from the content of a key
+// file, the password is not required.
+func Inspect(data []byte) (*Info, error) {
+	_, pubKey, err := unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ecdsa.NewPublicKeyFromByteSlice(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{
+		NodeID:    types.NewNodeID(key),
+		PublicKey: pubKey,
+	}, nil
+}
+
+// WriteKeyFile encrypts a private key and writes it to path. The file is
+// written to a temporary file and renamed, so an existing key file would
+// never be left half written.
+func WriteKeyFile(
+	path string, prvKey *ecdsa.PrivateKey, password string,
+	scryptN, scryptP int) error {
+	data, err := EncryptKey(prvKey, password, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err = f.Chmod(0600); err == nil {
+		if _, err = f.Write(data); err == nil {
+			err = f.Sync()
+		}
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// ReadKeyFile reads and decrypts a private key from path.
+func ReadKeyFile(path, password string) (*ecdsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(data, password)
+}
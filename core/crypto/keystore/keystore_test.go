@@ -0,0 +1,140 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+type KeystoreTestSuite struct {
+	suite.Suite
+}
+
+func (s *KeystoreTestSuite) TestEncryptDecrypt() {
+	prvKey, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	data, err := EncryptKey(prvKey, "password", LightScryptN, LightScryptP)
+	s.Require().NoError(err)
+	// Private key should not be stored in plain text.
+	s.NotContains(string(data), string(prvKey.Bytes()))
+
+	decrypted, err := DecryptKey(data, "password")
+	s.Require().NoError(err)
+	s.Equal(prvKey.Bytes(), decrypted.Bytes())
+	hash := common.NewRandomHash()
+	sig, err := decrypted.Sign(hash)
+	s.Require().NoError(err)
+	s.True(prvKey.PublicKey().VerifySignature(hash, sig))
+
+	// Wrong password.
+	_, err = DecryptKey(data, "wrong password")
+	s.Equal(ErrDecrypt, err)
+
+	// Info could be read without password.
+	info, err := Inspect(data)
+	s.Require().NoError(err)
+	s.Equal(types.NewNodeID(prvKey.PublicKey()), info.NodeID)
+	s.Equal(prvKey.PublicKey().Bytes(), info.PublicKey)
+}
+
+func (s *KeystoreTestSuite) TestTamper() {
+	prvKey, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	otherKey, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	data, err := EncryptKey(prvKey, "password", LightScryptN, LightScryptP)
+	s.Require().NoError(err)
+	k := keyJSON{}
+	s.Require().NoError(json.Unmarshal(data, &k))
+
+	// Replacing the public key should fail the decryption.
+	other, err := EncryptKey(otherKey, "password", LightScryptN, LightScryptP)
+	s.Require().NoError(err)
+	info, err := Inspect(other)
+	s.Require().NoError(err)
+	tampered := k
+	tampered.PublicKey = hex.EncodeToString(info.PublicKey)
+	b, err := json.Marshal(&tampered)
+	s.Require().NoError(err)
+	_, err = DecryptKey(b, "password")
+	s.Equal(ErrDecrypt, err)
+
+	// Unsupported version, cipher or KDF.
+	tampered = k
+	tampered.Version++
+	b, err = json.Marshal(&tampered)
+	s.Require().NoError(err)
+	_, err = DecryptKey(b, "password")
+	s.Equal(ErrUnsupportedVersion, err)
+	tampered = k
+	tampered.Crypto.Cipher = "aes-128-ctr"
+	b, err = json.Marshal(&tampered)
+	s.Require().NoError(err)
+	_, err = DecryptKey(b, "password")
+	s.Equal(ErrUnsupportedCipher, err)
+	tampered = k
+	tampered.Crypto.KDF = "pbkdf2"
+	b, err = json.Marshal(&tampered)
+	s.Require().NoError(err)
+	_, err = DecryptKey(b, "password")
+	s.Equal(ErrUnsupportedKDF, err)
+}
+
+func (s *KeystoreTestSuite) TestKeyFile() {
+	dir, err := ioutil.TempDir("", "dexcon-keystore")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "key")
+	prvKey, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	s.Require().NoError(
+		WriteKeyFile(path, prvKey, "password", LightScryptN, LightScryptP))
+	fi, err := os.Stat(path)
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(0600), fi.Mode().Perm())
+	loaded, err := ReadKeyFile(path, "password")
+	s.Require().NoError(err)
+	s.Equal(prvKey.Bytes(), loaded.Bytes())
+
+	// Overwrite the key file, no temporary file should be left.
+	prvKey2, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	s.Require().NoError(
+		WriteKeyFile(path, prvKey2, "password2", LightScryptN, LightScryptP))
+	loaded, err = ReadKeyFile(path, "password2")
+	s.Require().NoError(err)
+	s.Equal(prvKey2.Bytes(), loaded.Bytes())
+	files, err := ioutil.ReadDir(dir)
+	s.Require().NoError(err)
+	s.Len(files, 1)
+}
+
+func TestKeystore(t *testing.T) {
+	suite.Run(t, new(KeystoreTestSuite))
+}
@@ -0,0 +1,184 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+)
+
+// Client sends signing requests to a Server, requests are sent one by one
+// through a single connection, which would be re-established after failures.
+type Client struct {
+	network string
+	address string
+	timeout time.Duration
+
+	lock sync.Mutex
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// Dial connects to a Server, 'timeout' limits each request including the
+// time to connect.
+func Dial(network, address string, timeout time.Duration) (*Client, error) {
+	c := &Client{
+		network: network,
+		address: address,
+		timeout: timeout,
+	}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) connect() error {
+	conn, err := net.DialTimeout(c.network, c.address, c.timeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.enc = json.NewEncoder(conn)
+	c.dec = json.NewDecoder(conn)
+	return nil
+}
+
+func (c *Client) disconnect() {
+	if c.conn == nil {
+		return
+	}
+	c.conn.Close()
+	c.conn, c.enc, c.dec = nil, nil, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.disconnect()
+	return nil
+}
+
+func (c *Client) call(req *request) (*response, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+	res := &response{}
+	err := func() error {
+		if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return err
+		}
+		if err := c.enc.Encode(req); err != nil {
+			return err
+		}
+		return c.dec.Decode(res)
+	}()
+	if err != nil {
+		// The connection is no longer in sync after failures.
+		c.disconnect()
+		return nil, err
+	}
+	if res.Error != "" {
+		return nil, errors.New(res.Error)
+	}
+	return res, nil
+}
+
+// PrivateKey returns a crypto.PrivateKey which signs via the server.
+func (c *Client) PrivateKey() (*PrivateKey, error) {
+	res, err := c.call(&request{Method: methodPublicKey})
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := ecdsa.NewPublicKeyFromByteSlice(res.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{client: c, pubKey: pubKey}, nil
+}
+
+// PutDKGPrivateKey implements core.DKGSigner interface by handing over the DKG
+// private share to the server.
+func (c *Client) PutDKGPrivateKey(
+	round, reset uint64, prvKey dkg.PrivateKey) error {
+	_, err := c.call(&request{
+		Method:     methodPutDKG,
+		Round:      round,
+		Reset:      reset,
+		PrivateKey: prvKey.Bytes(),
+	})
+	return err
+}
+
+// SignDKG implements core.DKGSigner interface by signing with the DKG private
+// share held by the server.
+func (c *Client) SignDKG(
+	round, reset uint64, hash common.Hash) (crypto.Signature, error) {
+	res, err := c.call(&request{
+		Method: methodSignDKG,
+		Round:  round,
+		Reset:  reset,
+		Hash:   hash,
+	})
+	if err != nil {
+		return crypto.Signature{}, err
+	}
+	return res.Signature, nil
+}
+
+// PrivateKey implements crypto.PrivateKey interface by signing via a remote
+// signer.
+type PrivateKey struct {
+	client *Client
+	pubKey crypto.PublicKey
+}
+
+// PublicKey implements crypto.PrivateKey interface.
+func (prv *PrivateKey) PublicKey() crypto.PublicKey {
+	return prv.pubKey
+}
+
+// Sign implements crypto.PrivateKey interface, signatures from the server are
+// verified before returned.
+func (prv *PrivateKey) Sign(hash common.Hash) (crypto.Signature, error) {
+	res, err := prv.client.call(&request{
+		Method: methodSign,
+		Hash:   hash,
+	})
+	if err != nil {
+		return crypto.Signature{}, err
+	}
+	if !prv.pubKey.VerifySignature(hash, res.Signature) {
+		return crypto.Signature{}, ErrIncorrectSignature
+	}
+	return res.Signature, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+// Package remote implements crypto.PrivateKey backed by an external signing
+// process, so the node key never lives in the memory of consensus process.
+//
+// The process could also keep DKG private shares, Client implements
+// core.DKGSigner to receive shares recovered by DKG protocol and sign with
+// them. Shares received from other nodes during DKG protocol are still held by
+// consensus process until the protocol finishes.
+//
+// Client and server exchange JSON encoded requests and responses over a
+// stream connection, ex. a Unix socket or a TCP connection to localhost.
+// There is no authentication in this protocol, the access to the socket
+// should be restricted by the operating system.
+package remote
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+)
+
+// Errors for remote signer.
+var (
+	ErrUnknownMethod = fmt.Errorf(
+		"unknown method")
+	ErrDKGKeyNotFound = fmt.Errorf(
+		"DKG private key not found")
+	ErrNoDKGKeyStore = fmt.Errorf(
+		"DKG key store is not set")
+	ErrIncorrectSignature = fmt.Errorf(
+		"incorrect signature from remote signer")
+)
+
+// Methods supported by the remote signer.
+const (
+	methodPublicKey = "public_key"
+	methodSign      = "sign"
+	methodPutDKG    = "put_dkg"
+	methodSignDKG   = "sign_dkg"
+)
+
+type request struct {
+	Method     string      `json:"method"`
+	Round      uint64      `json:"round"`
+	Reset      uint64      `json:"reset"`
+	Hash       common.Hash `json:"hash"`
+	PrivateKey []byte      `json:"private_key,omitempty"`
+}
+
+type response struct {
+	PublicKey []byte           `json:"public_key,omitempty"`
+	Signature crypto.Signature `json:"signature"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// DKGKeyStore keeps DKG private shares for a Server.
+type DKGKeyStore interface {
+	// GetDKGPrivateKey returns the DKG private share of a round, or
+	// ErrDKGKeyNotFound.
+	GetDKGPrivateKey(round, reset uint64) (*dkg.PrivateKey, error)
+
+	// PutDKGPrivateKey saves the DKG private share of a round.
+	PutDKGPrivateKey(round, reset uint64, prvKey *dkg.PrivateKey) error
+}
+
+// DKGKeyDir is a DKGKeyStore saving DKG private shares in a directory, the
+// share of each round is saved in hex in a file named by the round and reset.
+type DKGKeyDir struct {
+	path string
+
+	lock    sync.RWMutex
+	prvKeys map[[2]uint64]*dkg.PrivateKey
+}
+
+// NewDKGKeyDir creates a DKGKeyDir instance, the directory is created if it
+// doesn't exist.
+func NewDKGKeyDir(path string) (*DKGKeyDir, error) {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	return &DKGKeyDir{
+		path:    path,
+		prvKeys: make(map[[2]uint64]*dkg.PrivateKey),
+	}, nil
+}
+
+func (dir *DKGKeyDir) fileName(round, reset uint64) string {
+	return filepath.Join(dir.path, fmt.Sprintf("%d-%d", round, reset))
+}
+
+// GetDKGPrivateKey implements DKGKeyStore interface.
+func (dir *DKGKeyDir) GetDKGPrivateKey(
+	round, reset uint64) (*dkg.PrivateKey, error) {
+	dir.lock.RLock()
+	prvKey, exist := dir.prvKeys[[2]uint64{round, reset}]
+	dir.lock.RUnlock()
+	if exist {
+		return prvKey, nil
+	}
+	buf, err := ioutil.ReadFile(dir.fileName(round, reset))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrDKGKeyNotFound
+		}
+		return nil, err
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(string(buf)))
+	if err != nil {
+		return nil, err
+	}
+	prvKey = &dkg.PrivateKey{}
+	if err = prvKey.SetBytes(b); err != nil {
+		return nil, err
+	}
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	dir.prvKeys[[2]uint64{round, reset}] = prvKey
+	return prvKey, nil
+}
+
+// PutDKGPrivateKey implements DKGKeyStore interface.
+func (dir *DKGKeyDir) PutDKGPrivateKey(
+	round, reset uint64, prvKey *dkg.PrivateKey) error {
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	// Write to a temporary file and rename it, so a partially written share
+	// is never read.
+	f, err := ioutil.TempFile(dir.path, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString(hex.EncodeToString(prvKey.Bytes())); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(f.Name(), dir.fileName(round, reset)); err != nil {
+		return err
+	}
+	d, err := os.Open(dir.path)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err = d.Sync(); err != nil {
+		return err
+	}
+	dir.prvKeys[[2]uint64{round, reset}] = prvKey
+	return nil
+}
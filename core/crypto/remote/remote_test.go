@@ -0,0 +1,192 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package remote
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+type RemoteTestSuite struct {
+	suite.Suite
+
+	dir     string
+	prvKey  crypto.PrivateKey
+	dkgKeys *DKGKeyDir
+	address string
+	server  *Server
+}
+
+func (s *RemoteTestSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "dexon-remote-signer")
+	s.Require().NoError(err)
+	s.prvKey, err = ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	s.dkgKeys, err = NewDKGKeyDir(filepath.Join(s.dir, "dkg"))
+	s.Require().NoError(err)
+	s.address = filepath.Join(s.dir, "signer.sock")
+	s.server = s.startServer(s.dkgKeys)
+}
+
+func (s *RemoteTestSuite) TearDownTest() {
+	s.server.Close()
+	os.RemoveAll(s.dir)
+}
+
+func (s *RemoteTestSuite) startServer(dkgKeys DKGKeyStore) *Server {
+	l, err := net.Listen("unix", s.address)
+	s.Require().NoError(err)
+	server := NewServer(s.prvKey, dkgKeys, &common.NullLogger{})
+	go server.Serve(l)
+	return server
+}
+
+func (s *RemoteTestSuite) TestSign() {
+	client, err := Dial("unix", s.address, time.Second)
+	s.Require().NoError(err)
+	defer client.Close()
+	prvKey, err := client.PrivateKey()
+	s.Require().NoError(err)
+	s.Equal(s.prvKey.PublicKey().Bytes(), prvKey.PublicKey().Bytes())
+	hash := common.NewRandomHash()
+	sig, err := prvKey.Sign(hash)
+	s.Require().NoError(err)
+	s.True(s.prvKey.PublicKey().VerifySignature(hash, sig))
+	// Sign via utils.Signer.
+	signer := utils.NewSigner(prvKey)
+	b := &types.Block{
+		ParentHash: common.NewRandomHash(),
+		Position:   types.Position{Round: 1, Height: 10},
+		Timestamp:  time.Now().UTC(),
+	}
+	s.Require().NoError(signer.SignBlock(b))
+	s.Require().NoError(utils.VerifyBlockSignature(b))
+	s.Equal(types.NewNodeID(s.prvKey.PublicKey()), b.ProposerID)
+	v := types.NewVote(types.VoteCom, common.NewRandomHash(), 1)
+	s.Require().NoError(signer.SignVote(v))
+	ok, err := utils.VerifyVoteSignature(v)
+	s.Require().NoError(err)
+	s.True(ok)
+}
+
+func (s *RemoteTestSuite) TestSignDKG() {
+	var _ core.DKGSigner = (*Client)(nil)
+	client, err := Dial("unix", s.address, time.Second)
+	s.Require().NoError(err)
+	defer client.Close()
+	hash := common.NewRandomHash()
+	_, err = client.SignDKG(3, 1, hash)
+	s.Equal(ErrDKGKeyNotFound.Error(), err.Error())
+	// Hand over the share of round 3.
+	dkgKey := dkg.NewPrivateKey()
+	s.Require().NoError(client.PutDKGPrivateKey(3, 1, *dkgKey))
+	sig, err := client.SignDKG(3, 1, hash)
+	s.Require().NoError(err)
+	s.True(dkgKey.PublicKey().VerifySignature(hash, sig))
+	_, err = client.SignDKG(3, 0, hash)
+	s.Equal(ErrDKGKeyNotFound.Error(), err.Error())
+	// The share is loaded from the directory after restarting.
+	s.Require().NoError(s.server.Close())
+	keyDir, err := NewDKGKeyDir(filepath.Join(s.dir, "dkg"))
+	s.Require().NoError(err)
+	s.server = s.startServer(keyDir)
+	client, err = Dial("unix", s.address, time.Second)
+	s.Require().NoError(err)
+	defer client.Close()
+	sig, err = client.SignDKG(3, 1, hash)
+	s.Require().NoError(err)
+	s.True(dkgKey.PublicKey().VerifySignature(hash, sig))
+	// Sign CRS via utils.Signer.
+	signer := utils.NewSigner(s.prvKey)
+	signer.SetBLSSigner(
+		func(round uint64, hash common.Hash) (crypto.Signature, error) {
+			return client.SignDKG(round, 1, hash)
+		})
+	b := &types.Block{
+		ProposerID: types.NewNodeID(s.prvKey.PublicKey()),
+		Position:   types.Position{Round: 3},
+	}
+	s.Require().NoError(signer.SignCRS(b, common.NewRandomHash(), 1))
+	s.NotEmpty(b.CRSSignature.Signature)
+}
+
+func (s *RemoteTestSuite) TestReconnect() {
+	client, err := Dial("unix", s.address, time.Second)
+	s.Require().NoError(err)
+	defer client.Close()
+	prvKey, err := client.PrivateKey()
+	s.Require().NoError(err)
+	// Restart the server.
+	s.Require().NoError(s.server.Close())
+	_, err = prvKey.Sign(common.NewRandomHash())
+	s.Require().Error(err)
+	s.server = s.startServer(nil)
+	_, err = prvKey.Sign(common.NewRandomHash())
+	s.Require().NoError(err)
+	// No DKG key store.
+	_, err = client.SignDKG(3, 0, common.NewRandomHash())
+	s.Equal(ErrNoDKGKeyStore.Error(), err.Error())
+}
+
+func (s *RemoteTestSuite) TestIncorrectSignature() {
+	client, err := Dial("unix", s.address, time.Second)
+	s.Require().NoError(err)
+	defer client.Close()
+	prvKey, err := client.PrivateKey()
+	s.Require().NoError(err)
+	// Pretend the server holds another key.
+	anotherKey, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	prvKey.pubKey = anotherKey.PublicKey()
+	_, err = prvKey.Sign(common.NewRandomHash())
+	s.Equal(ErrIncorrectSignature, err)
+}
+
+func (s *RemoteTestSuite) TestTCP() {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	server := NewServer(s.prvKey, nil, &common.NullLogger{})
+	defer server.Close()
+	go server.Serve(l)
+	client, err := Dial("tcp", l.Addr().String(), time.Second)
+	s.Require().NoError(err)
+	defer client.Close()
+	prvKey, err := client.PrivateKey()
+	s.Require().NoError(err)
+	_, err = prvKey.Sign(common.NewRandomHash())
+	s.Require().NoError(err)
+}
+
+func TestRemote(t *testing.T) {
+	suite.Run(t, new(RemoteTestSuite))
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package remote
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+)
+
+// Server serves signing requests with keys it holds.
+type Server struct {
+	prvKey  crypto.PrivateKey
+	dkgKeys DKGKeyStore
+	logger  common.Logger
+
+	lock      sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	waitGroup sync.WaitGroup
+}
+
+// NewServer creates a Server instance, dkgKeys could be nil when keeping DKG
+// private shares is not required.
+func NewServer(prvKey crypto.PrivateKey, dkgKeys DKGKeyStore,
+	logger common.Logger) *Server {
+	return &Server{
+		prvKey:    prvKey,
+		dkgKeys:   dkgKeys,
+		logger:    logger,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve accepts connections from the listener until it's closed, it always
+// returns a non-nil error.
+func (s *Server) Serve(l net.Listener) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		l.Close()
+		return io.ErrClosedPipe
+	}
+	s.listeners[l] = struct{}{}
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		delete(s.listeners, l)
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		s.lock.Lock()
+		if s.closed {
+			s.lock.Unlock()
+			conn.Close()
+			return io.ErrClosedPipe
+		}
+		s.conns[conn] = struct{}{}
+		s.waitGroup.Add(1)
+		s.lock.Unlock()
+		go s.handle(conn)
+	}
+}
+
+// Close closes all listeners and connections, and waits for all handlers to
+// return.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+	s.waitGroup.Wait()
+	return nil
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		delete(s.conns, conn)
+		conn.Close()
+		s.waitGroup.Done()
+	}()
+	var (
+		dec = json.NewDecoder(conn)
+		enc = json.NewEncoder(conn)
+	)
+	for {
+		req := &request{}
+		if err := dec.Decode(req); err != nil {
+			if err != io.EOF {
+				s.logger.Debug("Failed to decode request", "error", err)
+			}
+			return
+		}
+		res := s.process(req)
+		if err := enc.Encode(res); err != nil {
+			s.logger.Debug("Failed to send response", "error", err)
+			return
+		}
+	}
+}
+
+func (s *Server) process(req *request) (res *response) {
+	var err error
+	res = &response{}
+	switch req.Method {
+	case methodPublicKey:
+		res.PublicKey = s.prvKey.PublicKey().Bytes()
+	case methodSign:
+		res.Signature, err = s.prvKey.Sign(req.Hash)
+	case methodPutDKG:
+		prvKey := &dkg.PrivateKey{}
+		if s.dkgKeys == nil {
+			err = ErrNoDKGKeyStore
+		} else if err = prvKey.SetBytes(req.PrivateKey); err == nil {
+			err = s.dkgKeys.PutDKGPrivateKey(req.Round, req.Reset, prvKey)
+		}
+	case methodSignDKG:
+		var prvKey *dkg.PrivateKey
+		if s.dkgKeys == nil {
+			err = ErrNoDKGKeyStore
+		} else if prvKey, err = s.dkgKeys.GetDKGPrivateKey(
+			req.Round, req.Reset); err == nil {
+			res.Signature, err = prvKey.Sign(req.Hash)
+		}
+	default:
+		err = ErrUnknownMethod
+	}
+	if err != nil {
+		s.logger.Warn("Failed to process request",
+			"method", req.Method,
+			"round", req.Round,
+			"reset", req.Reset,
+			"error", err)
+		res.Error = err.Error()
+	}
+	return
+}
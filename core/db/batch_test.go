@@ -0,0 +1,120 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// crashTestEnv is the environment variable passing the DB path to the
+// process killed in crash tests.
+const crashTestEnv = "DEXCON_DB_CRASH_TEST_PATH"
+
+type BatchTestSuite struct {
+	suite.Suite
+}
+
+// TestCrash kills a process writing batches to LevelDBBackedDB at random
+// time, and checks the tip of compaction chain always matches stored blocks.
+func (s *BatchTestSuite) TestCrash() {
+	if testing.Short() {
+		s.T().Skip("skipping crash test in short mode")
+	}
+	dbName := fmt.Sprintf("test-db-%v-crash.db", time.Now().UTC())
+	defer os.RemoveAll(dbName)
+	var lastTip uint64
+	for i := 0; i < 5; i++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestBatchCrashWriter$")
+		cmd.Env = append(os.Environ(), crashTestEnv+"="+dbName)
+		s.Require().NoError(cmd.Start())
+		time.Sleep(time.Duration(500+rand.Intn(500)) * time.Millisecond)
+		s.Require().NoError(cmd.Process.Kill())
+		cmd.Wait()
+
+		dbInst, err := NewLevelDBBackedDB(dbName)
+		s.Require().NoError(err)
+		hash, tip := dbInst.GetCompactionChainTipInfo()
+		s.Require().True(tip >= lastTip)
+		if tip > 0 {
+			b, err := dbInst.GetBlock(hash)
+			s.Require().NoError(err)
+			s.Require().Equal(tip, b.Position.Height)
+		}
+		// Blocks written with the tip should all exist, and no block should
+		// be written without the tip.
+		iter, err := dbInst.GetBlocksInRange(0, tip+100)
+		s.Require().NoError(err)
+		heights, err := collectHeights(iter)
+		s.Require().NoError(err)
+		s.Require().Len(heights, int(tip))
+		for j, h := range heights {
+			s.Require().Equal(uint64(j+1), h)
+		}
+		s.Require().NoError(dbInst.Close())
+		lastTip = tip
+	}
+	s.True(lastTip > 0)
+}
+
+// TestBatchCrashWriter keeps writing batches to the DB until killed, it's
+// only run as a child process of BatchTestSuite.TestCrash.
+func TestBatchCrashWriter(t *testing.T) {
+	dbName := os.Getenv(crashTestEnv)
+	if dbName == "" {
+		t.Skip("only run by crash test")
+	}
+	dbInst, err := NewLevelDBBackedDB(dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, 4096)
+	for {
+		_, tip := dbInst.GetCompactionChainTipInfo()
+		batch := dbInst.NewBatch()
+		for h := tip + 1; h <= tip+10; h++ {
+			b := types.Block{
+				Hash:     common.NewRandomHash(),
+				Position: types.Position{Height: h},
+				Payload:  payload,
+			}
+			if err = batch.PutBlock(b); err != nil {
+				t.Fatal(err)
+			}
+			if err = batch.PutCompactionChainTipInfo(b.Hash, h); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = batch.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBatch(t *testing.T) {
+	suite.Run(t, new(BatchTestSuite))
+}
@@ -0,0 +1,489 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+// Buckets and keys used in BoltBackedDB.
+var (
+	boltBlocksBucket        = []byte("blocks")
+	boltBlockHeightsBucket  = []byte("block-heights")
+	boltDKGPrivateKeyBucket = []byte("dkg-prvs")
+	boltMetaBucket          = []byte("meta")
+
+	boltBuckets = [][]byte{
+		boltBlocksBucket,
+		boltBlockHeightsBucket,
+		boltDKGPrivateKeyBucket,
+		boltMetaBucket,
+	}
+)
+
+// boltSchemaVersion is the version of schema written by BoltBackedDB.
+const boltSchemaVersion = 1
+
+func encodeUint64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// BoltBackedDB is a DB implementation backed by bbolt, a pure-Go B+tree
+// store. All data is kept in a single file, and each write is committed in a
+// transaction.
+type BoltBackedDB struct {
+	db *bolt.DB
+}
+
+// NewBoltBackedDB opens or creates a bbolt backed database in the file at
+// path.
+func NewBoltBackedDB(path string) (b *BoltBackedDB, err error) {
+	if len(path) == 0 {
+		err = ErrEmptyPath
+		return
+	}
+	dbInst, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return
+	}
+	err = dbInst.Update(func(tx *bolt.Tx) error {
+		for _, name := range boltBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		meta := tx.Bucket(boltMetaBucket)
+		if v := meta.Get(schemaVersionKey); v != nil {
+			if binary.BigEndian.Uint64(v) > boltSchemaVersion {
+				return ErrUnknownSchemaVersion
+			}
+			return nil
+		}
+		return meta.Put(schemaVersionKey, encodeUint64(boltSchemaVersion))
+	})
+	if err != nil {
+		dbInst.Close()
+		return
+	}
+	b = &BoltBackedDB{db: dbInst}
+	return
+}
+
+// Close implement Closer interface, which would release allocated resource.
+func (b *BoltBackedDB) Close() error {
+	return b.db.Close()
+}
+
+// HasBlock implements the Reader.Has method.
+func (b *BoltBackedDB) HasBlock(hash common.Hash) (exists bool) {
+	b.db.View(func(tx *bolt.Tx) error {
+		exists = tx.Bucket(boltBlocksBucket).Get(hash[:]) != nil
+		return nil
+	})
+	return
+}
+
+func boltGetBlock(tx *bolt.Tx, hash []byte) (block types.Block, err error) {
+	queried := tx.Bucket(boltBlocksBucket).Get(hash)
+	if queried == nil {
+		err = ErrBlockDoesNotExist
+		return
+	}
+	err = rlp.DecodeBytes(queried, &block)
+	return
+}
+
+// GetBlock implements the Reader.GetBlock method.
+func (b *BoltBackedDB) GetBlock(hash common.Hash) (block types.Block, err error) {
+	err = b.db.View(func(tx *bolt.Tx) (err error) {
+		block, err = boltGetBlock(tx, hash[:])
+		return
+	})
+	return
+}
+
+// GetAllBlocks implements Reader.GetAllBlocks method, blocks are iterated in
+// the order of their hashes.
+func (b *BoltBackedDB) GetAllBlocks() (BlockIterator, error) {
+	return &boltBlockIterator{db: b, from: []byte{}}, nil
+}
+
+// GetBlockByHeight implements the Reader.GetBlockByHeight method.
+func (b *BoltBackedDB) GetBlockByHeight(
+	height uint64) (block types.Block, err error) {
+	err = b.db.View(func(tx *bolt.Tx) (err error) {
+		hash := tx.Bucket(boltBlockHeightsBucket).Get(encodeUint64(height))
+		if hash == nil {
+			return ErrBlockDoesNotExist
+		}
+		block, err = boltGetBlock(tx, hash)
+		return
+	})
+	return
+}
+
+// GetBlocksInRange implements the Reader.GetBlocksInRange method.
+func (b *BoltBackedDB) GetBlocksInRange(
+	from, to uint64) (BlockIterator, error) {
+	return &boltBlockIterator{
+		db:       b,
+		from:     encodeUint64(from),
+		to:       encodeUint64(to),
+		byHeight: true,
+	}, nil
+}
+
+// GetBlocksInRangeReverse implements the Reader.GetBlocksInRangeReverse
+// method.
+func (b *BoltBackedDB) GetBlocksInRangeReverse(
+	from, to uint64) (BlockIterator, error) {
+	return &boltBlockIterator{
+		db:       b,
+		from:     encodeUint64(from),
+		to:       encodeUint64(to),
+		byHeight: true,
+		reverse:  true,
+	}, nil
+}
+
+// GetCompactionChainTipInfo get the tip info of compaction chain into the
+// database.
+func (b *BoltBackedDB) GetCompactionChainTipInfo() (
+	hash common.Hash, height uint64) {
+	var info compactionChainTipInfo
+	if err := b.db.View(func(tx *bolt.Tx) (err error) {
+		info, err = boltGetCompactionChainTipInfo(tx)
+		return
+	}); err != nil {
+		panic(err)
+	}
+	hash, height = info.Hash, info.Height
+	return
+}
+
+func boltGetCompactionChainTipInfo(
+	tx *bolt.Tx) (info compactionChainTipInfo, err error) {
+	queried := tx.Bucket(boltMetaBucket).Get(compactionChainTipInfoKey)
+	if queried == nil {
+		return
+	}
+	err = rlp.DecodeBytes(queried, &info)
+	return
+}
+
+// GetDKGPrivateKey get DKG private key of one round.
+func (b *BoltBackedDB) GetDKGPrivateKey(round, reset uint64) (
+	prv dkg.PrivateKey, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		queried := tx.Bucket(boltDKGPrivateKeyBucket).Get(encodeUint64(round))
+		if queried == nil {
+			return ErrDKGPrivateKeyDoesNotExist
+		}
+		pk := dkgPrivateKey{}
+		if err := rlp.DecodeBytes(queried, &pk); err != nil {
+			return err
+		}
+		if pk.Reset != reset {
+			return ErrDKGPrivateKeyDoesNotExist
+		}
+		prv = pk.PK
+		return nil
+	})
+	return
+}
+
+// GetDKGProtocol get DKG protocol.
+func (b *BoltBackedDB) GetDKGProtocol() (info DKGProtocolInfo, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		queried := tx.Bucket(boltMetaBucket).Get(dkgProtocolInfoKeyPrefix)
+		if queried == nil {
+			return ErrDKGProtocolDoesNotExist
+		}
+		return rlp.DecodeBytes(queried, &info)
+	})
+	return
+}
+
+// UpdateBlock implements the Writer.UpdateBlock method.
+func (b *BoltBackedDB) UpdateBlock(block types.Block) error {
+	batch := b.NewBatch()
+	if err := batch.UpdateBlock(block); err != nil {
+		batch.Rollback()
+		return err
+	}
+	return batch.Commit()
+}
+
+// PutBlock implements the Writer.PutBlock method.
+func (b *BoltBackedDB) PutBlock(block types.Block) error {
+	batch := b.NewBatch()
+	if err := batch.PutBlock(block); err != nil {
+		batch.Rollback()
+		return err
+	}
+	return batch.Commit()
+}
+
+// PutCompactionChainTipInfo saves tip of compaction chain into the database.
+func (b *BoltBackedDB) PutCompactionChainTipInfo(
+	blockHash common.Hash, height uint64) error {
+	batch := b.NewBatch()
+	if err := batch.PutCompactionChainTipInfo(blockHash, height); err != nil {
+		batch.Rollback()
+		return err
+	}
+	return batch.Commit()
+}
+
+// PutDKGPrivateKey save DKG private key of one round.
+func (b *BoltBackedDB) PutDKGPrivateKey(
+	round, reset uint64, prv dkg.PrivateKey) error {
+	marshaled, err := rlp.EncodeToBytes(&dkgPrivateKey{
+		PK:    prv,
+		Reset: reset,
+	})
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltDKGPrivateKeyBucket)
+		key := encodeUint64(round)
+		if queried := bucket.Get(key); queried != nil {
+			pk := dkgPrivateKey{}
+			if err := rlp.DecodeBytes(queried, &pk); err != nil {
+				return err
+			}
+			if pk.Reset == reset {
+				return ErrDKGPrivateKeyExists
+			}
+		}
+		return bucket.Put(key, marshaled)
+	})
+}
+
+// PutOrUpdateDKGProtocol save DKG protocol.
+func (b *BoltBackedDB) PutOrUpdateDKGProtocol(info DKGProtocolInfo) error {
+	marshaled, err := rlp.EncodeToBytes(&info)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltMetaBucket).Put(dkgProtocolInfoKeyPrefix, marshaled)
+	})
+}
+
+// NewBatch implements the Writer.NewBatch method.
+func (b *BoltBackedDB) NewBatch() Batch {
+	return &boltBatch{
+		db:     b,
+		blocks: make(map[common.Hash]struct{}),
+	}
+}
+
+// boltBlockIterator iterates blocks by hashes, or by heights in [from, to).
+// Like levelDBBlockIterator, it seeks for each block in a new read-only
+// transaction, so there is nothing to release.
+type boltBlockIterator struct {
+	db       *BoltBackedDB
+	from, to []byte
+	byHeight bool
+	reverse  bool
+	done     bool
+}
+
+// NextBlock implements BlockIterator.NextBlock method.
+func (it *boltBlockIterator) NextBlock() (block types.Block, err error) {
+	if it.done {
+		err = ErrIterationFinished
+		return
+	}
+	err = it.db.db.View(func(tx *bolt.Tx) (err error) {
+		bucket := boltBlocksBucket
+		if it.byHeight {
+			bucket = boltBlockHeightsBucket
+		}
+		c := tx.Bucket(bucket).Cursor()
+		var k, v []byte
+		if it.reverse {
+			// Seek to the first key not less than "to" and step back.
+			if k, _ = c.Seek(it.to); k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+			if k != nil && string(k) < string(it.from) {
+				k = nil
+			}
+		} else {
+			k, v = c.Seek(it.from)
+			if k != nil && it.to != nil && string(k) >= string(it.to) {
+				k = nil
+			}
+		}
+		if k == nil {
+			it.done = true
+			return ErrIterationFinished
+		}
+		if it.reverse {
+			it.to = append([]byte(nil), k...)
+		} else {
+			// The smallest key greater than k.
+			it.from = append(append([]byte(nil), k...), 0)
+		}
+		if !it.byHeight {
+			return rlp.DecodeBytes(v, &block)
+		}
+		block, err = boltGetBlock(tx, v)
+		return
+	})
+	return
+}
+
+type boltBatchOp struct {
+	key, value []byte
+	height     []byte
+}
+
+// boltBatch is a Batch applying all writes in one bbolt transaction.
+type boltBatch struct {
+	db     *BoltBackedDB
+	ops    []boltBatchOp
+	blocks map[common.Hash]struct{}
+	tip    *compactionChainTipInfo
+	closed bool
+}
+
+func (b *boltBatch) hasBlock(hash common.Hash) bool {
+	if _, exists := b.blocks[hash]; exists {
+		return true
+	}
+	return b.db.HasBlock(hash)
+}
+
+func (b *boltBatch) putBlock(block *types.Block) error {
+	marshaled, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return err
+	}
+	b.ops = append(b.ops, boltBatchOp{
+		key:    append([]byte(nil), block.Hash[:]...),
+		value:  marshaled,
+		height: encodeUint64(block.Position.Height),
+	})
+	b.blocks[block.Hash] = struct{}{}
+	return nil
+}
+
+// PutBlock implements Batch.PutBlock method.
+func (b *boltBatch) PutBlock(block types.Block) error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	if b.hasBlock(block.Hash) {
+		return ErrBlockExists
+	}
+	return b.putBlock(&block)
+}
+
+// UpdateBlock implements Batch.UpdateBlock method.
+func (b *boltBatch) UpdateBlock(block types.Block) error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	if !b.hasBlock(block.Hash) {
+		return ErrBlockDoesNotExist
+	}
+	return b.putBlock(&block)
+}
+
+// PutCompactionChainTipInfo implements Batch.PutCompactionChainTipInfo
+// method.
+func (b *boltBatch) PutCompactionChainTipInfo(
+	blockHash common.Hash, height uint64) error {
+	return b.putTip(blockHash, height, (*compactionChainTipInfo).isNext)
+}
+
+// SeedCompactionChainTip implements Batch.SeedCompactionChainTip method.
+func (b *boltBatch) SeedCompactionChainTip(
+	blockHash common.Hash, height uint64) error {
+	return b.putTip(blockHash, height, (*compactionChainTipInfo).canSeed)
+}
+
+func (b *boltBatch) putTip(blockHash common.Hash, height uint64,
+	valid func(*compactionChainTipInfo, uint64) bool) error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	current := b.tip
+	if current == nil {
+		hash, height := b.db.GetCompactionChainTipInfo()
+		current = &compactionChainTipInfo{Hash: hash, Height: height}
+	}
+	if !valid(current, height) {
+		return ErrInvalidCompactionChainTipHeight
+	}
+	b.tip = &compactionChainTipInfo{Hash: blockHash, Height: height}
+	return nil
+}
+
+// Commit implements Batch.Commit method.
+func (b *boltBatch) Commit() error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	b.closed = true
+	var tip []byte
+	if b.tip != nil {
+		var err error
+		if tip, err = rlp.EncodeToBytes(b.tip); err != nil {
+			return err
+		}
+	}
+	return b.db.db.Update(func(tx *bolt.Tx) error {
+		blocks := tx.Bucket(boltBlocksBucket)
+		heights := tx.Bucket(boltBlockHeightsBucket)
+		for _, op := range b.ops {
+			if err := blocks.Put(op.key, op.value); err != nil {
+				return err
+			}
+			if err := heights.Put(op.height, op.key); err != nil {
+				return err
+			}
+		}
+		if tip == nil {
+			return nil
+		}
+		return tx.Bucket(boltMetaBucket).Put(compactionChainTipInfoKey, tip)
+	})
+}
+
+// Rollback implements Batch.Rollback method.
+func (b *boltBatch) Rollback() {
+	b.closed = true
+	b.ops = nil
+}
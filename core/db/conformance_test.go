@@ -0,0 +1,54 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package db_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/db/dbtest"
+)
+
+func TestLevelDBConformance(t *testing.T) {
+	suite.Run(t, &dbtest.ConformanceTestSuite{
+		NewDB: func(path string) (db.Database, error) {
+			return db.NewLevelDBBackedDB(path)
+		},
+		Persistent: true,
+	})
+}
+
+func TestBoltDBConformance(t *testing.T) {
+	suite.Run(t, &dbtest.ConformanceTestSuite{
+		NewDB: func(path string) (db.Database, error) {
+			return db.NewBoltBackedDB(path)
+		},
+		Persistent: true,
+	})
+}
+
+func TestMemBackedDBConformance(t *testing.T) {
+	suite.Run(t, &dbtest.ConformanceTestSuite{
+		NewDB: func(path string) (db.Database, error) {
+			return db.NewMemBackedDB(path)
+		},
+		Persistent: true,
+	})
+}
@@ -0,0 +1,384 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+// Package dbtest provides a conformance test suite for db.Database
+// implementations.
+package dbtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// ConformanceTestSuite runs the same cases against a db.Database
+// implementation, it could be run by:
+//
+//	suite.Run(t, &dbtest.ConformanceTestSuite{
+//		NewDB: func(path string) (db.Database, error) {
+//			return NewMyDB(path)
+//		},
+//		Persistent: true,
+//	})
+type ConformanceTestSuite struct {
+	suite.Suite
+
+	// NewDB opens a database at path. A new empty path in a temporary
+	// directory is given for each test case, and the same path is given when
+	// the database is reopened.
+	NewDB func(path string) (db.Database, error)
+	// Persistent indicates all data should be loaded back after the
+	// database is closed and opened again.
+	Persistent bool
+
+	dir    string
+	path   string
+	dbInst db.Database
+}
+
+// SetupTest opens a new database for each test case.
+func (s *ConformanceTestSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "dexcon-dbtest")
+	s.Require().NoError(err)
+	s.path = filepath.Join(s.dir, "db")
+	s.dbInst, err = s.NewDB(s.path)
+	s.Require().NoError(err)
+}
+
+// TearDownTest closes and removes the database.
+func (s *ConformanceTestSuite) TearDownTest() {
+	if s.dbInst != nil {
+		s.NoError(s.dbInst.Close())
+		s.dbInst = nil
+	}
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *ConformanceTestSuite) reopen() {
+	s.Require().NoError(s.dbInst.Close())
+	s.dbInst = nil
+	dbInst, err := s.NewDB(s.path)
+	s.Require().NoError(err)
+	s.dbInst = dbInst
+}
+
+func (s *ConformanceTestSuite) collectHeights(
+	iter db.BlockIterator, err error) (heights []uint64) {
+	s.Require().NoError(err)
+	for {
+		b, err := iter.NextBlock()
+		if err == db.ErrIterationFinished {
+			return
+		}
+		s.Require().NoError(err)
+		heights = append(heights, b.Position.Height)
+	}
+}
+
+func newBlock(height uint64) types.Block {
+	return types.Block{
+		ProposerID: types.NodeID{Hash: common.NewRandomHash()},
+		Hash:       common.NewRandomHash(),
+		Position: types.Position{
+			Round:  height / 10,
+			Height: height,
+		},
+	}
+}
+
+// TestBasicUsage checks reading and writing blocks.
+func (s *ConformanceTestSuite) TestBasicUsage() {
+	dbInst := s.dbInst
+	// Queried something from an empty database.
+	block := newBlock(1)
+	_, err := dbInst.GetBlock(block.Hash)
+	s.Equal(db.ErrBlockDoesNotExist, err)
+	s.False(dbInst.HasBlock(block.Hash))
+	// Update on an empty database should not success.
+	s.Equal(db.ErrBlockDoesNotExist, dbInst.UpdateBlock(block))
+	// Put to create a new record should just work fine.
+	s.Require().NoError(dbInst.PutBlock(block))
+	s.True(dbInst.HasBlock(block.Hash))
+	queried, err := dbInst.GetBlock(block.Hash)
+	s.Require().NoError(err)
+	s.Equal(block.ProposerID, queried.ProposerID)
+	s.Equal(block.Position, queried.Position)
+	// Test Update.
+	queried.Randomness = []byte{1, 2, 3}
+	s.Require().NoError(dbInst.UpdateBlock(queried))
+	queried, err = dbInst.GetBlock(block.Hash)
+	s.Require().NoError(err)
+	s.Equal([]byte{1, 2, 3}, queried.Randomness)
+	// Put again should not success.
+	s.Equal(db.ErrBlockExists, dbInst.PutBlock(block))
+}
+
+// TestGetAllBlocks checks iterating all blocks, it's allowed to be not
+// implemented.
+func (s *ConformanceTestSuite) TestGetAllBlocks() {
+	hashes := common.Hashes{}
+	for h := uint64(0); h < 3; h++ {
+		b := newBlock(h)
+		s.Require().NoError(s.dbInst.PutBlock(b))
+		hashes = append(hashes, b.Hash)
+	}
+	iter, err := s.dbInst.GetAllBlocks()
+	if err == db.ErrNotImplemented {
+		return
+	}
+	s.Require().NoError(err)
+	touched := common.Hashes{}
+	for {
+		b, err := iter.NextBlock()
+		if err == db.ErrIterationFinished {
+			break
+		}
+		s.Require().NoError(err)
+		touched = append(touched, b.Hash)
+	}
+	s.ElementsMatch(hashes, touched)
+}
+
+// TestBlocksByHeight checks reading blocks by height.
+func (s *ConformanceTestSuite) TestBlocksByHeight() {
+	dbInst := s.dbInst
+	_, err := dbInst.GetBlockByHeight(1)
+	s.Equal(db.ErrBlockDoesNotExist, err)
+	// Put blocks out of order, with a gap at height 4.
+	for _, h := range []uint64{3, 1, 2, 5} {
+		s.Require().NoError(dbInst.PutBlock(newBlock(h)))
+	}
+	b, err := dbInst.GetBlockByHeight(3)
+	s.Require().NoError(err)
+	s.Equal(uint64(3), b.Position.Height)
+	_, err = dbInst.GetBlockByHeight(4)
+	s.Equal(db.ErrBlockDoesNotExist, err)
+
+	s.Equal([]uint64{2, 3, 5},
+		s.collectHeights(dbInst.GetBlocksInRange(2, 6)))
+	s.Equal([]uint64{5, 3, 2},
+		s.collectHeights(dbInst.GetBlocksInRangeReverse(2, 6)))
+	s.Equal([]uint64{1},
+		s.collectHeights(dbInst.GetBlocksInRange(0, 2)))
+	s.Equal([]uint64{3, 2, 1},
+		s.collectHeights(dbInst.GetBlocksInRangeReverse(0, 5)))
+	s.Empty(s.collectHeights(dbInst.GetBlocksInRange(4, 5)))
+	s.Empty(s.collectHeights(dbInst.GetBlocksInRangeReverse(3, 3)))
+	s.Empty(s.collectHeights(dbInst.GetBlocksInRange(6, 100)))
+}
+
+// TestCompactionChainTipInfo checks the tip of compaction chain.
+func (s *ConformanceTestSuite) TestCompactionChainTipInfo() {
+	dbInst := s.dbInst
+	hash, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(common.Hash{}, hash)
+	s.Equal(uint64(0), height)
+	// Save some tip info.
+	hash = common.NewRandomHash()
+	s.Require().NoError(dbInst.PutCompactionChainTipInfo(hash, 1))
+	hashBack, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(hash, hashBack)
+	s.Equal(uint64(1), height)
+	// Unable to put compaction chain tip info with lower height.
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		dbInst.PutCompactionChainTipInfo(hash, 0))
+	// Unable to put compaction chain tip info with height not incremental
+	// by 1.
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		dbInst.PutCompactionChainTipInfo(hash, 3))
+	// It's OK to put compaction chain tip info with height incremental by 1.
+	s.Require().NoError(dbInst.PutCompactionChainTipInfo(hash, 2))
+}
+
+// TestCompactionChainTipFromCheckpoint checks an empty database could only be
+// seeded with a tip at any height via Batch.SeedCompactionChainTip.
+func (s *ConformanceTestSuite) TestCompactionChainTipFromCheckpoint() {
+	dbInst := s.dbInst
+	hash := common.NewRandomHash()
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		dbInst.PutCompactionChainTipInfo(hash, 0))
+	// Normal writes to an empty database should start from height 1.
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		dbInst.PutCompactionChainTipInfo(hash, 100))
+	batch := dbInst.NewBatch()
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		batch.PutCompactionChainTipInfo(hash, 100))
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		batch.SeedCompactionChainTip(hash, 0))
+	s.Require().NoError(batch.SeedCompactionChainTip(hash, 100))
+	// Only an empty database could be seeded.
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		batch.SeedCompactionChainTip(hash, 101))
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		batch.PutCompactionChainTipInfo(hash, 102))
+	s.Require().NoError(batch.Commit())
+	hashBack, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(hash, hashBack)
+	s.Equal(uint64(100), height)
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		dbInst.PutCompactionChainTipInfo(hash, 102))
+	s.Require().NoError(dbInst.PutCompactionChainTipInfo(hash, 101))
+	batch = dbInst.NewBatch()
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		batch.SeedCompactionChainTip(hash, 200))
+	batch.Rollback()
+}
+
+// TestDKGPrivateKey checks DKG private keys.
+func (s *ConformanceTestSuite) TestDKGPrivateKey() {
+	dbInst := s.dbInst
+	p := dkg.NewPrivateKey()
+	_, err := dbInst.GetDKGPrivateKey(1, 0)
+	s.Equal(db.ErrDKGPrivateKeyDoesNotExist, err)
+	s.Require().NoError(dbInst.PutDKGPrivateKey(1, 0, *p))
+	// Unable to get it because reset is different.
+	_, err = dbInst.GetDKGPrivateKey(1, 1)
+	s.Equal(db.ErrDKGPrivateKeyDoesNotExist, err)
+	// Put it again should not success.
+	s.Equal(db.ErrDKGPrivateKeyExists, dbInst.PutDKGPrivateKey(1, 0, *p))
+	queried, err := dbInst.GetDKGPrivateKey(1, 0)
+	s.Require().NoError(err)
+	s.Equal(p.Bytes(), queried.Bytes())
+	// Put it at different reset would replace the old one.
+	p2 := dkg.NewPrivateKey()
+	s.Require().NoError(dbInst.PutDKGPrivateKey(1, 1, *p2))
+	_, err = dbInst.GetDKGPrivateKey(1, 0)
+	s.Equal(db.ErrDKGPrivateKeyDoesNotExist, err)
+	queried, err = dbInst.GetDKGPrivateKey(1, 1)
+	s.Require().NoError(err)
+	s.Equal(p2.Bytes(), queried.Bytes())
+}
+
+func newDKGProtocolInfo(round uint64) db.DKGProtocolInfo {
+	return db.DKGProtocolInfo{
+		ID:                        types.NodeID{Hash: common.NewRandomHash()},
+		Round:                     round,
+		Threshold:                 3,
+		IsMasterPrivateShareEmpty: true,
+		IsPrvSharesEmpty:          true,
+		Step:                      2,
+	}
+}
+
+// TestDKGProtocol checks DKG protocol info.
+func (s *ConformanceTestSuite) TestDKGProtocol() {
+	dbInst := s.dbInst
+	_, err := dbInst.GetDKGProtocol()
+	s.Equal(db.ErrDKGProtocolDoesNotExist, err)
+	info := newDKGProtocolInfo(1)
+	s.Require().NoError(dbInst.PutOrUpdateDKGProtocol(info))
+	queried, err := dbInst.GetDKGProtocol()
+	s.Require().NoError(err)
+	s.True(info.Equal(&queried))
+	info = newDKGProtocolInfo(2)
+	s.Require().NoError(dbInst.PutOrUpdateDKGProtocol(info))
+	queried, err = dbInst.GetDKGProtocol()
+	s.Require().NoError(err)
+	s.True(info.Equal(&queried))
+}
+
+// TestBatch checks writes in db.Batch are validated and applied atomically.
+func (s *ConformanceTestSuite) TestBatch() {
+	dbInst := s.dbInst
+	b1, b2 := newBlock(1), newBlock(2)
+
+	// Nothing is written after rollback.
+	batch := dbInst.NewBatch()
+	s.Require().NoError(batch.PutBlock(b1))
+	s.Require().NoError(batch.PutCompactionChainTipInfo(b1.Hash, 1))
+	batch.Rollback()
+	s.False(dbInst.HasBlock(b1.Hash))
+	_, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(uint64(0), height)
+	s.Equal(db.ErrBatchClosed, batch.PutBlock(b1))
+	s.Equal(db.ErrBatchClosed, batch.Commit())
+
+	// Writes are validated against previous writes in the same batch.
+	batch = dbInst.NewBatch()
+	s.Equal(db.ErrBlockDoesNotExist, batch.UpdateBlock(b1))
+	s.Require().NoError(batch.PutBlock(b1))
+	s.Equal(db.ErrBlockExists, batch.PutBlock(b1))
+	b1.Randomness = []byte{1, 2, 3}
+	s.Require().NoError(batch.UpdateBlock(b1))
+	s.Require().NoError(batch.PutCompactionChainTipInfo(b1.Hash, 1))
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		batch.PutCompactionChainTipInfo(b2.Hash, 3))
+	s.Require().NoError(batch.PutBlock(b2))
+	s.Require().NoError(batch.PutCompactionChainTipInfo(b2.Hash, 2))
+	// Nothing is written before commit.
+	s.False(dbInst.HasBlock(b1.Hash))
+	s.Require().NoError(batch.Commit())
+	s.Equal(db.ErrBatchClosed, batch.Commit())
+	queried, err := dbInst.GetBlock(b1.Hash)
+	s.Require().NoError(err)
+	s.Equal(b1.Randomness, queried.Randomness)
+	queried, err = dbInst.GetBlockByHeight(2)
+	s.Require().NoError(err)
+	s.Equal(b2.Hash, queried.Hash)
+	hash, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(b2.Hash, hash)
+	s.Equal(uint64(2), height)
+
+	// Writes are validated against the database.
+	batch = dbInst.NewBatch()
+	s.Equal(db.ErrBlockExists, batch.PutBlock(b2))
+	s.Equal(db.ErrInvalidCompactionChainTipHeight,
+		batch.PutCompactionChainTipInfo(b2.Hash, 2))
+	batch.Rollback()
+}
+
+// TestPersistence checks all data is loaded back after reopened.
+func (s *ConformanceTestSuite) TestPersistence() {
+	if !s.Persistent {
+		s.T().Skip("not a persistent database")
+	}
+	blocks := []types.Block{newBlock(1), newBlock(2)}
+	for _, b := range blocks {
+		s.Require().NoError(s.dbInst.PutBlock(b))
+		s.Require().NoError(
+			s.dbInst.PutCompactionChainTipInfo(b.Hash, b.Position.Height))
+	}
+	prv := dkg.NewPrivateKey()
+	s.Require().NoError(s.dbInst.PutDKGPrivateKey(1, 2, *prv))
+	info := newDKGProtocolInfo(1)
+	s.Require().NoError(s.dbInst.PutOrUpdateDKGProtocol(info))
+
+	s.reopen()
+	for _, b := range blocks {
+		queried, err := s.dbInst.GetBlockByHeight(b.Position.Height)
+		s.Require().NoError(err)
+		s.Equal(b.Hash, queried.Hash)
+		s.Equal(b.ProposerID, queried.ProposerID)
+	}
+	hash, height := s.dbInst.GetCompactionChainTipInfo()
+	s.Equal(blocks[1].Hash, hash)
+	s.Equal(uint64(2), height)
+	queriedPrv, err := s.dbInst.GetDKGPrivateKey(1, 2)
+	s.Require().NoError(err)
+	s.Equal(prv.Bytes(), queriedPrv.Bytes())
+	queriedInfo, err := s.dbInst.GetDKGProtocol()
+	s.Require().NoError(err)
+	s.True(info.Equal(&queriedInfo))
+}
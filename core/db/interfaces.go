@@ -41,7 +41,8 @@ var (
 	ErrNotImplemented = fmt.Errorf("not implemented")
 	// ErrInvalidCompactionChainTipHeight means the newly updated height of
 	// the tip of compaction chain is invalid, usually means it's smaller than
-	// current cached one.
+	// current cached one. Only Batch.SeedCompactionChainTip could set the
+	// first tip of an empty database at any height, ex. from a checkpoint.
 	ErrInvalidCompactionChainTipHeight = fmt.Errorf(
 		"invalid compaction chain tip height")
 	// ErrDKGPrivateKeyExists raised when attempting to save DKG private key
@@ -56,6 +57,15 @@ var (
 	// ErrDKGProtocolDoesNotExist raised when the DKG protocol of the
 	// requested round does not exists.
 	ErrDKGProtocolDoesNotExist = errors.New("dkg protocol does not exists")
+	// ErrBatchClosed raised when using a Batch after it's committed or
+	// rolled back.
+	ErrBatchClosed = errors.New("batch closed")
+	// ErrUnknownSchemaVersion raised when opening a database written by a
+	// newer version of this package.
+	ErrUnknownSchemaVersion = errors.New("unknown db schema version")
+	// ErrPruningStarted raised when attempting to start the background
+	// pruning more than once.
+	ErrPruningStarted = errors.New("pruning started")
 )
 
 // Database is the interface for a Database.
@@ -74,6 +84,17 @@ type Reader interface {
 	GetBlock(hash common.Hash) (types.Block, error)
 	GetAllBlocks() (BlockIterator, error)
 
+	// GetBlockByHeight returns the block put into DB at height, when more
+	// than one block is put at the same height, the latest one is returned.
+	GetBlockByHeight(height uint64) (types.Block, error)
+	// GetBlocksInRange returns an iterator of blocks with height in
+	// [from, to), in ascending order of height. Heights without blocks are
+	// skipped.
+	GetBlocksInRange(from, to uint64) (BlockIterator, error)
+	// GetBlocksInRangeReverse is GetBlocksInRange in descending order of
+	// height.
+	GetBlocksInRangeReverse(from, to uint64) (BlockIterator, error)
+
 	// GetCompactionChainTipInfo returns the block hash and finalization height
 	// of the tip block of compaction chain. Empty hash and zero height means
 	// the compaction chain is empty.
@@ -91,6 +112,30 @@ type Writer interface {
 	PutCompactionChainTipInfo(common.Hash, uint64) error
 	PutDKGPrivateKey(round, reset uint64, pk dkg.PrivateKey) error
 	PutOrUpdateDKGProtocol(dkgProtocol DKGProtocolInfo) error
+
+	// NewBatch creates a Batch to write blocks and the tip of compaction
+	// chain atomically.
+	NewBatch() Batch
+}
+
+// Batch collects writes and applies them to DB atomically on Commit, none
+// of them is applied if Rollback is called. Writes are validated against the
+// DB and previous writes in the same batch. A Batch is not safe for
+// concurrent use, and could not be used after Commit or Rollback.
+type Batch interface {
+	PutBlock(block types.Block) error
+	UpdateBlock(block types.Block) error
+	PutCompactionChainTipInfo(common.Hash, uint64) error
+	// SeedCompactionChainTip sets the tip of compaction chain of an empty
+	// database at any height, ex. at the block of a checkpoint. Other writers
+	// should use PutCompactionChainTipInfo, which only moves the tip to the
+	// next height.
+	SeedCompactionChainTip(common.Hash, uint64) error
+
+	// Commit applies all writes in the batch.
+	Commit() error
+	// Rollback discards all writes in the batch.
+	Rollback()
 }
 
 // BlockIterator defines an iterator on blocks hold
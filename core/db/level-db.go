@@ -20,8 +20,10 @@ package db
 import (
 	"encoding/binary"
 	"io"
+	"sync"
 
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
 
 	"github.com/dexon-foundation/dexon-consensus/common"
 	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
@@ -31,6 +33,7 @@ import (
 
 var (
 	blockKeyPrefix            = []byte("b-")
+	blockHeightKeyPrefix      = []byte("bh-")
 	compactionChainTipInfoKey = []byte("cc-tip")
 	dkgPrivateKeyKeyPrefix    = []byte("dkg-prvs")
 	dkgProtocolInfoKeyPrefix  = []byte("dkg-protocol-info")
@@ -41,6 +44,16 @@ type compactionChainTipInfo struct {
 	Hash   common.Hash `json:"hash"`
 }
 
+// isNext checks if the tip could be moved to height.
+func (info *compactionChainTipInfo) isNext(height uint64) bool {
+	return info.Height+1 == height
+}
+
+// canSeed checks if the tip of an empty database could be seeded at height.
+func (info *compactionChainTipInfo) canSeed(height uint64) bool {
+	return info.Height == 0 && height > 0
+}
+
 // DKGProtocolInfo DKG protocol info.
 type DKGProtocolInfo struct {
 	ID                        types.NodeID
@@ -341,9 +354,185 @@ func (m *NodeIDToDKGID) DecodeRLP(s *rlp.Stream) error {
 	return nil
 }
 
+// levelDBBlockIterator iterates blocks through the height index. It seeks
+// the index for each block instead of holding a leveldb iterator, so there is
+// nothing to release when callers stop iterating halfway.
+type levelDBBlockIterator struct {
+	lvl      *LevelDBBackedDB
+	from, to uint64
+	reverse  bool
+}
+
+// NextBlock implements BlockIterator.NextBlock method.
+func (it *levelDBBlockIterator) NextBlock() (types.Block, error) {
+	for {
+		b, err := it.nextBlock()
+		// The block might be pruned after its index is read, just skip it.
+		if err != ErrBlockDoesNotExist {
+			return b, err
+		}
+	}
+}
+
+func (it *levelDBBlockIterator) nextBlock() (types.Block, error) {
+	if it.from >= it.to {
+		return types.Block{}, ErrIterationFinished
+	}
+	iter := it.lvl.db.NewIterator(&util.Range{
+		Start: it.lvl.getBlockHeightKey(it.from),
+		Limit: it.lvl.getBlockHeightKey(it.to),
+	}, nil)
+	defer iter.Release()
+	var found bool
+	if it.reverse {
+		found = iter.Last()
+	} else {
+		found = iter.First()
+	}
+	if !found {
+		if err := iter.Error(); err != nil {
+			return types.Block{}, err
+		}
+		it.from = it.to
+		return types.Block{}, ErrIterationFinished
+	}
+	height := binary.BigEndian.Uint64(iter.Key()[len(blockHeightKeyPrefix):])
+	if it.reverse {
+		it.to = height
+	} else {
+		it.from = height + 1
+	}
+	hash := common.Hash{}
+	copy(hash[:], iter.Value())
+	return it.lvl.GetBlock(hash)
+}
+
+// levelDBBatch is a Batch backed by leveldb.Batch, which is written to the
+// journal of leveldb as a single record.
+type levelDBBatch struct {
+	lvl    *LevelDBBackedDB
+	batch  *leveldb.Batch
+	blocks map[common.Hash]struct{}
+	tip    *compactionChainTipInfo
+	closed bool
+}
+
+func (b *levelDBBatch) hasBlock(hash common.Hash) (bool, error) {
+	if _, exists := b.blocks[hash]; exists {
+		return true, nil
+	}
+	return b.lvl.internalHasBlock(b.lvl.getBlockKey(hash))
+}
+
+func (b *levelDBBatch) putBlock(block *types.Block) error {
+	marshaled, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return err
+	}
+	b.batch.Put(b.lvl.getBlockKey(block.Hash), marshaled)
+	b.batch.Put(b.lvl.getBlockHeightKey(block.Position.Height), block.Hash[:])
+	b.blocks[block.Hash] = struct{}{}
+	return nil
+}
+
+// PutBlock implements Batch.PutBlock method.
+func (b *levelDBBatch) PutBlock(block types.Block) error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	exists, err := b.hasBlock(block.Hash)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrBlockExists
+	}
+	return b.putBlock(&block)
+}
+
+// UpdateBlock implements Batch.UpdateBlock method.
+func (b *levelDBBatch) UpdateBlock(block types.Block) error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	// NOTE: we didn't handle changes of block hash (and it
+	//       should not happen).
+	exists, err := b.hasBlock(block.Hash)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrBlockDoesNotExist
+	}
+	return b.putBlock(&block)
+}
+
+// PutCompactionChainTipInfo implements Batch.PutCompactionChainTipInfo
+// method.
+func (b *levelDBBatch) PutCompactionChainTipInfo(
+	blockHash common.Hash, height uint64) error {
+	return b.putTip(blockHash, height, (*compactionChainTipInfo).isNext)
+}
+
+// SeedCompactionChainTip implements Batch.SeedCompactionChainTip method.
+func (b *levelDBBatch) SeedCompactionChainTip(
+	blockHash common.Hash, height uint64) error {
+	return b.putTip(blockHash, height, (*compactionChainTipInfo).canSeed)
+}
+
+func (b *levelDBBatch) putTip(blockHash common.Hash, height uint64,
+	valid func(*compactionChainTipInfo, uint64) bool) error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	info := compactionChainTipInfo{
+		Hash:   blockHash,
+		Height: height,
+	}
+	marshaled, err := rlp.EncodeToBytes(&info)
+	if err != nil {
+		return err
+	}
+	// Check current cached tip info, including the one in this batch, to
+	// make sure the one to be updated is valid.
+	current := b.tip
+	if current == nil {
+		tip, err := b.lvl.internalGetCompactionChainTipInfo()
+		if err != nil {
+			return err
+		}
+		current = &tip
+	}
+	if !valid(current, height) {
+		return ErrInvalidCompactionChainTipHeight
+	}
+	b.batch.Put(compactionChainTipInfoKey, marshaled)
+	b.tip = &info
+	return nil
+}
+
+// Commit implements Batch.Commit method.
+func (b *levelDBBatch) Commit() error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	b.closed = true
+	return b.lvl.db.Write(b.batch, nil)
+}
+
+// Rollback implements Batch.Rollback method.
+func (b *levelDBBatch) Rollback() {
+	b.closed = true
+	b.batch.Reset()
+}
+
 // LevelDBBackedDB is a leveldb backed DB implementation.
 type LevelDBBackedDB struct {
 	db *leveldb.DB
+
+	pruneLock sync.Mutex
+	pruneStop chan struct{}
+	pruneDone chan struct{}
 }
 
 // NewLevelDBBackedDB initialize a leveldb-backed database.
@@ -355,11 +544,16 @@ func NewLevelDBBackedDB(
 		return
 	}
 	lvl = &LevelDBBackedDB{db: dbInst}
+	if err = lvl.upgradeSchema(); err != nil {
+		dbInst.Close()
+		lvl = nil
+	}
 	return
 }
 
 // Close implement Closer interface, which would release allocated resource.
 func (lvl *LevelDBBackedDB) Close() error {
+	lvl.stopPruning()
 	return lvl.db.Close()
 }
 
@@ -391,43 +585,61 @@ func (lvl *LevelDBBackedDB) GetBlock(
 }
 
 // UpdateBlock implements the Writer.UpdateBlock method.
-func (lvl *LevelDBBackedDB) UpdateBlock(block types.Block) (err error) {
-	// NOTE: we didn't handle changes of block hash (and it
-	//       should not happen).
-	marshaled, err := rlp.EncodeToBytes(&block)
-	if err != nil {
-		return
-	}
-	blockKey := lvl.getBlockKey(block.Hash)
-	exists, err := lvl.internalHasBlock(blockKey)
-	if err != nil {
-		return
-	}
-	if !exists {
-		err = ErrBlockDoesNotExist
-		return
+func (lvl *LevelDBBackedDB) UpdateBlock(block types.Block) error {
+	batch := lvl.NewBatch()
+	if err := batch.UpdateBlock(block); err != nil {
+		batch.Rollback()
+		return err
 	}
-	err = lvl.db.Put(blockKey, marshaled, nil)
-	return
+	return batch.Commit()
 }
 
 // PutBlock implements the Writer.PutBlock method.
-func (lvl *LevelDBBackedDB) PutBlock(block types.Block) (err error) {
-	marshaled, err := rlp.EncodeToBytes(&block)
-	if err != nil {
-		return
+func (lvl *LevelDBBackedDB) PutBlock(block types.Block) error {
+	batch := lvl.NewBatch()
+	if err := batch.PutBlock(block); err != nil {
+		batch.Rollback()
+		return err
 	}
-	blockKey := lvl.getBlockKey(block.Hash)
-	exists, err := lvl.internalHasBlock(blockKey)
-	if err != nil {
-		return
+	return batch.Commit()
+}
+
+// NewBatch implements the Writer.NewBatch method.
+func (lvl *LevelDBBackedDB) NewBatch() Batch {
+	return &levelDBBatch{
+		lvl:    lvl,
+		batch:  new(leveldb.Batch),
+		blocks: make(map[common.Hash]struct{}),
 	}
-	if exists {
-		err = ErrBlockExists
+}
+
+// GetBlockByHeight implements the Reader.GetBlockByHeight method.
+func (lvl *LevelDBBackedDB) GetBlockByHeight(
+	height uint64) (block types.Block, err error) {
+	queried, err := lvl.db.Get(lvl.getBlockHeightKey(height), nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			err = ErrBlockDoesNotExist
+		}
 		return
 	}
-	err = lvl.db.Put(blockKey, marshaled, nil)
-	return
+	hash := common.Hash{}
+	copy(hash[:], queried)
+	return lvl.GetBlock(hash)
+}
+
+// GetBlocksInRange implements the Reader.GetBlocksInRange method.
+func (lvl *LevelDBBackedDB) GetBlocksInRange(
+	from, to uint64) (BlockIterator, error) {
+	return &levelDBBlockIterator{lvl: lvl, from: from, to: to}, nil
+}
+
+// GetBlocksInRangeReverse implements the Reader.GetBlocksInRangeReverse
+// method.
+func (lvl *LevelDBBackedDB) GetBlocksInRangeReverse(
+	from, to uint64) (BlockIterator, error) {
+	return &levelDBBlockIterator{
+		lvl: lvl, from: from, to: to, reverse: true}, nil
 }
 
 // GetAllBlocks implements Reader.GetAllBlocks method, which allows callers
@@ -439,23 +651,12 @@ func (lvl *LevelDBBackedDB) GetAllBlocks() (BlockIterator, error) {
 // PutCompactionChainTipInfo saves tip of compaction chain into the database.
 func (lvl *LevelDBBackedDB) PutCompactionChainTipInfo(
 	blockHash common.Hash, height uint64) error {
-	marshaled, err := rlp.EncodeToBytes(&compactionChainTipInfo{
-		Hash:   blockHash,
-		Height: height,
-	})
-	if err != nil {
-		return err
-	}
-	// Check current cached tip info to make sure the one to be updated is
-	// valid.
-	info, err := lvl.internalGetCompactionChainTipInfo()
-	if err != nil {
+	batch := lvl.NewBatch()
+	if err := batch.PutCompactionChainTipInfo(blockHash, height); err != nil {
+		batch.Rollback()
 		return err
 	}
-	if info.Height+1 != height {
-		return ErrInvalidCompactionChainTipHeight
-	}
-	return lvl.db.Put(compactionChainTipInfoKey, marshaled, nil)
+	return batch.Commit()
 }
 
 func (lvl *LevelDBBackedDB) internalGetCompactionChainTipInfo() (
@@ -557,6 +758,14 @@ func (lvl *LevelDBBackedDB) getBlockKey(hash common.Hash) (ret []byte) {
 	return
 }
 
+func (lvl *LevelDBBackedDB) getBlockHeightKey(height uint64) (ret []byte) {
+	// Heights are encoded in big endian to keep the index sorted by height.
+	ret = make([]byte, len(blockHeightKeyPrefix)+8)
+	copy(ret, blockHeightKeyPrefix)
+	binary.BigEndian.PutUint64(ret[len(blockHeightKeyPrefix):], height)
+	return
+}
+
 func (lvl *LevelDBBackedDB) getDKGPrivateKeyKey(
 	round uint64) (ret []byte) {
 	ret = make([]byte, len(dkgPrivateKeyKeyPrefix)+8)
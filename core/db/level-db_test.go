@@ -20,6 +20,7 @@ package db
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -131,6 +132,72 @@ func (s *LevelDBTestSuite) TestSyncIndex() {
 	}
 }
 
+// collectHeights iterates all blocks from iter and returns their heights.
+func collectHeights(iter BlockIterator) (heights []uint64, err error) {
+	for {
+		var b types.Block
+		if b, err = iter.NextBlock(); err != nil {
+			if err == ErrIterationFinished {
+				err = nil
+			}
+			return
+		}
+		heights = append(heights, b.Position.Height)
+	}
+}
+
+func (s *LevelDBTestSuite) TestBlocksByHeight() {
+	dbName := fmt.Sprintf("test-db-%v-height.db", time.Now().UTC())
+	dbInst, err := NewLevelDBBackedDB(dbName)
+	s.Require().NoError(err)
+	defer func(dbName string) {
+		err = dbInst.Close()
+		s.NoError(err)
+		err = os.RemoveAll(dbName)
+		s.NoError(err)
+	}(dbName)
+
+	_, err = dbInst.GetBlockByHeight(1)
+	s.Equal(ErrBlockDoesNotExist, err)
+	// Put blocks with a gap at height 4.
+	for _, h := range []uint64{1, 2, 3, 5} {
+		s.Require().NoError(dbInst.PutBlock(types.Block{
+			Hash:     common.NewRandomHash(),
+			Position: types.Position{Height: h},
+		}))
+	}
+	b, err := dbInst.GetBlockByHeight(3)
+	s.Require().NoError(err)
+	s.Equal(uint64(3), b.Position.Height)
+	_, err = dbInst.GetBlockByHeight(4)
+	s.Equal(ErrBlockDoesNotExist, err)
+
+	check := func(reverse bool, from, to uint64, expected []uint64) {
+		var iter BlockIterator
+		if reverse {
+			iter, err = dbInst.GetBlocksInRangeReverse(from, to)
+		} else {
+			iter, err = dbInst.GetBlocksInRange(from, to)
+		}
+		s.Require().NoError(err)
+		heights, err := collectHeights(iter)
+		s.Require().NoError(err)
+		s.Equal(expected, heights)
+	}
+	check(false, 2, 6, []uint64{2, 3, 5})
+	check(true, 2, 6, []uint64{5, 3, 2})
+	check(false, 0, 2, []uint64{1})
+	check(true, 4, 5, nil)
+	check(false, 3, 3, nil)
+	check(false, 0, math.MaxUint64, []uint64{1, 2, 3, 5})
+
+	// The index should be persisted.
+	s.Require().NoError(dbInst.Close())
+	dbInst, err = NewLevelDBBackedDB(dbName)
+	s.Require().NoError(err)
+	check(true, 0, 10, []uint64{5, 3, 2, 1})
+}
+
 func (s *LevelDBTestSuite) TestCompactionChainTipInfo() {
 	dbName := fmt.Sprintf("test-db-%v-cc-tip.db", time.Now().UTC())
 	dbInst, err := NewLevelDBBackedDB(dbName)
@@ -18,14 +18,19 @@
 package db
 
 import (
+	"bufio"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 
 	"github.com/dexon-foundation/dexon-consensus/common"
 	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
 	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon/rlp"
 )
 
 type blockSeqIterator struct {
@@ -40,11 +45,126 @@ func (seq *blockSeqIterator) NextBlock() (types.Block, error) {
 	return seq.db.getBlockByIndex(curIdx)
 }
 
-// MemBackedDB is a memory backed DB implementation.
+type blockHashIterator struct {
+	idx    int
+	hashes common.Hashes
+	db     *MemBackedDB
+}
+
+// NextBlock implemenets BlockIterator.NextBlock method.
+func (it *blockHashIterator) NextBlock() (types.Block, error) {
+	if it.idx >= len(it.hashes) {
+		return types.Block{}, ErrIterationFinished
+	}
+	curIdx := it.idx
+	it.idx++
+	return it.db.GetBlock(it.hashes[curIdx])
+}
+
+// memBatch is a Batch applying writes to MemBackedDB as one journal entry.
+type memBatch struct {
+	m      *MemBackedDB
+	writes []types.Block
+	blocks map[common.Hash]struct{}
+	tip    *compactionChainTipInfo
+	closed bool
+}
+
+func (b *memBatch) hasBlock(hash common.Hash) bool {
+	if _, exists := b.blocks[hash]; exists {
+		return true
+	}
+	return b.m.HasBlock(hash)
+}
+
+// PutBlock implements Batch.PutBlock method.
+func (b *memBatch) PutBlock(block types.Block) error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	if b.hasBlock(block.Hash) {
+		return ErrBlockExists
+	}
+	b.writes = append(b.writes, block)
+	b.blocks[block.Hash] = struct{}{}
+	return nil
+}
+
+// UpdateBlock implements Batch.UpdateBlock method.
+func (b *memBatch) UpdateBlock(block types.Block) error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	if !b.hasBlock(block.Hash) {
+		return ErrBlockDoesNotExist
+	}
+	b.writes = append(b.writes, block)
+	return nil
+}
+
+// PutCompactionChainTipInfo implements Batch.PutCompactionChainTipInfo
+// method.
+func (b *memBatch) PutCompactionChainTipInfo(
+	blockHash common.Hash, height uint64) error {
+	return b.putTip(blockHash, height, (*compactionChainTipInfo).isNext)
+}
+
+// SeedCompactionChainTip implements Batch.SeedCompactionChainTip method.
+func (b *memBatch) SeedCompactionChainTip(
+	blockHash common.Hash, height uint64) error {
+	return b.putTip(blockHash, height, (*compactionChainTipInfo).canSeed)
+}
+
+func (b *memBatch) putTip(blockHash common.Hash, height uint64,
+	valid func(*compactionChainTipInfo, uint64) bool) error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	current := b.tip
+	if current == nil {
+		hash, height := b.m.GetCompactionChainTipInfo()
+		current = &compactionChainTipInfo{Hash: hash, Height: height}
+	}
+	if !valid(current, height) {
+		return ErrInvalidCompactionChainTipHeight
+	}
+	b.tip = &compactionChainTipInfo{Hash: blockHash, Height: height}
+	return nil
+}
+
+// Commit implements Batch.Commit method.
+func (b *memBatch) Commit() error {
+	if b.closed {
+		return ErrBatchClosed
+	}
+	b.closed = true
+	b.m.writeLock.Lock()
+	defer b.m.writeLock.Unlock()
+	return b.m.write(&memJournalEntry{
+		Blocks:             b.writes,
+		CompactionChainTip: b.tip,
+	})
+}
+
+// Rollback implements Batch.Rollback method.
+func (b *memBatch) Rollback() {
+	b.closed = true
+	b.writes = nil
+}
+
+// memJournalLimit is the count of journal entries to trigger a checkpoint.
+const memJournalLimit = 1024
+
+// MemBackedDB is a memory backed DB implementation. When a file path is
+// given, each write is appended to a journal file next to it before applied,
+// and all state is saved to the file when the journal is long enough or the
+// DB is closed. It's only suitable for small data sets like tests, simulations
+// and devnets.
 type MemBackedDB struct {
 	blocksLock               sync.RWMutex
 	blockHashSequence        common.Hashes
 	blocksByHash             map[common.Hash]*types.Block
+	blocksByHeight           map[uint64]common.Hash
 	compactionChainTipLock   sync.RWMutex
 	compactionChainTipHash   common.Hash
 	compactionChainTipHeight uint64
@@ -53,6 +173,12 @@ type MemBackedDB struct {
 	dkgProtocolLock          sync.RWMutex
 	dkgProtocolInfo          *DKGProtocolInfo
 	persistantFilePath       string
+	// writeLock serializes writes, and guards fields below.
+	writeLock     sync.Mutex
+	journal       *os.File
+	journalOffset int64
+	journalSize   int
+	journalLimit  int
 }
 
 // NewMemBackedDB initialize a memory-backed database.
@@ -61,39 +187,323 @@ func NewMemBackedDB(persistantFilePath ...string) (
 	dbInst = &MemBackedDB{
 		blockHashSequence: common.Hashes{},
 		blocksByHash:      make(map[common.Hash]*types.Block),
+		blocksByHeight:    make(map[uint64]common.Hash),
 		dkgPrivateKeys:    make(map[uint64]*dkgPrivateKey),
 	}
 	if len(persistantFilePath) == 0 || len(persistantFilePath[0]) == 0 {
 		return
 	}
 	dbInst.persistantFilePath = persistantFilePath[0]
+	dbInst.journalLimit = memJournalLimit
 	buf, err := ioutil.ReadFile(dbInst.persistantFilePath)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			// Something unexpected happened.
-			return
-		}
+	if err == nil {
+		err = dbInst.load(buf)
+	} else if os.IsNotExist(err) {
 		// It's expected behavior that file doesn't exists, we should not
 		// report error on it.
 		err = nil
-		return
 	}
-
-	// Init this instance by file content, it's a temporary way
-	// to export those private field for JSON encoding.
-	toLoad := struct {
-		Sequence common.Hashes
-		ByHash   map[common.Hash]*types.Block
-	}{}
-	err = json.Unmarshal(buf, &toLoad)
 	if err != nil {
 		return
 	}
-	dbInst.blockHashSequence = toLoad.Sequence
-	dbInst.blocksByHash = toLoad.ByHash
+	err = dbInst.loadJournal()
 	return
 }
 
+// memBackedDBFileVersion is the version of file format persisted by
+// MemBackedDB. Files from version 0 have no version field, and contain only
+// blocks.
+const memBackedDBFileVersion = 1
+
+// memBackedDBFile is the content of the file persisted by MemBackedDB. DKG
+// related fields are encoded in RLP, the same as LevelDBBackedDB.
+type memBackedDBFile struct {
+	Version            uint64
+	Sequence           common.Hashes
+	ByHash             map[common.Hash]*types.Block
+	CompactionChainTip compactionChainTipInfo
+	DKGPrivateKeys     map[uint64][]byte
+	DKGProtocolInfo    []byte
+}
+
+// memJournalEntry is a write appended to the journal of MemBackedDB, fields
+// are encoded the same as memBackedDBFile.
+type memJournalEntry struct {
+	Blocks             []types.Block           `json:",omitempty"`
+	CompactionChainTip *compactionChainTipInfo `json:",omitempty"`
+	DKGPrivateKeys     map[uint64][]byte       `json:",omitempty"`
+	DKGProtocolInfo    []byte                  `json:",omitempty"`
+}
+
+func (m *MemBackedDB) load(buf []byte) (err error) {
+	toLoad := memBackedDBFile{}
+	if err = json.Unmarshal(buf, &toLoad); err != nil {
+		return
+	}
+	if toLoad.Version > memBackedDBFileVersion {
+		err = ErrUnknownSchemaVersion
+		return
+	}
+	entry := &memJournalEntry{
+		Blocks:             make([]types.Block, 0, len(toLoad.Sequence)),
+		CompactionChainTip: &toLoad.CompactionChainTip,
+		DKGPrivateKeys:     toLoad.DKGPrivateKeys,
+		DKGProtocolInfo:    toLoad.DKGProtocolInfo,
+	}
+	for _, hash := range toLoad.Sequence {
+		block, exists := toLoad.ByHash[hash]
+		if !exists {
+			err = ErrBlockDoesNotExist
+			return
+		}
+		entry.Blocks = append(entry.Blocks, *block)
+	}
+	return m.apply(entry)
+}
+
+func (m *MemBackedDB) journalPath() string {
+	return m.persistantFilePath + ".journal"
+}
+
+// loadJournal replays entries in the journal. The last entry is dropped if
+// it's not completely written.
+func (m *MemBackedDB) loadJournal() error {
+	f, err := os.Open(m.journalPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				return os.Truncate(m.journalPath(), m.journalOffset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		entry := &memJournalEntry{}
+		if err = json.Unmarshal(line, entry); err != nil {
+			return err
+		}
+		if err = m.apply(entry); err != nil {
+			return err
+		}
+		m.journalOffset += int64(len(line))
+		m.journalSize++
+	}
+}
+
+// apply applies an entry to the memory, it's idempotent so entries already
+// saved in the file could be replayed again.
+func (m *MemBackedDB) apply(entry *memJournalEntry) error {
+	// Decode all fields before touching anything.
+	prvKeys := make(map[uint64]*dkgPrivateKey, len(entry.DKGPrivateKeys))
+	for round, marshaled := range entry.DKGPrivateKeys {
+		prv := &dkgPrivateKey{}
+		if err := rlp.DecodeBytes(marshaled, prv); err != nil {
+			return err
+		}
+		prvKeys[round] = prv
+	}
+	var info *DKGProtocolInfo
+	if len(entry.DKGProtocolInfo) > 0 {
+		info = &DKGProtocolInfo{}
+		if err := rlp.DecodeBytes(entry.DKGProtocolInfo, info); err != nil {
+			return err
+		}
+	}
+	func() {
+		m.blocksLock.Lock()
+		defer m.blocksLock.Unlock()
+		for i := range entry.Blocks {
+			block := entry.Blocks[i]
+			if _, exists := m.blocksByHash[block.Hash]; !exists {
+				m.blockHashSequence = append(m.blockHashSequence, block.Hash)
+			}
+			m.blocksByHash[block.Hash] = &block
+			m.blocksByHeight[block.Position.Height] = block.Hash
+		}
+	}()
+	if entry.CompactionChainTip != nil {
+		m.compactionChainTipLock.Lock()
+		m.compactionChainTipHash = entry.CompactionChainTip.Hash
+		m.compactionChainTipHeight = entry.CompactionChainTip.Height
+		m.compactionChainTipLock.Unlock()
+	}
+	if len(prvKeys) > 0 {
+		m.dkgPrivateKeysLock.Lock()
+		for round, prv := range prvKeys {
+			m.dkgPrivateKeys[round] = prv
+		}
+		m.dkgPrivateKeysLock.Unlock()
+	}
+	if info != nil {
+		m.dkgProtocolLock.Lock()
+		m.dkgProtocolInfo = info
+		m.dkgProtocolLock.Unlock()
+	}
+	return nil
+}
+
+// write appends an entry to the journal before applying it, so the memory is
+// untouched when it fails to be saved. It should be called with writeLock
+// held.
+func (m *MemBackedDB) write(entry *memJournalEntry) error {
+	if len(m.persistantFilePath) == 0 {
+		return m.apply(entry)
+	}
+	if err := m.appendJournal(entry); err != nil {
+		return err
+	}
+	if err := m.apply(entry); err != nil {
+		return err
+	}
+	if m.journalSize >= m.journalLimit {
+		// The entry is already saved in the journal, failed checkpoints
+		// would be retried by following writes and Close.
+		m.checkpoint()
+	}
+	return nil
+}
+
+func (m *MemBackedDB) appendJournal(entry *memJournalEntry) error {
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if m.journal == nil {
+		f, err := os.OpenFile(m.journalPath(),
+			os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		// Make sure the journal won't disappear after a crash.
+		if err = syncDir(filepath.Dir(m.journalPath())); err != nil {
+			f.Close()
+			return err
+		}
+		m.journal = f
+	}
+	if _, err = m.journal.Write(append(buf, '\n')); err == nil {
+		err = m.journal.Sync()
+	}
+	if err != nil {
+		// Drop the partially written entry.
+		m.journal.Truncate(m.journalOffset)
+		return err
+	}
+	m.journalOffset += int64(len(buf) + 1)
+	m.journalSize++
+	return nil
+}
+
+// checkpoint saves all state to the file and empties the journal. It should
+// be called with writeLock held.
+func (m *MemBackedDB) checkpoint() error {
+	if err := m.save(); err != nil {
+		return err
+	}
+	if m.journal != nil {
+		if err := m.journal.Truncate(0); err != nil {
+			return err
+		}
+		if err := m.journal.Sync(); err != nil {
+			return err
+		}
+	}
+	m.journalOffset = 0
+	m.journalSize = 0
+	return nil
+}
+
+func (m *MemBackedDB) save() error {
+	m.blocksLock.RLock()
+	defer m.blocksLock.RUnlock()
+	m.compactionChainTipLock.RLock()
+	defer m.compactionChainTipLock.RUnlock()
+	m.dkgPrivateKeysLock.RLock()
+	defer m.dkgPrivateKeysLock.RUnlock()
+	m.dkgProtocolLock.RLock()
+	defer m.dkgProtocolLock.RUnlock()
+
+	toDump := memBackedDBFile{
+		Version:  memBackedDBFileVersion,
+		Sequence: m.blockHashSequence,
+		ByHash:   m.blocksByHash,
+		CompactionChainTip: compactionChainTipInfo{
+			Hash:   m.compactionChainTipHash,
+			Height: m.compactionChainTipHeight,
+		},
+		DKGPrivateKeys: make(map[uint64][]byte),
+	}
+	for round, prv := range m.dkgPrivateKeys {
+		marshaled, err := rlp.EncodeToBytes(prv)
+		if err != nil {
+			return err
+		}
+		toDump.DKGPrivateKeys[round] = marshaled
+	}
+	if m.dkgProtocolInfo != nil {
+		marshaled, err := rlp.EncodeToBytes(m.dkgProtocolInfo)
+		if err != nil {
+			return err
+		}
+		toDump.DKGProtocolInfo = marshaled
+	}
+	buf, err := json.Marshal(&toDump)
+	if err != nil {
+		return err
+	}
+	// The file contains DKG private keys, only the owner could read it.
+	return writeFileAtomic(m.persistantFilePath, buf, 0600)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to path, so
+// the file at path is either the old one or the new one after a crash. The
+// parent directory is synced to make the rename durable.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err = f.Chmod(perm); err == nil {
+		if _, err = f.Write(data); err == nil {
+			err = f.Sync()
+		}
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = dir.Sync()
+	if errClose := dir.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
 // HasBlock returns wheter or not the DB has a block identified with the hash.
 func (m *MemBackedDB) HasBlock(hash common.Hash) bool {
 	m.blocksLock.RLock()
@@ -121,42 +531,102 @@ func (m *MemBackedDB) internalGetBlock(hash common.Hash) (types.Block, error) {
 
 // PutBlock inserts a new block into the database.
 func (m *MemBackedDB) PutBlock(block types.Block) error {
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
 	if m.HasBlock(block.Hash) {
 		return ErrBlockExists
 	}
-
-	m.blocksLock.Lock()
-	defer m.blocksLock.Unlock()
-
-	m.blockHashSequence = append(m.blockHashSequence, block.Hash)
-	m.blocksByHash[block.Hash] = &block
-	return nil
+	return m.write(&memJournalEntry{Blocks: []types.Block{block}})
 }
 
 // UpdateBlock updates a block in the database.
 func (m *MemBackedDB) UpdateBlock(block types.Block) error {
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
 	if !m.HasBlock(block.Hash) {
 		return ErrBlockDoesNotExist
 	}
+	return m.write(&memJournalEntry{Blocks: []types.Block{block}})
+}
 
-	m.blocksLock.Lock()
-	defer m.blocksLock.Unlock()
+// NewBatch creates a Batch writing to the database atomically.
+func (m *MemBackedDB) NewBatch() Batch {
+	return &memBatch{
+		m:      m,
+		blocks: make(map[common.Hash]struct{}),
+	}
+}
 
-	m.blocksByHash[block.Hash] = &block
-	return nil
+// GetBlockByHeight returns the block put at a height.
+func (m *MemBackedDB) GetBlockByHeight(height uint64) (types.Block, error) {
+	m.blocksLock.RLock()
+	defer m.blocksLock.RUnlock()
+
+	hash, ok := m.blocksByHeight[height]
+	if !ok {
+		return types.Block{}, ErrBlockDoesNotExist
+	}
+	return m.internalGetBlock(hash)
+}
+
+// GetBlocksInRange implements Reader.GetBlocksInRange method.
+func (m *MemBackedDB) GetBlocksInRange(from, to uint64) (
+	BlockIterator, error) {
+	return &blockHashIterator{
+		hashes: m.getBlockHashesInRange(from, to, false),
+		db:     m,
+	}, nil
+}
+
+// GetBlocksInRangeReverse implements Reader.GetBlocksInRangeReverse method.
+func (m *MemBackedDB) GetBlocksInRangeReverse(from, to uint64) (
+	BlockIterator, error) {
+	return &blockHashIterator{
+		hashes: m.getBlockHashesInRange(from, to, true),
+		db:     m,
+	}, nil
+}
+
+func (m *MemBackedDB) getBlockHashesInRange(
+	from, to uint64, reverse bool) common.Hashes {
+	m.blocksLock.RLock()
+	defer m.blocksLock.RUnlock()
+
+	heights := []uint64{}
+	for h := range m.blocksByHeight {
+		if h >= from && h < to {
+			heights = append(heights, h)
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool {
+		if reverse {
+			return heights[i] > heights[j]
+		}
+		return heights[i] < heights[j]
+	})
+	hashes := make(common.Hashes, 0, len(heights))
+	for _, h := range heights {
+		hashes = append(hashes, m.blocksByHeight[h])
+	}
+	return hashes
 }
 
 // PutCompactionChainTipInfo saves tip of compaction chain into the database.
 func (m *MemBackedDB) PutCompactionChainTipInfo(
 	blockHash common.Hash, height uint64) error {
-	m.compactionChainTipLock.Lock()
-	defer m.compactionChainTipLock.Unlock()
-	if m.compactionChainTipHeight+1 != height {
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
+	current := compactionChainTipInfo{}
+	current.Hash, current.Height = m.GetCompactionChainTipInfo()
+	if !current.isNext(height) {
 		return ErrInvalidCompactionChainTipHeight
 	}
-	m.compactionChainTipHeight = height
-	m.compactionChainTipHash = blockHash
-	return nil
+	return m.write(&memJournalEntry{
+		CompactionChainTip: &compactionChainTipInfo{
+			Hash:   blockHash,
+			Height: height,
+		},
+	})
 }
 
 // GetCompactionChainTipInfo get the tip info of compaction chain into the
@@ -182,16 +652,21 @@ func (m *MemBackedDB) GetDKGPrivateKey(round, reset uint64) (
 // PutDKGPrivateKey save DKG private key of one round.
 func (m *MemBackedDB) PutDKGPrivateKey(
 	round, reset uint64, prv dkg.PrivateKey) error {
-	m.dkgPrivateKeysLock.Lock()
-	defer m.dkgPrivateKeysLock.Unlock()
-	if prv, exists := m.dkgPrivateKeys[round]; exists && prv.Reset == reset {
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
+	if _, err := m.GetDKGPrivateKey(round, reset); err == nil {
 		return ErrDKGPrivateKeyExists
 	}
-	m.dkgPrivateKeys[round] = &dkgPrivateKey{
+	marshaled, err := rlp.EncodeToBytes(&dkgPrivateKey{
 		PK:    prv,
 		Reset: reset,
+	})
+	if err != nil {
+		return err
 	}
-	return nil
+	return m.write(&memJournalEntry{
+		DKGPrivateKeys: map[uint64][]byte{round: marshaled},
+	})
 }
 
 // GetDKGProtocol get DKG protocol.
@@ -208,38 +683,35 @@ func (m *MemBackedDB) GetDKGProtocol() (
 
 // PutOrUpdateDKGProtocol save DKG protocol.
 func (m *MemBackedDB) PutOrUpdateDKGProtocol(dkgProtocol DKGProtocolInfo) error {
-	m.dkgProtocolLock.Lock()
-	defer m.dkgProtocolLock.Unlock()
-	m.dkgProtocolInfo = &dkgProtocol
-	return nil
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
+	marshaled, err := rlp.EncodeToBytes(&dkgProtocol)
+	if err != nil {
+		return err
+	}
+	return m.write(&memJournalEntry{DKGProtocolInfo: marshaled})
 }
 
 // Close implement Closer interface, which would release allocated resource.
+// All state is saved to the file and the journal is removed.
 func (m *MemBackedDB) Close() (err error) {
-	// Save internal state to a pretty-print json file. It's a temporary way
-	// to dump private file via JSON encoding.
 	if len(m.persistantFilePath) == 0 {
 		return
 	}
-
-	m.blocksLock.RLock()
-	defer m.blocksLock.RUnlock()
-
-	toDump := struct {
-		Sequence common.Hashes
-		ByHash   map[common.Hash]*types.Block
-	}{
-		Sequence: m.blockHashSequence,
-		ByHash:   m.blocksByHash,
-	}
-
-	// Dump to JSON with 2-space indent.
-	buf, err := json.Marshal(&toDump)
-	if err != nil {
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
+	if err = m.checkpoint(); err != nil {
 		return
 	}
-
-	err = ioutil.WriteFile(m.persistantFilePath, buf, 0644)
+	if m.journal != nil {
+		if err = m.journal.Close(); err != nil {
+			return
+		}
+		m.journal = nil
+	}
+	if err = os.Remove(m.journalPath()); os.IsNotExist(err) {
+		err = nil
+	}
 	return
 }
 
@@ -19,7 +19,10 @@ package db
 
 import (
 	"bytes"
+	"encoding/json"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dexon-foundation/dexon-consensus/common"
@@ -99,6 +102,229 @@ func (s *MemBackedDBTestSuite) TestSaveAndLoad() {
 	s.NoError(dbInst.Close())
 }
 
+func (s *MemBackedDBTestSuite) TestSaveAndLoadAll() {
+	dir, err := ioutil.TempDir("", "dexcon-mem-db")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "test.db")
+
+	dbInst, err := NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	for _, b := range []*types.Block{s.b00, s.b01, s.b02} {
+		s.Require().NoError(dbInst.PutBlock(*b))
+	}
+	s.Require().NoError(dbInst.PutCompactionChainTipInfo(s.b01.Hash, 1))
+	prv1, prv2 := dkg.NewPrivateKey(), dkg.NewPrivateKey()
+	s.Require().NoError(dbInst.PutDKGPrivateKey(1, 0, *prv1))
+	s.Require().NoError(dbInst.PutDKGPrivateKey(2, 3, *prv2))
+	info := DKGProtocolInfo{
+		ID:                        s.v0,
+		Round:                     2,
+		Threshold:                 3,
+		IsMasterPrivateShareEmpty: true,
+		IsPrvSharesEmpty:          true,
+		Reset:                     3,
+	}
+	s.Require().NoError(dbInst.PutOrUpdateDKGProtocol(info))
+	s.Require().NoError(dbInst.Close())
+
+	// The file should be written atomically and readable only by owner.
+	files, err := ioutil.ReadDir(dir)
+	s.Require().NoError(err)
+	s.Require().Len(files, 1)
+	s.Equal(os.FileMode(0600), files[0].Mode().Perm())
+
+	dbInst, err = NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	b, err := dbInst.GetBlockByHeight(2)
+	s.Require().NoError(err)
+	s.Equal(s.b02.Hash, b.Hash)
+	hash, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(s.b01.Hash, hash)
+	s.Equal(uint64(1), height)
+	queried, err := dbInst.GetDKGPrivateKey(1, 0)
+	s.Require().NoError(err)
+	s.Equal(prv1.Bytes(), queried.Bytes())
+	queried, err = dbInst.GetDKGPrivateKey(2, 3)
+	s.Require().NoError(err)
+	s.Equal(prv2.Bytes(), queried.Bytes())
+	queriedInfo, err := dbInst.GetDKGProtocol()
+	s.Require().NoError(err)
+	s.True(info.Equal(&queriedInfo))
+	// Keep writing after loaded.
+	s.Require().NoError(dbInst.PutCompactionChainTipInfo(s.b02.Hash, 2))
+	s.Require().NoError(dbInst.Close())
+}
+
+func (s *MemBackedDBTestSuite) TestSaveOnWrite() {
+	dir, err := ioutil.TempDir("", "dexcon-mem-db")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "test.db")
+
+	dbInst, err := NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	defer dbInst.Close()
+	// Writes are saved without closing the DB.
+	load := func() *MemBackedDB {
+		loaded, err := NewMemBackedDB(dbPath)
+		s.Require().NoError(err)
+		return loaded
+	}
+	s.Require().NoError(dbInst.PutBlock(*s.b00))
+	s.True(load().HasBlock(s.b00.Hash))
+	batch := dbInst.NewBatch()
+	s.Require().NoError(batch.PutBlock(*s.b01))
+	s.Require().NoError(batch.PutCompactionChainTipInfo(s.b01.Hash, 1))
+	s.Require().NoError(batch.Commit())
+	loaded := load()
+	s.True(loaded.HasBlock(s.b01.Hash))
+	hash, height := loaded.GetCompactionChainTipInfo()
+	s.Equal(s.b01.Hash, hash)
+	s.Equal(uint64(1), height)
+	prv := dkg.NewPrivateKey()
+	s.Require().NoError(dbInst.PutDKGPrivateKey(1, 0, *prv))
+	queried, err := load().GetDKGPrivateKey(1, 0)
+	s.Require().NoError(err)
+	s.Equal(prv.Bytes(), queried.Bytes())
+	s.Require().NoError(dbInst.PutOrUpdateDKGProtocol(DKGProtocolInfo{
+		ID:                        s.v0,
+		Round:                     1,
+		IsMasterPrivateShareEmpty: true,
+		IsPrvSharesEmpty:          true,
+	}))
+	info, err := load().GetDKGProtocol()
+	s.Require().NoError(err)
+	s.Equal(uint64(1), info.Round)
+	// Only the journal is written before a checkpoint.
+	files, err := ioutil.ReadDir(dir)
+	s.Require().NoError(err)
+	s.Require().Len(files, 1)
+	s.Equal("test.db.journal", files[0].Name())
+}
+
+func (s *MemBackedDBTestSuite) TestCheckpoint() {
+	dir, err := ioutil.TempDir("", "dexcon-mem-db")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "test.db")
+
+	dbInst, err := NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	dbInst.journalLimit = 2
+	s.Require().NoError(dbInst.PutBlock(*s.b00))
+	_, err = os.Stat(dbPath)
+	s.True(os.IsNotExist(err))
+	// The second write triggers a checkpoint and empties the journal.
+	s.Require().NoError(dbInst.PutBlock(*s.b01))
+	info, err := os.Stat(dbPath + ".journal")
+	s.Require().NoError(err)
+	s.Equal(int64(0), info.Size())
+	s.Require().NoError(dbInst.PutBlock(*s.b02))
+	loaded, err := NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	s.True(loaded.HasBlock(s.b00.Hash))
+	s.True(loaded.HasBlock(s.b01.Hash))
+	s.True(loaded.HasBlock(s.b02.Hash))
+	// The journal is removed when closed.
+	s.Require().NoError(dbInst.Close())
+	_, err = os.Stat(dbPath + ".journal")
+	s.True(os.IsNotExist(err))
+	loaded, err = NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	s.True(loaded.HasBlock(s.b02.Hash))
+}
+
+func (s *MemBackedDBTestSuite) TestTornJournal() {
+	dir, err := ioutil.TempDir("", "dexcon-mem-db")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "test.db")
+
+	dbInst, err := NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	s.Require().NoError(dbInst.PutBlock(*s.b00))
+	info, err := os.Stat(dbPath + ".journal")
+	s.Require().NoError(err)
+	// Simulate a crash in the middle of appending an entry.
+	f, err := os.OpenFile(dbPath+".journal", os.O_WRONLY|os.O_APPEND, 0600)
+	s.Require().NoError(err)
+	_, err = f.Write([]byte(`{"Blocks":[{"Has`))
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+	loaded, err := NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	s.True(loaded.HasBlock(s.b00.Hash))
+	// The torn entry is dropped.
+	truncated, err := os.Stat(dbPath + ".journal")
+	s.Require().NoError(err)
+	s.Equal(info.Size(), truncated.Size())
+	s.Require().NoError(loaded.PutBlock(*s.b01))
+	s.Require().NoError(loaded.Close())
+	loaded, err = NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	s.True(loaded.HasBlock(s.b00.Hash))
+	s.True(loaded.HasBlock(s.b01.Hash))
+}
+
+func (s *MemBackedDBTestSuite) TestWriteFailure() {
+	dir, err := ioutil.TempDir("", "dexcon-mem-db")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "test.db")
+
+	dbInst, err := NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	s.Require().NoError(dbInst.PutBlock(*s.b00))
+	// Writes failed to be saved should not be applied.
+	s.Require().NoError(dbInst.journal.Close())
+	s.Require().Error(dbInst.PutBlock(*s.b01))
+	s.False(dbInst.HasBlock(s.b01.Hash))
+	s.Require().Error(dbInst.PutCompactionChainTipInfo(s.b00.Hash, 1))
+	hash, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(common.Hash{}, hash)
+	s.Equal(uint64(0), height)
+	s.Require().Error(dbInst.PutDKGPrivateKey(1, 0, *dkg.NewPrivateKey()))
+	_, err = dbInst.GetDKGPrivateKey(1, 0)
+	s.Equal(ErrDKGPrivateKeyDoesNotExist, err)
+}
+
+func (s *MemBackedDBTestSuite) TestLoadFileVersions() {
+	dir, err := ioutil.TempDir("", "dexcon-mem-db")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "test.db")
+
+	// Files from version 0 contain only blocks.
+	buf, err := json.Marshal(map[string]interface{}{
+		"Sequence": common.Hashes{s.b00.Hash, s.b01.Hash},
+		"ByHash": map[common.Hash]*types.Block{
+			s.b00.Hash: s.b00,
+			s.b01.Hash: s.b01,
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(ioutil.WriteFile(dbPath, buf, 0644))
+	dbInst, err := NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	b, err := dbInst.GetBlockByHeight(1)
+	s.Require().NoError(err)
+	s.Equal(s.b01.Hash, b.Hash)
+	_, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(uint64(0), height)
+	_, err = dbInst.GetDKGProtocol()
+	s.Equal(ErrDKGProtocolDoesNotExist, err)
+
+	// Files from newer versions are refused.
+	buf, err = json.Marshal(map[string]interface{}{
+		"Version": memBackedDBFileVersion + 1,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(ioutil.WriteFile(dbPath, buf, 0644))
+	_, err = NewMemBackedDB(dbPath)
+	s.Equal(ErrUnknownSchemaVersion, err)
+}
+
 func (s *MemBackedDBTestSuite) TestIteration() {
 	// Make sure the file pointed by 'dbPath' doesn't exist.
 	dbInst, err := NewMemBackedDB()
@@ -128,6 +354,43 @@ func (s *MemBackedDBTestSuite) TestIteration() {
 	s.Contains(touched, s.b02.Hash)
 }
 
+func (s *MemBackedDBTestSuite) TestBlocksByHeight() {
+	dbPath := "test-blocks-by-height.db"
+	dbInst, err := NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	defer os.Remove(dbPath)
+
+	s.NoError(dbInst.PutBlock(*s.b02))
+	s.NoError(dbInst.PutBlock(*s.b00))
+	s.NoError(dbInst.PutBlock(*s.b01))
+	b, err := dbInst.GetBlockByHeight(1)
+	s.Require().NoError(err)
+	s.Equal(s.b01.Hash, b.Hash)
+	_, err = dbInst.GetBlockByHeight(3)
+	s.Equal(ErrBlockDoesNotExist, err)
+
+	iter, err := dbInst.GetBlocksInRange(1, 10)
+	s.Require().NoError(err)
+	heights, err := collectHeights(iter)
+	s.Require().NoError(err)
+	s.Equal([]uint64{1, 2}, heights)
+	iter, err = dbInst.GetBlocksInRangeReverse(0, 2)
+	s.Require().NoError(err)
+	heights, err = collectHeights(iter)
+	s.Require().NoError(err)
+	s.Equal([]uint64{1, 0}, heights)
+
+	// The index should be rebuilt after loaded from file.
+	s.NoError(dbInst.Close())
+	dbInst, err = NewMemBackedDB(dbPath)
+	s.Require().NoError(err)
+	iter, err = dbInst.GetBlocksInRangeReverse(0, 10)
+	s.Require().NoError(err)
+	heights, err = collectHeights(iter)
+	s.Require().NoError(err)
+	s.Equal([]uint64{2, 1, 0}, heights)
+}
+
 func (s *MemBackedDBTestSuite) TestCompactionChainTipInfo() {
 	dbInst, err := NewMemBackedDB()
 	s.Require().NoError(err)
@@ -0,0 +1,263 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+var prunedHeightKey = []byte("pruned-height")
+
+// pruneBatchSize is the count of blocks removed in one leveldb batch.
+const pruneBatchSize = 1024
+
+// RetentionPolicy decides which data could be removed from DB.
+//
+// The block at the tip of compaction chain is always retained. Blocks not yet
+// persisted by the application layer are only retained via KeepAbove, the
+// syncer would be unable to replay them after restarted if they are pruned.
+type RetentionPolicy struct {
+	// KeepRounds retains blocks in the latest KeepRounds rounds, including
+	// the round of compaction chain tip. Zero means no limit by round.
+	KeepRounds uint64
+	// KeepHeight retains blocks with height not less than KeepHeight. Zero
+	// means no limit by height.
+	KeepHeight uint64
+	// KeepAbove returns a height, blocks higher than it are always retained.
+	// It's called on each pruning, so the application layer could return the
+	// height of the latest block it persisted. Nil means no such limit.
+	KeepAbove func() uint64
+	// PruneDKGPrivateKeys removes DKG private keys of rounds before the
+	// previous round of compaction chain tip. The previous round is kept
+	// because the tip might just enter a new round.
+	PruneDKGPrivateKeys bool
+}
+
+// prunable checks if a block at pos could be removed when the tip of
+// compaction chain is at tip. A block is retained if any limit retains it.
+func (p RetentionPolicy) prunable(pos, tip types.Position) bool {
+	if pos.Height >= tip.Height {
+		return false
+	}
+	if p.KeepRounds == 0 && p.KeepHeight == 0 {
+		return false
+	}
+	if p.KeepRounds > 0 && pos.Round+p.KeepRounds > tip.Round {
+		return false
+	}
+	if p.KeepHeight > 0 && pos.Height >= p.KeepHeight {
+		return false
+	}
+	return true
+}
+
+// StartPruning starts a routine to prune DB by policy periodically, it's
+// stopped when DB is closed. It's safe to prune while Consensus is running.
+func (lvl *LevelDBBackedDB) StartPruning(policy RetentionPolicy,
+	interval time.Duration, logger common.Logger) error {
+	lvl.pruneLock.Lock()
+	defer lvl.pruneLock.Unlock()
+	if lvl.pruneStop != nil {
+		return ErrPruningStarted
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	lvl.pruneStop, lvl.pruneDone = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			if err := lvl.prune(policy, stop); err != nil {
+				logger.Error("Failed to prune db", "error", err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (lvl *LevelDBBackedDB) stopPruning() {
+	lvl.pruneLock.Lock()
+	stop, done := lvl.pruneStop, lvl.pruneDone
+	lvl.pruneLock.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+	lvl.pruneLock.Lock()
+	defer lvl.pruneLock.Unlock()
+	lvl.pruneStop, lvl.pruneDone = nil, nil
+}
+
+// Prune removes blocks and DKG private keys not retained by policy.
+func (lvl *LevelDBBackedDB) Prune(policy RetentionPolicy) error {
+	return lvl.prune(policy, nil)
+}
+
+func (lvl *LevelDBBackedDB) prune(
+	policy RetentionPolicy, stop <-chan struct{}) error {
+	lvl.pruneLock.Lock()
+	defer lvl.pruneLock.Unlock()
+	tipInfo, err := lvl.internalGetCompactionChainTipInfo()
+	if err != nil {
+		return err
+	}
+	if tipInfo.Height == 0 {
+		return nil
+	}
+	tip, err := lvl.GetBlock(tipInfo.Hash)
+	if err != nil {
+		return err
+	}
+	blocksPruned, err := lvl.pruneBlocks(policy, tip.Position, stop)
+	if err != nil {
+		return err
+	}
+	keysPruned := false
+	if policy.PruneDKGPrivateKeys {
+		if keysPruned, err = lvl.pruneDKGPrivateKeys(
+			tip.Position.Round); err != nil {
+			return err
+		}
+	}
+	// Deleted entries are dropped from disk only after compaction.
+	if blocksPruned {
+		if err = lvl.db.CompactRange(
+			*util.BytesPrefix(blockKeyPrefix)); err != nil {
+			return err
+		}
+		if err = lvl.db.CompactRange(
+			*util.BytesPrefix(blockHeightKeyPrefix)); err != nil {
+			return err
+		}
+	}
+	if keysPruned {
+		err = lvl.db.CompactRange(*util.BytesPrefix(dkgPrivateKeyKeyPrefix))
+	}
+	return err
+}
+
+func (lvl *LevelDBBackedDB) pruneBlocks(policy RetentionPolicy,
+	tip types.Position, stop <-chan struct{}) (pruned bool, err error) {
+	limit := tip.Height
+	if policy.KeepAbove != nil {
+		if above := policy.KeepAbove(); above < limit {
+			limit = above + 1
+		}
+	}
+	from, err := lvl.getPrunedHeight()
+	if err != nil || from >= limit {
+		return
+	}
+	iter := lvl.db.NewIterator(&util.Range{
+		Start: lvl.getBlockHeightKey(from),
+		Limit: lvl.getBlockHeightKey(limit),
+	}, nil)
+	defer iter.Release()
+	batch := new(leveldb.Batch)
+	write := func(next uint64) error {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, next)
+		batch.Put(prunedHeightKey, b)
+		if err := lvl.db.Write(batch, nil); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+	for iter.Next() {
+		height := binary.BigEndian.Uint64(
+			iter.Key()[len(blockHeightKeyPrefix):])
+		hash := common.Hash{}
+		copy(hash[:], iter.Value())
+		// The index without block is removed as well.
+		block, errGet := lvl.GetBlock(hash)
+		if errGet == nil && !policy.prunable(block.Position, tip) {
+			break
+		}
+		if errGet != nil && errGet != ErrBlockDoesNotExist {
+			err = errGet
+			return
+		}
+		batch.Delete(lvl.getBlockKey(hash))
+		batch.Delete(lvl.getBlockHeightKey(height))
+		pruned = true
+		if batch.Len() >= 2*pruneBatchSize {
+			if err = write(height + 1); err != nil {
+				return
+			}
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+		from = height + 1
+	}
+	if err = iter.Error(); err != nil {
+		return
+	}
+	if batch.Len() > 0 {
+		err = write(from)
+	}
+	return
+}
+
+func (lvl *LevelDBBackedDB) pruneDKGPrivateKeys(
+	tipRound uint64) (pruned bool, err error) {
+	iter := lvl.db.NewIterator(util.BytesPrefix(dkgPrivateKeyKeyPrefix), nil)
+	defer iter.Release()
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		round := binary.LittleEndian.Uint64(
+			iter.Key()[len(dkgPrivateKeyKeyPrefix):])
+		if round+1 < tipRound {
+			batch.Delete(lvl.getDKGPrivateKeyKey(round))
+		}
+	}
+	if err = iter.Error(); err != nil || batch.Len() == 0 {
+		return
+	}
+	pruned = true
+	err = lvl.db.Write(batch, nil)
+	return
+}
+
+// getPrunedHeight returns the lowest height not yet pruned.
+func (lvl *LevelDBBackedDB) getPrunedHeight() (uint64, error) {
+	queried, err := lvl.db.Get(prunedHeightKey, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			err = nil
+		}
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(queried), nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+type PruningTestSuite struct {
+	suite.Suite
+}
+
+// newTestDB creates a LevelDBBackedDB with blocks from height 1 to tip, and
+// there are 10 blocks in each round.
+func (s *PruningTestSuite) newTestDB(tip uint64) (
+	dbInst *LevelDBBackedDB, cleanup func()) {
+	dbName := fmt.Sprintf("test-db-%v-pruning.db", time.Now().UTC())
+	dbInst, err := NewLevelDBBackedDB(dbName)
+	s.Require().NoError(err)
+	cleanup = func() {
+		s.NoError(dbInst.Close())
+		s.NoError(os.RemoveAll(dbName))
+	}
+	for h := uint64(1); h <= tip; h++ {
+		b := types.Block{
+			Hash:     common.NewRandomHash(),
+			Position: types.Position{Round: h / 10, Height: h},
+		}
+		s.Require().NoError(dbInst.PutBlock(b))
+		s.Require().NoError(dbInst.PutCompactionChainTipInfo(b.Hash, h))
+	}
+	for r := uint64(0); r <= tip/10; r++ {
+		s.Require().NoError(dbInst.PutDKGPrivateKey(r, 0, *dkg.NewPrivateKey()))
+	}
+	return
+}
+
+func (s *PruningTestSuite) lowestHeight(dbInst *LevelDBBackedDB) uint64 {
+	iter, err := dbInst.GetBlocksInRange(0, math.MaxUint64)
+	s.Require().NoError(err)
+	b, err := iter.NextBlock()
+	s.Require().NoError(err)
+	return b.Position.Height
+}
+
+func (s *PruningTestSuite) TestPrunable() {
+	tip := types.Position{Round: 5, Height: 50}
+	s.False(RetentionPolicy{}.prunable(types.Position{Height: 1}, tip))
+	// The tip should never be pruned.
+	s.False(RetentionPolicy{KeepHeight: 100}.prunable(tip, tip))
+	s.True(RetentionPolicy{KeepRounds: 2}.prunable(
+		types.Position{Round: 3, Height: 39}, tip))
+	s.False(RetentionPolicy{KeepRounds: 2}.prunable(
+		types.Position{Round: 4, Height: 40}, tip))
+	s.True(RetentionPolicy{KeepHeight: 30}.prunable(
+		types.Position{Round: 2, Height: 29}, tip))
+	s.False(RetentionPolicy{KeepHeight: 30}.prunable(
+		types.Position{Round: 3, Height: 30}, tip))
+	// Retained if any limit retains it.
+	s.False(RetentionPolicy{KeepRounds: 2, KeepHeight: 30}.prunable(
+		types.Position{Round: 3, Height: 35}, tip))
+	s.False(RetentionPolicy{KeepRounds: 3, KeepHeight: 45}.prunable(
+		types.Position{Round: 3, Height: 35}, tip))
+	s.True(RetentionPolicy{KeepRounds: 2, KeepHeight: 45}.prunable(
+		types.Position{Round: 3, Height: 35}, tip))
+}
+
+func (s *PruningTestSuite) TestPrune() {
+	dbInst, cleanup := s.newTestDB(35)
+	defer cleanup()
+
+	// Nothing is pruned without limits.
+	s.Require().NoError(dbInst.Prune(RetentionPolicy{}))
+	s.Equal(uint64(1), s.lowestHeight(dbInst))
+
+	// Keep round 2 and 3.
+	s.Require().NoError(dbInst.Prune(RetentionPolicy{
+		KeepRounds:          2,
+		PruneDKGPrivateKeys: true,
+	}))
+	s.Equal(uint64(20), s.lowestHeight(dbInst))
+	b, err := dbInst.GetBlockByHeight(19)
+	s.Equal(ErrBlockDoesNotExist, err)
+	s.False(dbInst.HasBlock(b.Hash))
+	for r := uint64(0); r <= 3; r++ {
+		_, err := dbInst.GetDKGPrivateKey(r, 0)
+		if r < 2 {
+			s.Equal(ErrDKGPrivateKeyDoesNotExist, err)
+		} else {
+			s.NoError(err)
+		}
+	}
+
+	// Keep blocks from height 25.
+	s.Require().NoError(dbInst.Prune(RetentionPolicy{KeepHeight: 25}))
+	s.Equal(uint64(25), s.lowestHeight(dbInst))
+
+	// Blocks not persisted by the application layer are retained.
+	persisted := uint64(29)
+	policy := RetentionPolicy{
+		KeepHeight: 100,
+		KeepAbove:  func() uint64 { return persisted },
+	}
+	s.Require().NoError(dbInst.Prune(policy))
+	s.Equal(uint64(30), s.lowestHeight(dbInst))
+	persisted = 32
+	s.Require().NoError(dbInst.Prune(policy))
+	s.Equal(uint64(33), s.lowestHeight(dbInst))
+
+	// The tip is always retained.
+	s.Require().NoError(dbInst.Prune(RetentionPolicy{KeepHeight: 100}))
+	s.Equal(uint64(35), s.lowestHeight(dbInst))
+	hash, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(uint64(35), height)
+	s.True(dbInst.HasBlock(hash))
+}
+
+func (s *PruningTestSuite) TestBackgroundPruning() {
+	dbInst, cleanup := s.newTestDB(2*pruneBatchSize + 50)
+	defer cleanup()
+
+	policy := RetentionPolicy{KeepHeight: 2*pruneBatchSize + 1}
+	s.Require().NoError(
+		dbInst.StartPruning(policy, 10*time.Millisecond, &common.NullLogger{}))
+	s.Equal(ErrPruningStarted,
+		dbInst.StartPruning(policy, time.Second, &common.NullLogger{}))
+	// Keep putting blocks while pruning.
+	for h := uint64(2*pruneBatchSize + 51); h <= 2*pruneBatchSize+100; h++ {
+		b := types.Block{
+			Hash:     common.NewRandomHash(),
+			Position: types.Position{Round: h / 10, Height: h},
+		}
+		s.Require().NoError(dbInst.PutBlock(b))
+		s.Require().NoError(dbInst.PutCompactionChainTipInfo(b.Hash, h))
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for s.lowestHeight(dbInst) != 2*pruneBatchSize+1 &&
+		time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.Require().Equal(uint64(2*pruneBatchSize+1), s.lowestHeight(dbInst))
+	_, height := dbInst.GetCompactionChainTipInfo()
+	s.Equal(uint64(2*pruneBatchSize+100), height)
+}
+
+func TestPruning(t *testing.T) {
+	suite.Run(t, new(PruningTestSuite))
+}
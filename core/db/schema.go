@@ -0,0 +1,121 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"encoding/binary"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+var schemaVersionKey = []byte("schema-version")
+
+// migrations upgrade the schema of LevelDBBackedDB on open, migrations[i]
+// upgrades a DB from version i to i+1. Databases created before the schema
+// version key was introduced are treated as version 0.
+//
+// A migration might be interrupted by a crash and run again on next open, it
+// should be safe to be applied more than once.
+var migrations = []func(lvl *LevelDBBackedDB) error{
+	// Version 1: index blocks by height.
+	migrateBlockHeightIndex,
+}
+
+// schemaVersion is the version of schema written by this package.
+var schemaVersion = uint64(len(migrations))
+
+func (lvl *LevelDBBackedDB) getSchemaVersion() (
+	version uint64, exists bool, err error) {
+	queried, err := lvl.db.Get(schemaVersionKey, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			err = nil
+		}
+		return
+	}
+	version, exists = binary.BigEndian.Uint64(queried), true
+	return
+}
+
+func (lvl *LevelDBBackedDB) putSchemaVersion(version uint64) error {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, version)
+	return lvl.db.Put(schemaVersionKey, b, nil)
+}
+
+// upgradeSchema applies migrations to upgrade DB to the current schema
+// version. Databases from newer versions are refused, this package has no
+// idea about how to read them.
+func (lvl *LevelDBBackedDB) upgradeSchema() error {
+	version, exists, err := lvl.getSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		iter := lvl.db.NewIterator(nil, nil)
+		empty := !iter.First()
+		iter.Release()
+		if err = iter.Error(); err != nil {
+			return err
+		}
+		if empty {
+			// A brand new DB is already in the current schema.
+			return lvl.putSchemaVersion(schemaVersion)
+		}
+	}
+	if version > schemaVersion {
+		return ErrUnknownSchemaVersion
+	}
+	for ; version < schemaVersion; version++ {
+		if err = migrations[version](lvl); err != nil {
+			return err
+		}
+		if err = lvl.putSchemaVersion(version + 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateBlockHeightIndex builds the height index of existing blocks.
+func migrateBlockHeightIndex(lvl *LevelDBBackedDB) error {
+	iter := lvl.db.NewIterator(util.BytesPrefix(blockKeyPrefix), nil)
+	defer iter.Release()
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		var b types.Block
+		if err := rlp.DecodeBytes(iter.Value(), &b); err != nil {
+			return err
+		}
+		batch.Put(lvl.getBlockHeightKey(b.Position.Height), b.Hash[:])
+		if batch.Len() >= 1024 {
+			if err := lvl.db.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return lvl.db.Write(batch, nil)
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SchemaTestSuite struct {
+	suite.Suite
+}
+
+// copyFixture copies a fixture DB in testdata to a temporary directory, the
+// fixture would be modified when opened.
+func (s *SchemaTestSuite) copyFixture(name string) string {
+	dir, err := ioutil.TempDir("", "dexcon-db-fixture")
+	s.Require().NoError(err)
+	src := filepath.Join("testdata", name)
+	files, err := ioutil.ReadDir(src)
+	s.Require().NoError(err)
+	for _, f := range files {
+		buf, err := ioutil.ReadFile(filepath.Join(src, f.Name()))
+		s.Require().NoError(err)
+		s.Require().NoError(
+			ioutil.WriteFile(filepath.Join(dir, f.Name()), buf, 0600))
+	}
+	return dir
+}
+
+func (s *SchemaTestSuite) TestNewDB() {
+	dir, err := ioutil.TempDir("", "dexcon-db")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	dbInst, err := NewLevelDBBackedDB(dir)
+	s.Require().NoError(err)
+	defer dbInst.Close()
+	version, exists, err := dbInst.getSchemaVersion()
+	s.Require().NoError(err)
+	s.True(exists)
+	s.Equal(schemaVersion, version)
+}
+
+// TestMigrateFromVersion0 opens a fixture DB written before the schema
+// version was introduced, which contains blocks at height 1 to 20 in round 0
+// to 2, and the tip of compaction chain at height 20.
+func (s *SchemaTestSuite) TestMigrateFromVersion0() {
+	dir := s.copyFixture("leveldb-v0")
+	defer os.RemoveAll(dir)
+	dbInst, err := NewLevelDBBackedDB(dir)
+	s.Require().NoError(err)
+	version, exists, err := dbInst.getSchemaVersion()
+	s.Require().NoError(err)
+	s.True(exists)
+	s.Equal(schemaVersion, version)
+
+	check := func() {
+		hash, height := dbInst.GetCompactionChainTipInfo()
+		s.Require().Equal(uint64(20), height)
+		tip, err := dbInst.GetBlockByHeight(20)
+		s.Require().NoError(err)
+		s.Equal(hash, tip.Hash)
+		iter, err := dbInst.GetBlocksInRangeReverse(0, 100)
+		s.Require().NoError(err)
+		parent := tip.ParentHash
+		for h := uint64(20); h >= 1; h-- {
+			b, err := iter.NextBlock()
+			s.Require().NoError(err)
+			s.Require().Equal(h, b.Position.Height)
+			s.Equal(h/10, b.Position.Round)
+			s.Equal([]byte{byte(h)}, b.Payload)
+			if h < 20 {
+				s.Equal(parent, b.Hash)
+				parent = b.ParentHash
+			}
+		}
+		_, err = iter.NextBlock()
+		s.Equal(ErrIterationFinished, err)
+	}
+	check()
+
+	// Open again, the upgraded DB should be unchanged.
+	s.Require().NoError(dbInst.Close())
+	dbInst, err = NewLevelDBBackedDB(dir)
+	s.Require().NoError(err)
+	check()
+	s.Require().NoError(dbInst.Close())
+}
+
+func (s *SchemaTestSuite) TestRefuseNewerVersion() {
+	dir, err := ioutil.TempDir("", "dexcon-db")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	dbInst, err := NewLevelDBBackedDB(dir)
+	s.Require().NoError(err)
+	s.Require().NoError(dbInst.putSchemaVersion(schemaVersion + 1))
+	s.Require().NoError(dbInst.Close())
+	_, err = NewLevelDBBackedDB(dir)
+	s.Equal(ErrUnknownSchemaVersion, err)
+	// The DB should be closed and unlocked.
+	_, err = NewLevelDBBackedDB(dir)
+	s.Equal(ErrUnknownSchemaVersion, err)
+}
+
+func TestSchema(t *testing.T) {
+	suite.Run(t, new(SchemaTestSuite))
+}
@@ -181,6 +181,10 @@ func (d *dkgProtocol) toDKGProtocolInfo() db.DKGProtocolInfo {
 
 type dkgShareSecret struct {
 	privateKey *dkg.PrivateKey
+	// The share is kept by remote instead of privateKey when it's not nil.
+	remote DKGSigner
+	round  uint64
+	reset  uint64
 }
 
 // TSigVerifier is the interface verifying threshold signature.
@@ -542,10 +546,15 @@ func (d *dkgProtocol) recoverShareSecret(qualifyIDs dkg.IDs) (
 	}, nil
 }
 
-func (ss *dkgShareSecret) sign(hash common.Hash) dkg.PartialSignature {
+func (ss *dkgShareSecret) sign(hash common.Hash) (
+	dkg.PartialSignature, error) {
+	if ss.remote != nil {
+		sig, err := ss.remote.SignDKG(ss.round, ss.reset, hash)
+		return dkg.PartialSignature(sig), err
+	}
 	// DKG sign will always success.
 	sig, _ := ss.privateKey.Sign(hash)
-	return dkg.PartialSignature(sig)
+	return dkg.PartialSignature(sig), nil
 }
 
 // NewTSigVerifierCache creats a TSigVerifierCache instance.
@@ -129,7 +129,7 @@ func (s *DKGTSIGProtocolTestSuite) newGov(
 	round, reset uint64) *test.Governance {
 	// NOTE: this method doesn't make the tip round in governance to the input
 	//       one.
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		pubKeys, 100, &common.NullLogger{}, true), ConfigRoundShift)
 	s.Require().NoError(err)
 	for i := uint64(0); i < reset; i++ {
@@ -257,13 +257,15 @@ func (s *DKGTSIGProtocolTestSuite) TestDKGTSIGProtocol() {
 	msgHash := crypto.Keccak256Hash([]byte("🏖🍹"))
 	tsig := newTSigProtocol(npks, msgHash)
 	for nID, shareSecret := range shareSecrets {
+		sig, err := shareSecret.sign(msgHash)
+		s.Require().NoError(err)
 		psig := &typesDKG.PartialSignature{
 			ProposerID:       nID,
 			Round:            round,
 			Hash:             msgHash,
-			PartialSignature: shareSecret.sign(msgHash),
+			PartialSignature: sig,
 		}
-		err := s.signers[nID].SignDKGPartialSignature(psig)
+		err = s.signers[nID].SignDKGPartialSignature(psig)
 		s.Require().NoError(err)
 		s.Require().NoError(tsig.processPartialSignature(psig))
 		if len(tsig.sigs) >= k {
@@ -818,20 +820,23 @@ func (s *DKGTSIGProtocolTestSuite) TestPartialSignature() {
 	byzantineID2 := s.nIDs[1]
 	byzantineID3 := s.nIDs[2]
 	for nID, shareSecret := range shareSecrets {
+		sig, err := shareSecret.sign(msgHash)
+		s.Require().NoError(err)
 		psig := &typesDKG.PartialSignature{
 			ProposerID:       nID,
 			Round:            round,
 			Hash:             msgHash,
-			PartialSignature: shareSecret.sign(msgHash),
+			PartialSignature: sig,
 		}
 		switch nID {
 		case byzantineID2:
-			psig.PartialSignature = shareSecret.sign(
+			psig.PartialSignature, err = shareSecret.sign(
 				crypto.Keccak256Hash([]byte("💣")))
+			s.Require().NoError(err)
 		case byzantineID3:
 			psig.Hash = common.NewRandomHash()
 		}
-		err := s.signers[nID].SignDKGPartialSignature(psig)
+		err = s.signers[nID].SignDKGPartialSignature(psig)
 		s.Require().NoError(err)
 		err = tsig.processPartialSignature(psig)
 		switch nID {
@@ -1039,7 +1044,7 @@ func benchmarkDKGGroupPubliKey(k, n int, b *testing.B) {
 	if err != nil {
 		panic(err)
 	}
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		pubKeys, 100, &common.NullLogger{}, true), ConfigRoundShift)
 	if err != nil {
 		panic(err)
@@ -1083,7 +1088,7 @@ func benchmarkDKGNodePubliKeys(k, n int, b *testing.B) {
 	if err != nil {
 		panic(err)
 	}
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		pubKeys, 100, &common.NullLogger{}, true), ConfigRoundShift)
 	if err != nil {
 		panic(err)
@@ -1127,7 +1132,7 @@ func benchmarkCalcQualified(k, n int, b *testing.B) {
 	if err != nil {
 		panic(err)
 	}
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		pubKeys, 100, &common.NullLogger{}, true), ConfigRoundShift)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,633 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	typesDKG "github.com/dexon-foundation/dexon-consensus/core/types/dkg"
+)
+
+// ErrGovernanceNotReady is returned by constructors taking GovernanceV2 when
+// the state required to bootstrap is not available in governance.
+type ErrGovernanceNotReady struct {
+	Round uint64
+	Item  string
+}
+
+func (e ErrGovernanceNotReady) Error() string {
+	return fmt.Sprintf("%s of round %d is not ready in governance",
+		e.Item, e.Round)
+}
+
+// RetryConfig defines the backoff behavior when retrying requests to
+// governance.
+type RetryConfig struct {
+	// InitialBackoff is the interval before the first retry, it would be
+	// doubled for each following retry until reaching MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxAttempts is the max count of attempts for one request, including the
+	// first one. Zero means retrying until the context is done.
+	MaxAttempts int
+	// RetryWrites enables retrying of methods changing the state of
+	// governance, ex. ProposeCRS and AddDKG*. They are not idempotent in
+	// general, so they are only tried once by default.
+	RetryWrites bool
+}
+
+// DefaultRetryConfig is the default RetryConfig.
+var DefaultRetryConfig = RetryConfig{
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// governanceV2Adapter adapts GovernanceV2 to Governance interface, failed
+// requests would be retried with backoff.
+type governanceV2Adapter struct {
+	ctx    context.Context
+	gov    GovernanceV2
+	config RetryConfig
+	logger common.Logger
+
+	// Configurations, node sets and begin heights of rounds never change
+	// once they are ready, they are cached to serve requests when governance
+	// is unavailable.
+	cacheLock    sync.RWMutex
+	configs      map[uint64]*types.Config
+	nodeSets     map[uint64][]crypto.PublicKey
+	roundHeights map[uint64]uint64
+}
+
+// NewGovernanceFromV2 adapts a GovernanceV2 instance to Governance interface.
+// Read requests to governance would be retried according to RetryConfig when
+// errors returned, and would be given up when 'ctx' is done. After giving up,
+// the value cached from previous successful requests is returned if any,
+// otherwise a zero value is returned, just like the case that things are not
+// ready. Write requests are only tried once unless RetryWrites is set.
+//
+// Prefer NewConsensusWithGovernanceV2 and its syncer counterpart, which
+// report errors instead of panicking when governance is unavailable during
+// bootstrap.
+func NewGovernanceFromV2(ctx context.Context, gov GovernanceV2,
+	config RetryConfig, logger common.Logger) Governance {
+	return newGovernanceV2Adapter(ctx, gov, config, logger)
+}
+
+func newGovernanceV2Adapter(ctx context.Context, gov GovernanceV2,
+	config RetryConfig, logger common.Logger) *governanceV2Adapter {
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultRetryConfig.InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+	return &governanceV2Adapter{
+		ctx:          ctx,
+		gov:          gov,
+		config:       config,
+		logger:       logger,
+		configs:      make(map[uint64]*types.Config),
+		nodeSets:     make(map[uint64][]crypto.PublicKey),
+		roundHeights: make(map[uint64]uint64),
+	}
+}
+
+// retry calls 'fn' until it succeeds, the context is done or max attempts is
+// reached.
+func (g *governanceV2Adapter) retry(
+	method string, fn func(ctx context.Context) error) (err error) {
+	backoff := g.config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		if err = fn(g.ctx); err == nil {
+			return
+		}
+		if g.config.MaxAttempts > 0 && attempt >= g.config.MaxAttempts {
+			break
+		}
+		g.logger.Warn("Governance request failed, retry later",
+			"method", method,
+			"attempt", attempt,
+			"backoff", backoff,
+			"error", err)
+		select {
+		case <-g.ctx.Done():
+			err = g.ctx.Err()
+		case <-time.After(backoff):
+		}
+		if g.ctx.Err() != nil {
+			break
+		}
+		if backoff *= 2; backoff > g.config.MaxBackoff {
+			backoff = g.config.MaxBackoff
+		}
+	}
+	g.logger.Error("Governance request failed",
+		"method", method,
+		"error", err)
+	return
+}
+
+// write calls 'fn' once, or retries it when RetryWrites is set.
+func (g *governanceV2Adapter) write(
+	method string, fn func(ctx context.Context) error) {
+	if g.config.RetryWrites {
+		g.retry(method, fn)
+		return
+	}
+	if err := fn(g.ctx); err != nil {
+		g.logger.Error("Governance request failed",
+			"method", method,
+			"error", err)
+	}
+}
+
+// configuration returns the configuration of a round, and caches it when
+// ready.
+func (g *governanceV2Adapter) configuration(round uint64) (
+	c *types.Config, err error) {
+	g.cacheLock.RLock()
+	c, cached := g.configs[round]
+	g.cacheLock.RUnlock()
+	if cached {
+		return
+	}
+	if err = g.retry("Configuration", func(ctx context.Context) (err error) {
+		c, err = g.gov.Configuration(ctx, round)
+		return
+	}); err != nil || c == nil {
+		return nil, err
+	}
+	g.cacheLock.Lock()
+	defer g.cacheLock.Unlock()
+	g.configs[round] = c
+	return
+}
+
+// nodeSet returns the node set of a round, and caches it when ready.
+func (g *governanceV2Adapter) nodeSet(round uint64) (
+	s []crypto.PublicKey, err error) {
+	g.cacheLock.RLock()
+	s, cached := g.nodeSets[round]
+	g.cacheLock.RUnlock()
+	if cached {
+		return
+	}
+	if err = g.retry("NodeSet", func(ctx context.Context) (err error) {
+		s, err = g.gov.NodeSet(ctx, round)
+		return
+	}); err != nil || len(s) == 0 {
+		return nil, err
+	}
+	g.cacheLock.Lock()
+	defer g.cacheLock.Unlock()
+	g.nodeSets[round] = s
+	return
+}
+
+// roundHeight returns the begin height of a round, and caches it when ready.
+func (g *governanceV2Adapter) roundHeight(round uint64) (
+	h uint64, err error) {
+	g.cacheLock.RLock()
+	h, cached := g.roundHeights[round]
+	g.cacheLock.RUnlock()
+	if cached {
+		return
+	}
+	if err = g.retry("GetRoundHeight", func(ctx context.Context) (err error) {
+		h, err = g.gov.GetRoundHeight(ctx, round)
+		return
+	}); err != nil || h == 0 {
+		return 0, err
+	}
+	g.cacheLock.Lock()
+	defer g.cacheLock.Unlock()
+	g.roundHeights[round] = h
+	return
+}
+
+// prepare makes sure the state to bootstrap from 'round' is ready in
+// governance, failures would be retried and the last error is returned.
+func (g *governanceV2Adapter) prepare(round uint64) error {
+	config, err := g.configuration(round)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return ErrGovernanceNotReady{Round: round, Item: "configuration"}
+	}
+	var crs common.Hash
+	if err = g.retry("CRS", func(ctx context.Context) (err error) {
+		crs, err = g.gov.CRS(ctx, round)
+		return
+	}); err != nil {
+		return err
+	}
+	if (crs == common.Hash{}) {
+		return ErrGovernanceNotReady{Round: round, Item: "CRS"}
+	}
+	nodes, err := g.nodeSet(round)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return ErrGovernanceNotReady{Round: round, Item: "node set"}
+	}
+	// The begin height of round 0 is always zero in governance.
+	if _, err = g.roundHeight(round); err != nil {
+		return err
+	}
+	return g.retry("DKGResetCount", func(ctx context.Context) (err error) {
+		_, err = g.gov.DKGResetCount(ctx, round+1)
+		return
+	})
+}
+
+// Configuration implements Governance interface.
+func (g *governanceV2Adapter) Configuration(round uint64) *types.Config {
+	c, _ := g.configuration(round)
+	return c
+}
+
+// CRS implements Governance interface.
+func (g *governanceV2Adapter) CRS(round uint64) (crs common.Hash) {
+	if err := g.retry("CRS", func(ctx context.Context) (err error) {
+		crs, err = g.gov.CRS(ctx, round)
+		return
+	}); err != nil {
+		crs = common.Hash{}
+	}
+	return
+}
+
+// ProposeCRS implements Governance interface.
+func (g *governanceV2Adapter) ProposeCRS(round uint64, signedCRS []byte) {
+	g.write("ProposeCRS", func(ctx context.Context) error {
+		return g.gov.ProposeCRS(ctx, round, signedCRS)
+	})
+}
+
+// NodeSet implements Governance interface.
+func (g *governanceV2Adapter) NodeSet(round uint64) []crypto.PublicKey {
+	s, _ := g.nodeSet(round)
+	return s
+}
+
+// GetRoundHeight implements Governance interface.
+func (g *governanceV2Adapter) GetRoundHeight(round uint64) uint64 {
+	h, _ := g.roundHeight(round)
+	return h
+}
+
+// AddDKGComplaint implements Governance interface.
+func (g *governanceV2Adapter) AddDKGComplaint(complaint *typesDKG.Complaint) {
+	g.write("AddDKGComplaint", func(ctx context.Context) error {
+		return g.gov.AddDKGComplaint(ctx, complaint)
+	})
+}
+
+// DKGComplaints implements Governance interface.
+func (g *governanceV2Adapter) DKGComplaints(
+	round uint64) (c []*typesDKG.Complaint) {
+	if err := g.retry("DKGComplaints", func(ctx context.Context) (err error) {
+		c, err = g.gov.DKGComplaints(ctx, round)
+		return
+	}); err != nil {
+		c = nil
+	}
+	return
+}
+
+// AddDKGMasterPublicKey implements Governance interface.
+func (g *governanceV2Adapter) AddDKGMasterPublicKey(
+	masterPublicKey *typesDKG.MasterPublicKey) {
+	g.write("AddDKGMasterPublicKey", func(ctx context.Context) error {
+		return g.gov.AddDKGMasterPublicKey(ctx, masterPublicKey)
+	})
+}
+
+// DKGMasterPublicKeys implements Governance interface.
+func (g *governanceV2Adapter) DKGMasterPublicKeys(
+	round uint64) (mpks []*typesDKG.MasterPublicKey) {
+	if err := g.retry("DKGMasterPublicKeys",
+		func(ctx context.Context) (err error) {
+			mpks, err = g.gov.DKGMasterPublicKeys(ctx, round)
+			return
+		}); err != nil {
+		mpks = nil
+	}
+	return
+}
+
+// AddDKGMPKReady implements Governance interface.
+func (g *governanceV2Adapter) AddDKGMPKReady(ready *typesDKG.MPKReady) {
+	g.write("AddDKGMPKReady", func(ctx context.Context) error {
+		return g.gov.AddDKGMPKReady(ctx, ready)
+	})
+}
+
+// IsDKGMPKReady implements Governance interface.
+func (g *governanceV2Adapter) IsDKGMPKReady(round uint64) (ready bool) {
+	if err := g.retry("IsDKGMPKReady", func(ctx context.Context) (err error) {
+		ready, err = g.gov.IsDKGMPKReady(ctx, round)
+		return
+	}); err != nil {
+		ready = false
+	}
+	return
+}
+
+// AddDKGFinalize implements Governance interface.
+func (g *governanceV2Adapter) AddDKGFinalize(final *typesDKG.Finalize) {
+	g.write("AddDKGFinalize", func(ctx context.Context) error {
+		return g.gov.AddDKGFinalize(ctx, final)
+	})
+}
+
+// IsDKGFinal implements Governance interface.
+func (g *governanceV2Adapter) IsDKGFinal(round uint64) (final bool) {
+	if err := g.retry("IsDKGFinal", func(ctx context.Context) (err error) {
+		final, err = g.gov.IsDKGFinal(ctx, round)
+		return
+	}); err != nil {
+		final = false
+	}
+	return
+}
+
+// AddDKGSuccess implements Governance interface.
+func (g *governanceV2Adapter) AddDKGSuccess(success *typesDKG.Success) {
+	g.write("AddDKGSuccess", func(ctx context.Context) error {
+		return g.gov.AddDKGSuccess(ctx, success)
+	})
+}
+
+// IsDKGSuccess implements Governance interface.
+func (g *governanceV2Adapter) IsDKGSuccess(round uint64) (success bool) {
+	if err := g.retry("IsDKGSuccess", func(ctx context.Context) (err error) {
+		success, err = g.gov.IsDKGSuccess(ctx, round)
+		return
+	}); err != nil {
+		success = false
+	}
+	return
+}
+
+// ReportForkVote implements Governance interface.
+func (g *governanceV2Adapter) ReportForkVote(vote1, vote2 *types.Vote) {
+	g.write("ReportForkVote", func(ctx context.Context) error {
+		return g.gov.ReportForkVote(ctx, vote1, vote2)
+	})
+}
+
+// ReportForkBlock implements Governance interface.
+func (g *governanceV2Adapter) ReportForkBlock(block1, block2 *types.Block) {
+	g.write("ReportForkBlock", func(ctx context.Context) error {
+		return g.gov.ReportForkBlock(ctx, block1, block2)
+	})
+}
+
+// ResetDKG implements Governance interface.
+func (g *governanceV2Adapter) ResetDKG(newSignedCRS []byte) {
+	g.write("ResetDKG", func(ctx context.Context) error {
+		return g.gov.ResetDKG(ctx, newSignedCRS)
+	})
+}
+
+// DKGResetCount implements Governance interface.
+func (g *governanceV2Adapter) DKGResetCount(round uint64) (count uint64) {
+	if err := g.retry("DKGResetCount", func(ctx context.Context) (err error) {
+		count, err = g.gov.DKGResetCount(ctx, round)
+		return
+	}); err != nil {
+		count = 0
+	}
+	return
+}
+
+// PrepareGovernanceV2 adapts 'gov' to Governance interface like
+// NewGovernanceFromV2, and makes sure the state to bootstrap from 'round' is
+// available in governance. Failed requests are retried according to
+// 'config', and the error is returned instead of panicking in constructors
+// later. It's designed for packages constructing Consensus instances, ex.
+// the syncer.
+func PrepareGovernanceV2(ctx context.Context, gov GovernanceV2, round uint64,
+	config RetryConfig, logger common.Logger) (Governance, error) {
+	adapter := newGovernanceV2Adapter(ctx, gov, config, logger)
+	if err := adapter.prepare(round); err != nil {
+		return nil, err
+	}
+	return adapter, nil
+}
+
+// governanceV1Wrapper wraps Governance to GovernanceV2 interface.
+type governanceV1Wrapper struct {
+	gov Governance
+}
+
+// NewGovernanceV2 wraps a Governance instance to GovernanceV2 interface. The
+// wrapped instance never fails unless the context is done before calling.
+func NewGovernanceV2(gov Governance) GovernanceV2 {
+	return &governanceV1Wrapper{gov: gov}
+}
+
+// Configuration implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) Configuration(
+	ctx context.Context, round uint64) (*types.Config, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return g.gov.Configuration(round), nil
+}
+
+// CRS implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) CRS(
+	ctx context.Context, round uint64) (common.Hash, error) {
+	if err := ctx.Err(); err != nil {
+		return common.Hash{}, err
+	}
+	return g.gov.CRS(round), nil
+}
+
+// ProposeCRS implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) ProposeCRS(
+	ctx context.Context, round uint64, signedCRS []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.gov.ProposeCRS(round, signedCRS)
+	return nil
+}
+
+// NodeSet implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) NodeSet(
+	ctx context.Context, round uint64) ([]crypto.PublicKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return g.gov.NodeSet(round), nil
+}
+
+// GetRoundHeight implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) GetRoundHeight(
+	ctx context.Context, round uint64) (uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return g.gov.GetRoundHeight(round), nil
+}
+
+// AddDKGComplaint implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) AddDKGComplaint(
+	ctx context.Context, complaint *typesDKG.Complaint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.gov.AddDKGComplaint(complaint)
+	return nil
+}
+
+// DKGComplaints implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) DKGComplaints(
+	ctx context.Context, round uint64) ([]*typesDKG.Complaint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return g.gov.DKGComplaints(round), nil
+}
+
+// AddDKGMasterPublicKey implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) AddDKGMasterPublicKey(
+	ctx context.Context, masterPublicKey *typesDKG.MasterPublicKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.gov.AddDKGMasterPublicKey(masterPublicKey)
+	return nil
+}
+
+// DKGMasterPublicKeys implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) DKGMasterPublicKeys(
+	ctx context.Context, round uint64) ([]*typesDKG.MasterPublicKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return g.gov.DKGMasterPublicKeys(round), nil
+}
+
+// AddDKGMPKReady implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) AddDKGMPKReady(
+	ctx context.Context, ready *typesDKG.MPKReady) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.gov.AddDKGMPKReady(ready)
+	return nil
+}
+
+// IsDKGMPKReady implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) IsDKGMPKReady(
+	ctx context.Context, round uint64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return g.gov.IsDKGMPKReady(round), nil
+}
+
+// AddDKGFinalize implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) AddDKGFinalize(
+	ctx context.Context, final *typesDKG.Finalize) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.gov.AddDKGFinalize(final)
+	return nil
+}
+
+// IsDKGFinal implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) IsDKGFinal(
+	ctx context.Context, round uint64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return g.gov.IsDKGFinal(round), nil
+}
+
+// AddDKGSuccess implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) AddDKGSuccess(
+	ctx context.Context, success *typesDKG.Success) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.gov.AddDKGSuccess(success)
+	return nil
+}
+
+// IsDKGSuccess implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) IsDKGSuccess(
+	ctx context.Context, round uint64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return g.gov.IsDKGSuccess(round), nil
+}
+
+// ReportForkVote implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) ReportForkVote(
+	ctx context.Context, vote1, vote2 *types.Vote) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.gov.ReportForkVote(vote1, vote2)
+	return nil
+}
+
+// ReportForkBlock implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) ReportForkBlock(
+	ctx context.Context, block1, block2 *types.Block) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.gov.ReportForkBlock(block1, block2)
+	return nil
+}
+
+// ResetDKG implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) ResetDKG(
+	ctx context.Context, newSignedCRS []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.gov.ResetDKG(newSignedCRS)
+	return nil
+}
+
+// DKGResetCount implements GovernanceV2 interface.
+func (g *governanceV1Wrapper) DKGResetCount(
+	ctx context.Context, round uint64) (uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return g.gov.DKGResetCount(round), nil
+}
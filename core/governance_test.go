@@ -0,0 +1,239 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+var errGovernanceUnavailable = errors.New("governance unavailable")
+
+// flakyGovernance fails the first several calls of read methods.
+type flakyGovernance struct {
+	GovernanceV2
+	lock     sync.Mutex
+	failures int
+	calls    int
+}
+
+func (g *flakyGovernance) fail() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.calls++
+	if g.failures > 0 {
+		g.failures--
+		return true
+	}
+	return false
+}
+
+func (g *flakyGovernance) setFailures(failures int) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.failures, g.calls = failures, 0
+}
+
+func (g *flakyGovernance) Configuration(
+	ctx context.Context, round uint64) (*types.Config, error) {
+	if g.fail() {
+		return nil, errGovernanceUnavailable
+	}
+	return g.GovernanceV2.Configuration(ctx, round)
+}
+
+func (g *flakyGovernance) CRS(
+	ctx context.Context, round uint64) (common.Hash, error) {
+	if g.fail() {
+		return common.Hash{}, errGovernanceUnavailable
+	}
+	return g.GovernanceV2.CRS(ctx, round)
+}
+
+func (g *flakyGovernance) NodeSet(
+	ctx context.Context, round uint64) ([]crypto.PublicKey, error) {
+	if g.fail() {
+		return nil, errGovernanceUnavailable
+	}
+	return g.GovernanceV2.NodeSet(ctx, round)
+}
+
+func (g *flakyGovernance) ReportForkVote(
+	ctx context.Context, vote1, vote2 *types.Vote) error {
+	if g.fail() {
+		return errGovernanceUnavailable
+	}
+	return g.GovernanceV2.ReportForkVote(ctx, vote1, vote2)
+}
+
+type GovernanceTestSuite struct {
+	suite.Suite
+}
+
+func (s *GovernanceTestSuite) newFlakyGovernance() (
+	*flakyGovernance, []crypto.PrivateKey) {
+	prvKeys, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
+		pubKeys, time.Second, &common.NullLogger{}, true), ConfigRoundShift)
+	s.Require().NoError(err)
+	return &flakyGovernance{GovernanceV2: NewGovernanceV2(gov)}, prvKeys
+}
+
+func (s *GovernanceTestSuite) TestRetry() {
+	flaky, _ := s.newFlakyGovernance()
+	gov := NewGovernanceFromV2(context.Background(), flaky, RetryConfig{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     4 * time.Millisecond,
+	}, &common.NullLogger{})
+	flaky.setFailures(5)
+	s.Require().NotNil(gov.Configuration(0))
+	s.Require().Equal(6, flaky.calls)
+	// Give up after max attempts.
+	gov = NewGovernanceFromV2(context.Background(), flaky, RetryConfig{
+		InitialBackoff: time.Millisecond,
+		MaxAttempts:    3,
+	}, &common.NullLogger{})
+	flaky.setFailures(5)
+	s.Require().Equal(common.Hash{}, gov.CRS(0))
+	s.Require().Equal(3, flaky.calls)
+	s.Require().NotEqual(common.Hash{}, gov.CRS(0))
+	// Write methods are not retried by default.
+	flaky.setFailures(5)
+	gov.ReportForkVote(&types.Vote{}, &types.Vote{})
+	s.Require().Equal(1, flaky.calls)
+	gov = NewGovernanceFromV2(context.Background(), flaky, RetryConfig{
+		InitialBackoff: time.Millisecond,
+		RetryWrites:    true,
+	}, &common.NullLogger{})
+	flaky.setFailures(5)
+	gov.ReportForkVote(&types.Vote{}, &types.Vote{})
+	s.Require().Equal(6, flaky.calls)
+}
+
+func (s *GovernanceTestSuite) TestCache() {
+	flaky, _ := s.newFlakyGovernance()
+	gov := NewGovernanceFromV2(context.Background(), flaky, RetryConfig{
+		InitialBackoff: time.Millisecond,
+		MaxAttempts:    1,
+	}, &common.NullLogger{})
+	config := gov.Configuration(0)
+	s.Require().NotNil(config)
+	nodes := gov.NodeSet(0)
+	s.Require().NotEmpty(nodes)
+	// Cached values are returned when governance is unavailable.
+	flaky.setFailures(10)
+	s.Require().Equal(config, gov.Configuration(0))
+	s.Require().Equal(nodes, gov.NodeSet(0))
+	s.Require().Equal(0, flaky.calls)
+	// Things not ready are not cached.
+	s.Require().Nil(gov.Configuration(100))
+	s.Require().Equal(1, flaky.calls)
+}
+
+func (s *GovernanceTestSuite) TestContextDone() {
+	flaky, _ := s.newFlakyGovernance()
+	ctx, cancel := context.WithCancel(context.Background())
+	gov := NewGovernanceFromV2(ctx, flaky, RetryConfig{
+		InitialBackoff: time.Hour,
+	}, &common.NullLogger{})
+	flaky.setFailures(5)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	s.Require().Nil(gov.NodeSet(0))
+	s.Require().Equal(1, flaky.calls)
+	// The wrapped V1 instance fails when context is done.
+	_, err := NewGovernanceV2(gov).NodeSet(ctx, 0)
+	s.Require().Equal(context.Canceled, err)
+}
+
+func (s *GovernanceTestSuite) TestModulesWithFlakyGovernance() {
+	flaky, prvKeys := s.newFlakyGovernance()
+	gov := NewGovernanceFromV2(context.Background(), flaky, RetryConfig{
+		InitialBackoff: time.Millisecond,
+	}, &common.NullLogger{})
+	// NodeSetCache.
+	flaky.setFailures(10)
+	cache := utils.NewNodeSetCache(gov)
+	notarySet, err := cache.GetNotarySet(0)
+	s.Require().NoError(err)
+	s.Require().Len(notarySet, 4)
+	// RoundEvent.
+	flaky.setFailures(10)
+	_, err = utils.NewRoundEvent(context.Background(), gov,
+		&common.NullLogger{}, types.Position{Height: types.GenesisHeight},
+		types.DefaultParams())
+	s.Require().NoError(err)
+	// Consensus.
+	flaky.setFailures(10)
+	dbInst, err := db.NewMemBackedDB()
+	s.Require().NoError(err)
+	network := test.NewNetwork(prvKeys[0].PublicKey(), test.NetworkConfig{
+		Type:          test.NetworkTypeFake,
+		DirectLatency: &test.FixedLatencyModel{},
+		GossipLatency: &test.FixedLatencyModel{},
+		Marshaller:    test.NewDefaultMarshaller(nil),
+	})
+	con, err := NewConsensusWithGovernanceV2(context.Background(),
+		time.Now().UTC(), types.DefaultParams(), test.NewApp(0, nil, nil),
+		flaky, RetryConfig{InitialBackoff: time.Millisecond}, dbInst, network,
+		prvKeys[0], &common.NullLogger{})
+	s.Require().NoError(err)
+	con.Stop()
+	// Errors are returned instead of panicking when governance keeps
+	// failing.
+	flaky.setFailures(10)
+	_, err = NewConsensusWithGovernanceV2(context.Background(),
+		time.Now().UTC(), types.DefaultParams(), test.NewApp(0, nil, nil),
+		flaky, RetryConfig{InitialBackoff: time.Millisecond, MaxAttempts: 3},
+		dbInst, network, prvKeys[0], &common.NullLogger{})
+	s.Require().Equal(errGovernanceUnavailable, err)
+	// Errors are returned when the bootstrap state is not ready.
+	tip := &types.Block{
+		Hash:       common.NewRandomHash(),
+		Position:   types.Position{Round: 100, Height: types.GenesisHeight},
+		Randomness: NoRand,
+	}
+	s.Require().NoError(dbInst.PutBlock(*tip))
+	s.Require().NoError(dbInst.PutCompactionChainTipInfo(
+		tip.Hash, tip.Position.Height))
+	_, err = NewConsensusWithGovernanceV2(context.Background(),
+		time.Now().UTC(), types.DefaultParams(), test.NewApp(0, nil, nil),
+		flaky, RetryConfig{InitialBackoff: time.Millisecond}, dbInst, network,
+		prvKeys[0], &common.NullLogger{})
+	s.Require().Equal(
+		ErrGovernanceNotReady{Round: 100, Item: "configuration"}, err)
+}
+
+func TestGovernance(t *testing.T) {
+	suite.Run(t, new(GovernanceTestSuite))
+}
@@ -18,10 +18,12 @@
 package core
 
 import (
+	"context"
 	"time"
 
 	"github.com/dexon-foundation/dexon-consensus/common"
 	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
 	"github.com/dexon-foundation/dexon-consensus/core/types"
 	typesDKG "github.com/dexon-foundation/dexon-consensus/core/types/dkg"
 )
@@ -160,6 +162,82 @@ type Governance interface {
 	DKGResetCount(round uint64) uint64
 }
 
+// GovernanceV2 is the context-aware version of Governance interface. Methods
+// return errors when governance is temporarily unavailable, ex. the RPC to
+// governance contract failed. Like Governance interface, it's not an error
+// when something is not ready yet, zero values should be returned with nil
+// error in that case.
+//
+// Pass it to NewConsensusWithGovernanceV2, or the syncer counterpart, to
+// construct instances reporting unavailable governance as errors. Use
+// NewGovernanceFromV2 to adapt it to Governance interface for other modules.
+type GovernanceV2 interface {
+	// Configuration returns the configuration at a given round.
+	// Return the genesis configuration if round == 0.
+	Configuration(ctx context.Context, round uint64) (*types.Config, error)
+
+	// CRS returns the CRS for a given round. Return the genesis CRS if
+	// round == 0.
+	CRS(ctx context.Context, round uint64) (common.Hash, error)
+
+	// Propose a CRS of round.
+	ProposeCRS(ctx context.Context, round uint64, signedCRS []byte) error
+
+	// NodeSet returns the node set at a given round.
+	// Return the genesis node set if round == 0.
+	NodeSet(ctx context.Context, round uint64) ([]crypto.PublicKey, error)
+
+	// Get the begin height of a round.
+	GetRoundHeight(ctx context.Context, round uint64) (uint64, error)
+
+	//// DKG-related methods.
+
+	// AddDKGComplaint adds a DKGComplaint.
+	AddDKGComplaint(ctx context.Context, complaint *typesDKG.Complaint) error
+
+	// DKGComplaints gets all the DKGComplaints of round.
+	DKGComplaints(ctx context.Context, round uint64) (
+		[]*typesDKG.Complaint, error)
+
+	// AddDKGMasterPublicKey adds a DKGMasterPublicKey.
+	AddDKGMasterPublicKey(ctx context.Context,
+		masterPublicKey *typesDKG.MasterPublicKey) error
+
+	// DKGMasterPublicKeys gets all the DKGMasterPublicKey of round.
+	DKGMasterPublicKeys(ctx context.Context, round uint64) (
+		[]*typesDKG.MasterPublicKey, error)
+
+	// AddDKGMPKReady adds a DKG ready message.
+	AddDKGMPKReady(ctx context.Context, ready *typesDKG.MPKReady) error
+
+	// IsDKGMPKReady checks if DKG's master public key preparation is ready.
+	IsDKGMPKReady(ctx context.Context, round uint64) (bool, error)
+
+	// AddDKGFinalize adds a DKG finalize message.
+	AddDKGFinalize(ctx context.Context, final *typesDKG.Finalize) error
+
+	// IsDKGFinal checks if DKG is final.
+	IsDKGFinal(ctx context.Context, round uint64) (bool, error)
+
+	// AddDKGSuccess adds a DKG success message.
+	AddDKGSuccess(ctx context.Context, success *typesDKG.Success) error
+
+	// IsDKGSuccess checks if DKG is success.
+	IsDKGSuccess(ctx context.Context, round uint64) (bool, error)
+
+	// ReportForkVote reports a node for forking votes.
+	ReportForkVote(ctx context.Context, vote1, vote2 *types.Vote) error
+
+	// ReportForkBlock reports a node for forking blocks.
+	ReportForkBlock(ctx context.Context, block1, block2 *types.Block) error
+
+	// ResetDKG resets latest DKG data and propose new CRS.
+	ResetDKG(ctx context.Context, newSignedCRS []byte) error
+
+	// DKGResetCount returns the reset count for DKG of given round.
+	DKGResetCount(ctx context.Context, round uint64) (uint64, error)
+}
+
 // Ticker define the capability to tick by interval.
 type Ticker interface {
 	// Tick would return a channel, which would be triggered until next tick.
@@ -180,3 +258,45 @@ type Recovery interface {
 	// Votes gets the number of votes of given height.
 	Votes(height uint64) (uint64, error)
 }
+
+// Metrics describes the interface for consensus core to report statistics of
+// its internal modules. Implementations should be safe for concurrent use and
+// should not block the caller.
+type Metrics interface {
+	// ObserveBAPeriod reports the period at which BA confirms a block.
+	ObserveBAPeriod(pos types.Position, period uint64)
+
+	// ObserveBAConfirmLatency reports the time elapsed from the start of BA
+	// for a position, when the leader is supposed to propose, to the
+	// confirmation of a block.
+	ObserveBAConfirmLatency(pos types.Position, elapsed time.Duration)
+
+	// ReportBAConfirmPath reports if a block is confirmed via fast path or
+	// slow path.
+	ReportBAConfirmPath(pos types.Position, fast bool)
+
+	// ObserveDKGPhase reports the time spent in one DKG phase.
+	ObserveDKGPhase(round, reset uint64, phase int, elapsed time.Duration)
+
+	// ReportDKGReset reports the DKG of a round is reset.
+	ReportDKGReset(round, reset uint64)
+
+	// ObserveRandomnessLatency reports the time elapsed from the confirmation
+	// of a block to the readiness of its randomness.
+	ObserveRandomnessLatency(pos types.Position, elapsed time.Duration)
+
+	// ReportQueueDepth reports the count of pending items in an internal
+	// queue.
+	ReportQueueDepth(queue string, depth int)
+}
+
+// DKGSigner keeps DKG private shares outside of consensus core and signs with
+// them. Implementations should be safe for concurrent use.
+type DKGSigner interface {
+	// PutDKGPrivateKey hands over the DKG private share of a round recovered
+	// by DKG protocol.
+	PutDKGPrivateKey(round, reset uint64, prvKey dkg.PrivateKey) error
+
+	// SignDKG signs a hash with the DKG private share of a round.
+	SignDKG(round, reset uint64, hash common.Hash) (crypto.Signature, error)
+}
@@ -106,7 +106,8 @@ func (s *LeaderSelectorTestSuite) TestLeaderBlockHash() {
 			Hash:       common.NewRandomHash(),
 		}
 		s.Require().NoError(
-			utils.NewSigner(prv).SignCRS(block, leader.hashCRS))
+			utils.NewSigner(prv).SignCRS(
+				block, leader.hashCRS, DKGDelayRound))
 		s.Require().NoError(leader.processBlock(block))
 		blocks[block.Hash] = block
 	}
@@ -134,7 +135,8 @@ func (s *LeaderSelectorTestSuite) TestValidLeaderFn() {
 			Hash:       common.NewRandomHash(),
 		}
 		s.Require().NoError(
-			utils.NewSigner(prv).SignCRS(block, leader.hashCRS))
+			utils.NewSigner(prv).SignCRS(
+				block, leader.hashCRS, DKGDelayRound))
 		s.Require().NoError(leader.processBlock(block))
 		blocks[block.Hash] = block
 	}
@@ -165,7 +167,8 @@ func (s *LeaderSelectorTestSuite) TestPotentialLeader() {
 			Hash:       common.NewRandomHash(),
 		}
 		s.Require().NoError(
-			utils.NewSigner(prv).SignCRS(block, leader.hashCRS))
+			utils.NewSigner(prv).SignCRS(
+				block, leader.hashCRS, DKGDelayRound))
 		ok, _ := leader.potentialLeader(block)
 		s.Require().NoError(leader.processBlock(block))
 		if i > 0 {
@@ -0,0 +1,298 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+// Package lightclient verifies finalized blocks with governance data only,
+// it's designed for those who need to trust blocks without running consensus
+// or storing every block.
+package lightclient
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	typesDKG "github.com/dexon-foundation/dexon-consensus/core/types/dkg"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+// groupPublicKeyCacheSize is the count of rounds to cache group public keys.
+const groupPublicKeyCacheSize = 16
+
+// Errors for light client.
+var (
+	ErrBlockNotFinalized = fmt.Errorf(
+		"block is not finalized")
+	ErrIncorrectRandomness = fmt.Errorf(
+		"incorrect block randomness")
+	ErrIncorrectBlockHash = fmt.Errorf(
+		"incorrect block hash")
+	ErrProposerNotInNotarySet = fmt.Errorf(
+		"block proposer is not in notary set")
+	ErrDKGNotReady = fmt.Errorf(
+		"DKG of round is not ready")
+	ErrConfigurationNotReady = fmt.Errorf(
+		"configuration of round is not ready")
+	ErrNoTrustedBlock = fmt.Errorf(
+		"no trusted block to follow")
+	ErrInvalidBlockHeight = fmt.Errorf(
+		"invalid block height")
+	ErrIncorrectParentHash = fmt.Errorf(
+		"incorrect parent hash")
+	ErrInvalidRoundID = fmt.Errorf(
+		"invalid round id")
+	ErrRoundNotSwitch = fmt.Errorf(
+		"round not switch")
+)
+
+// Governance is the subset of core.Governance required by light client, all
+// methods are read-only.
+type Governance interface {
+	// Configuration returns the configuration at a given round.
+	// Return the genesis configuration if round == 0.
+	Configuration(round uint64) *types.Config
+
+	// CRS returns the CRS for a given round.
+	// Return the genesis CRS if round == 0.
+	CRS(round uint64) common.Hash
+
+	// NodeSet returns the node set at a given round.
+	// Return the genesis node set if round == 0.
+	NodeSet(round uint64) []crypto.PublicKey
+
+	// Get the begin height of a round.
+	GetRoundHeight(round uint64) uint64
+
+	// DKGComplaints gets all the DKGComplaints of round.
+	DKGComplaints(round uint64) []*typesDKG.Complaint
+
+	// DKGMasterPublicKeys gets all the DKGMasterPublicKey of round.
+	DKGMasterPublicKeys(round uint64) []*typesDKG.MasterPublicKey
+
+	// IsDKGFinal checks if DKG is final.
+	IsDKGFinal(round uint64) bool
+
+	// IsDKGSuccess checks if DKG is success.
+	IsDKGSuccess(round uint64) bool
+}
+
+// Client verifies finalized blocks and follows the chain from a trusted
+// block.
+//
+// Blocks in rounds before DKGDelayRound carry no threshold signature, their
+// finality could not be proved by themselves. They are only checked against
+// their proposers and linked by parent hash, and would be proved implicitly
+// once a block after them is verified.
+type Client struct {
+	gov          Governance
+	params       types.Params
+	nodeSetCache *utils.NodeSetCache
+	gpks         *lru.Cache
+	logger       common.Logger
+
+	// lock for accessing the tip.
+	lock sync.RWMutex
+	tip  *types.Block
+}
+
+// NewClient creates a light client. The trusted block is the block to follow
+// from, it could be nil and the genesis block is expected to be followed
+// first.
+func NewClient(gov Governance, params types.Params, trusted *types.Block,
+	logger common.Logger) *Client {
+	if err := params.Validate(); err != nil {
+		panic(err)
+	}
+	gpks, err := lru.New(groupPublicKeyCacheSize)
+	if err != nil {
+		panic(err)
+	}
+	c := &Client{
+		gov:          gov,
+		params:       params,
+		nodeSetCache: utils.NewNodeSetCache(gov),
+		gpks:         gpks,
+		logger:       logger,
+	}
+	if trusted != nil {
+		c.tip = trusted.Clone()
+	}
+	return c
+}
+
+// Tip returns the latest block followed.
+func (c *Client) Tip() *types.Block {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if c.tip == nil {
+		return nil
+	}
+	return c.tip.Clone()
+}
+
+// VerifyBlock verifies if a block is finalized, its position in the chain is
+// not checked. The payload is verified against the payload hash only when it's
+// carried by the block.
+func (c *Client) VerifyBlock(b *types.Block) error {
+	if !b.IsFinalized() {
+		return ErrBlockNotFinalized
+	}
+	if err := c.verifyBlockHash(b); err != nil {
+		return err
+	}
+	return c.verifyRandomness(b)
+}
+
+// Follow verifies a block and makes it the new tip when it's the next block
+// of current tip.
+func (c *Client) Follow(b *types.Block) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.verifyPosition(b); err != nil {
+		return err
+	}
+	if err := c.VerifyBlock(b); err != nil {
+		return err
+	}
+	c.tip = b.Clone()
+	return nil
+}
+
+func (c *Client) verifyPosition(b *types.Block) error {
+	if c.tip == nil {
+		if !b.IsGenesis() {
+			return ErrNoTrustedBlock
+		}
+		if b.Position.Round != 0 {
+			return ErrInvalidRoundID
+		}
+		return nil
+	}
+	if b.Position.Height != c.tip.Position.Height+1 {
+		return ErrInvalidBlockHeight
+	}
+	if !b.ParentHash.Equal(c.tip.Hash) {
+		return ErrIncorrectParentHash
+	}
+	switch b.Position.Round {
+	case c.tip.Position.Round:
+	case c.tip.Position.Round + 1:
+		c.logger.Debug("Calling Governance.GetRoundHeight",
+			"round", b.Position.Round)
+		if c.gov.GetRoundHeight(b.Position.Round) != b.Position.Height {
+			return ErrRoundNotSwitch
+		}
+	default:
+		return ErrInvalidRoundID
+	}
+	return nil
+}
+
+func (c *Client) verifyBlockHash(b *types.Block) error {
+	if len(b.Payload) > 0 {
+		if crypto.Keccak256Hash(b.Payload) != b.PayloadHash {
+			return ErrIncorrectBlockHash
+		}
+	}
+	if b.IsEmpty() {
+		// Empty blocks are not signed by anyone, only their hashes are
+		// verified.
+		hash, err := utils.HashBlock(b)
+		if err != nil {
+			return err
+		}
+		if hash != b.Hash {
+			return ErrIncorrectBlockHash
+		}
+		return nil
+	}
+	if err := utils.VerifyBlockSignatureWithoutPayload(b); err != nil {
+		return err
+	}
+	inNotarySet := func() (bool, error) {
+		notarySet, err := c.nodeSetCache.GetNotarySet(b.Position.Round)
+		if err != nil {
+			return false, err
+		}
+		_, exists := notarySet[b.ProposerID]
+		return exists, nil
+	}
+	exists, err := inNotarySet()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// The notary set might be changed by DKG reset after it's cached.
+		c.nodeSetCache.Purge(b.Position.Round)
+		if exists, err = inNotarySet(); err != nil {
+			return err
+		}
+	}
+	if !exists {
+		return ErrProposerNotInNotarySet
+	}
+	return nil
+}
+
+func (c *Client) verifyRandomness(b *types.Block) error {
+	if b.Position.Round < c.params.DKGDelayRound {
+		if !bytes.Equal(b.Randomness, c.params.NoRand) {
+			return ErrIncorrectRandomness
+		}
+		return nil
+	}
+	gpk, err := c.groupPublicKey(b.Position.Round)
+	if err != nil {
+		return err
+	}
+	if !gpk.VerifySignature(b.Hash, crypto.Signature{
+		Type:      "bls",
+		Signature: b.Randomness,
+	}) {
+		return ErrIncorrectRandomness
+	}
+	return nil
+}
+
+// groupPublicKey returns the group public key of a round, it's only available
+// after DKG of that round is final and successful.
+func (c *Client) groupPublicKey(round uint64) (*typesDKG.GroupPublicKey,
+	error) {
+	if v, exists := c.gpks.Get(round); exists {
+		return v.(*typesDKG.GroupPublicKey), nil
+	}
+	if !c.gov.IsDKGFinal(round) || !c.gov.IsDKGSuccess(round) {
+		return nil, ErrDKGNotReady
+	}
+	cfg := c.gov.Configuration(round)
+	if cfg == nil {
+		return nil, ErrConfigurationNotReady
+	}
+	gpk, err := typesDKG.NewGroupPublicKey(round,
+		c.gov.DKGMasterPublicKeys(round),
+		c.gov.DKGComplaints(round),
+		utils.GetDKGThreshold(cfg))
+	if err != nil {
+		return nil, err
+	}
+	c.gpks.Add(round, gpk)
+	return gpk, nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+type LightClientTestSuite struct {
+	suite.Suite
+
+	params       types.Params
+	gov          *test.Governance
+	prvKeys      []crypto.PrivateKey
+	signers      []*utils.Signer
+	groupSigners map[uint64]*test.GroupSigner
+}
+
+func (s *LightClientTestSuite) SetupTest() {
+	prvKeys, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	s.params = types.DefaultParams()
+	s.gov, err = test.NewGovernance(test.NewState(s.params, pubKeys,
+		100*time.Millisecond, &common.NullLogger{}, true),
+		s.params.ConfigRoundShift)
+	s.Require().NoError(err)
+	s.prvKeys = prvKeys
+	s.signers = make([]*utils.Signer, 0, len(prvKeys))
+	for _, k := range prvKeys {
+		s.signers = append(s.signers, utils.NewSigner(k))
+	}
+	s.groupSigners = make(map[uint64]*test.GroupSigner)
+}
+
+func (s *LightClientTestSuite) prepareDKG(round uint64) {
+	gs, err := test.PrepareDKG(s.gov, round, s.prvKeys)
+	s.Require().NoError(err)
+	s.groupSigners[round] = gs
+}
+
+func (s *LightClientTestSuite) randomness(
+	round uint64, hash common.Hash) []byte {
+	if round < s.params.DKGDelayRound {
+		return s.params.NoRand
+	}
+	gs, exists := s.groupSigners[round]
+	if !exists {
+		// DKG is not ready, the randomness is never valid.
+		return common.NewRandomHash().Bytes()
+	}
+	sig, err := gs.Sign(hash)
+	s.Require().NoError(err)
+	return sig.Signature
+}
+
+func (s *LightClientTestSuite) newBlockAt(
+	pos types.Position, proposer int) *types.Block {
+	b := &types.Block{
+		ParentHash: common.NewRandomHash(),
+		Position:   pos,
+		Timestamp:  time.Now().UTC(),
+		Payload:    common.NewRandomHash().Bytes(),
+	}
+	if b.Position.Height == types.GenesisHeight {
+		b.ParentHash = common.Hash{}
+	}
+	s.Require().NoError(s.signers[proposer].SignBlock(b))
+	b.Randomness = s.randomness(pos.Round, b.Hash)
+	return b
+}
+
+func (s *LightClientTestSuite) newBlock(
+	parent *types.Block, round uint64, proposer int) *types.Block {
+	if parent == nil {
+		return s.newBlockAt(types.Position{
+			Round: round, Height: types.GenesisHeight}, proposer)
+	}
+	b := s.newBlockAt(types.Position{
+		Round:  round,
+		Height: parent.Position.Height + 1,
+	}, proposer)
+	b.ParentHash = parent.Hash
+	s.Require().NoError(s.signers[proposer].SignBlock(b))
+	b.Randomness = s.randomness(round, b.Hash)
+	return b
+}
+
+func (s *LightClientTestSuite) TestFollow() {
+	c := NewClient(s.gov, s.params, nil, &common.NullLogger{})
+	s.Require().Nil(c.Tip())
+	// Should start from genesis block when no trusted block is provided.
+	genesis := s.newBlock(nil, 0, 0)
+	b := s.newBlock(genesis, 0, 1)
+	s.Equal(ErrNoTrustedBlock, c.Follow(b))
+	s.Require().NoError(c.Follow(genesis))
+	s.Require().NoError(c.Follow(b))
+	s.Equal(b.Hash, c.Tip().Hash)
+	// Blocks not extending the tip.
+	s.Equal(ErrInvalidBlockHeight, c.Follow(b))
+	fork := s.newBlock(genesis, 0, 2)
+	fork.Position.Height++
+	s.Require().NoError(s.signers[2].SignBlock(fork))
+	s.Equal(ErrIncorrectParentHash, c.Follow(fork))
+	for i := 0; i < 5; i++ {
+		next := s.newBlock(b, 0, i%len(s.signers))
+		s.Require().NoError(c.Follow(next))
+		b = next
+	}
+	s.Equal(b.Hash, c.Tip().Hash)
+}
+
+func (s *LightClientTestSuite) TestFollowAcrossRounds() {
+	s.prepareDKG(1)
+	s.gov.CatchUpWithRound(1)
+	beginHeight := s.gov.GetRoundHeight(1)
+	// Start from the block two blocks before round 1.
+	trusted := s.newBlockAt(
+		types.Position{Round: 0, Height: beginHeight - 2}, 0)
+	c := NewClient(s.gov, s.params, trusted, &common.NullLogger{})
+	s.Equal(trusted.Hash, c.Tip().Hash)
+	b := trusted
+	// Round can't be switched before begin height of next round.
+	next := s.newBlock(b, 1, 1)
+	s.Equal(ErrRoundNotSwitch, c.Follow(next))
+	next = s.newBlock(b, 2, 1)
+	s.Equal(ErrInvalidRoundID, c.Follow(next))
+	next = s.newBlock(b, 0, 1)
+	s.Require().NoError(c.Follow(next))
+	b = next
+	// Switch to round 1.
+	next = s.newBlock(b, 1, 2)
+	s.Require().NoError(c.Follow(next))
+	b = next
+	s.Equal(beginHeight, c.Tip().Position.Height)
+	for i := 0; i < 5; i++ {
+		next = s.newBlock(b, 1, i%len(s.signers))
+		s.Require().NoError(c.Follow(next))
+		b = next
+	}
+	// Randomness from round 0 is not accepted in round 1.
+	next = s.newBlock(b, 1, 0)
+	next.Randomness = s.params.NoRand
+	s.Equal(ErrIncorrectRandomness, c.Follow(next))
+	s.Equal(b.Hash, c.Tip().Hash)
+	// Can't go back to round 0.
+	next = s.newBlock(b, 0, 0)
+	s.Equal(ErrInvalidRoundID, c.Follow(next))
+}
+
+func (s *LightClientTestSuite) TestVerifyBlock() {
+	s.prepareDKG(1)
+	c := NewClient(s.gov, s.params, nil, &common.NullLogger{})
+	b := s.newBlockAt(types.Position{Round: 1, Height: 10}, 0)
+	s.Require().NoError(c.VerifyBlock(b))
+	// Payload is not required.
+	b.Payload = nil
+	s.Require().NoError(c.VerifyBlock(b))
+	// Payload should match payload hash when provided.
+	b.Payload = []byte{1, 2, 3}
+	s.Equal(ErrIncorrectBlockHash, c.VerifyBlock(b))
+	b.Payload = nil
+	// Randomness should be signed by group public key of that round.
+	randomness := b.Randomness
+	b.Randomness = s.randomness(1, common.NewRandomHash())
+	s.Equal(ErrIncorrectRandomness, c.VerifyBlock(b))
+	b.Randomness = nil
+	s.Equal(ErrBlockNotFinalized, c.VerifyBlock(b))
+	b.Randomness = randomness
+	// Fields covered by block hash should not be modified.
+	b.Timestamp = b.Timestamp.Add(time.Second)
+	s.Equal(utils.ErrIncorrectHash, c.VerifyBlock(b))
+	// Empty blocks are not signed.
+	empty := &types.Block{
+		ParentHash: b.Hash,
+		Position:   types.Position{Round: 1, Height: 11},
+	}
+	var err error
+	empty.Hash, err = utils.HashBlock(empty)
+	s.Require().NoError(err)
+	empty.Randomness = s.randomness(1, empty.Hash)
+	s.Require().NoError(c.VerifyBlock(empty))
+	empty.ParentHash = common.NewRandomHash()
+	s.Equal(ErrIncorrectBlockHash, c.VerifyBlock(empty))
+	// Proposer should be in notary set.
+	prvKey, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	b = s.newBlockAt(types.Position{Round: 1, Height: 10}, 0)
+	s.Require().NoError(utils.NewSigner(prvKey).SignBlock(b))
+	b.Randomness = s.randomness(1, b.Hash)
+	s.Equal(ErrProposerNotInNotarySet, c.VerifyBlock(b))
+	// DKG of round 2 is not ready.
+	empty.ParentHash = b.Hash
+	empty.Position.Round = 2
+	empty.Hash, err = utils.HashBlock(empty)
+	s.Require().NoError(err)
+	empty.Randomness = s.randomness(1, empty.Hash)
+	s.Equal(ErrDKGNotReady, c.VerifyBlock(empty))
+}
+
+func TestLightClient(t *testing.T) {
+	suite.Run(t, new(LightClientTestSuite))
+}
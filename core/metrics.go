@@ -0,0 +1,125 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// Names of internal queues reported via Metrics.ReportQueueDepth.
+const (
+	QueueMsg         = "msg"
+	QueuePriorityMsg = "priority-msg"
+	QueueApp         = "app"
+)
+
+// NopMetrics implements Metrics interface and drops everything reported.
+type NopMetrics struct{}
+
+// ObserveBAPeriod implements Metrics interface.
+func (m NopMetrics) ObserveBAPeriod(types.Position, uint64) {}
+
+// ObserveBAConfirmLatency implements Metrics interface.
+func (m NopMetrics) ObserveBAConfirmLatency(types.Position, time.Duration) {}
+
+// ReportBAConfirmPath implements Metrics interface.
+func (m NopMetrics) ReportBAConfirmPath(types.Position, bool) {}
+
+// ObserveDKGPhase implements Metrics interface.
+func (m NopMetrics) ObserveDKGPhase(uint64, uint64, int, time.Duration) {}
+
+// ReportDKGReset implements Metrics interface.
+func (m NopMetrics) ReportDKGReset(uint64, uint64) {}
+
+// ObserveRandomnessLatency implements Metrics interface.
+func (m NopMetrics) ObserveRandomnessLatency(types.Position, time.Duration) {}
+
+// ReportQueueDepth implements Metrics interface.
+func (m NopMetrics) ReportQueueDepth(string, int) {}
+
+// metricsHolder wraps Metrics to be stored in atomic.Value, which requires
+// values of the same concrete type.
+type metricsHolder struct {
+	Metrics
+}
+
+// metricsProxy implements Metrics interface and forwards everything to the
+// Metrics instance stored in it. Modules keep a reference to the same proxy
+// since construction, so the underlying instance could be replaced while they
+// are running.
+type metricsProxy struct {
+	v atomic.Value
+}
+
+func newMetricsProxy() *metricsProxy {
+	p := &metricsProxy{}
+	p.set(NopMetrics{})
+	return p
+}
+
+func (p *metricsProxy) set(metrics Metrics) {
+	if metrics == nil {
+		metrics = NopMetrics{}
+	}
+	p.v.Store(metricsHolder{metrics})
+}
+
+func (p *metricsProxy) get() Metrics {
+	return p.v.Load().(metricsHolder).Metrics
+}
+
+// ObserveBAPeriod implements Metrics interface.
+func (p *metricsProxy) ObserveBAPeriod(pos types.Position, period uint64) {
+	p.get().ObserveBAPeriod(pos, period)
+}
+
+// ObserveBAConfirmLatency implements Metrics interface.
+func (p *metricsProxy) ObserveBAConfirmLatency(
+	pos types.Position, elapsed time.Duration) {
+	p.get().ObserveBAConfirmLatency(pos, elapsed)
+}
+
+// ReportBAConfirmPath implements Metrics interface.
+func (p *metricsProxy) ReportBAConfirmPath(pos types.Position, fast bool) {
+	p.get().ReportBAConfirmPath(pos, fast)
+}
+
+// ObserveDKGPhase implements Metrics interface.
+func (p *metricsProxy) ObserveDKGPhase(
+	round, reset uint64, phase int, elapsed time.Duration) {
+	p.get().ObserveDKGPhase(round, reset, phase, elapsed)
+}
+
+// ReportDKGReset implements Metrics interface.
+func (p *metricsProxy) ReportDKGReset(round, reset uint64) {
+	p.get().ReportDKGReset(round, reset)
+}
+
+// ObserveRandomnessLatency implements Metrics interface.
+func (p *metricsProxy) ObserveRandomnessLatency(
+	pos types.Position, elapsed time.Duration) {
+	p.get().ObserveRandomnessLatency(pos, elapsed)
+}
+
+// ReportQueueDepth implements Metrics interface.
+func (p *metricsProxy) ReportQueueDepth(queue string, depth int) {
+	p.get().ReportQueueDepth(queue, depth)
+}
@@ -0,0 +1,285 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// ContentType is the content type of Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	latencyBuckets = []float64{
+		0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	dkgPhaseBuckets = []float64{
+		0.1, 0.5, 1, 5, 10, 30, 60, 300, 600, 1800}
+	periodBuckets = []float64{2, 3, 4, 5, 6, 8, 10, 15, 20}
+)
+
+// histogram is a cumulative histogram in Prometheus style.
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(buf *bytes.Buffer, name, labels string) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	for i, upper := range h.buckets {
+		fmt.Fprintf(buf, "%s_bucket{%s%sle=\"%s\"} %d\n",
+			name, labels, sep, formatFloat(upper), h.counts[i])
+	}
+	fmt.Fprintf(buf, "%s_bucket{%s%sle=\"+Inf\"} %d\n",
+		name, labels, sep, h.count)
+	fmt.Fprintf(buf, "%s_sum%s %s\n",
+		name, wrapLabels(labels), formatFloat(h.sum))
+	fmt.Fprintf(buf, "%s_count%s %d\n", name, wrapLabels(labels), h.count)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func wrapLabels(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// Prometheus implements core.Metrics interface and exposes collected
+// statistics in Prometheus text exposition format via http.Handler interface.
+type Prometheus struct {
+	lock             sync.Mutex
+	namespace        string
+	baPeriod         *histogram
+	baConfirmLatency *histogram
+	baFastConfirm    uint64
+	baSlowConfirm    uint64
+	baHeight         uint64
+	dkgPhase         map[int]*histogram
+	dkgReset         uint64
+	dkgResetRound    uint64
+	dkgResetCount    uint64
+	randLatency      *histogram
+	queueDepth       map[string]int
+}
+
+// NewPrometheus constructs a Prometheus instance. The namespace would be
+// prepended to the name of all exposed metrics.
+func NewPrometheus(namespace string) *Prometheus {
+	return &Prometheus{
+		namespace:        namespace,
+		baPeriod:         newHistogram(periodBuckets),
+		baConfirmLatency: newHistogram(latencyBuckets),
+		dkgPhase:         make(map[int]*histogram),
+		randLatency:      newHistogram(latencyBuckets),
+		queueDepth:       make(map[string]int),
+	}
+}
+
+// ObserveBAPeriod implements core.Metrics interface.
+func (m *Prometheus) ObserveBAPeriod(pos types.Position, period uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.baPeriod.observe(float64(period))
+	if pos.Height > m.baHeight {
+		m.baHeight = pos.Height
+	}
+}
+
+// ObserveBAConfirmLatency implements core.Metrics interface.
+func (m *Prometheus) ObserveBAConfirmLatency(
+	_ types.Position, elapsed time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.baConfirmLatency.observe(elapsed.Seconds())
+}
+
+// ReportBAConfirmPath implements core.Metrics interface.
+func (m *Prometheus) ReportBAConfirmPath(_ types.Position, fast bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if fast {
+		m.baFastConfirm++
+	} else {
+		m.baSlowConfirm++
+	}
+}
+
+// ObserveDKGPhase implements core.Metrics interface.
+func (m *Prometheus) ObserveDKGPhase(
+	_, _ uint64, phase int, elapsed time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	h, exist := m.dkgPhase[phase]
+	if !exist {
+		h = newHistogram(dkgPhaseBuckets)
+		m.dkgPhase[phase] = h
+	}
+	h.observe(elapsed.Seconds())
+}
+
+// ReportDKGReset implements core.Metrics interface.
+func (m *Prometheus) ReportDKGReset(round, reset uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.dkgReset++
+	if round >= m.dkgResetRound {
+		m.dkgResetRound, m.dkgResetCount = round, reset
+	}
+}
+
+// ObserveRandomnessLatency implements core.Metrics interface.
+func (m *Prometheus) ObserveRandomnessLatency(
+	_ types.Position, elapsed time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.randLatency.observe(elapsed.Seconds())
+}
+
+// ReportQueueDepth implements core.Metrics interface.
+func (m *Prometheus) ReportQueueDepth(queue string, depth int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.queueDepth[queue] = depth
+}
+
+func (m *Prometheus) name(name string) string {
+	if m.namespace == "" {
+		return name
+	}
+	return m.namespace + "_" + name
+}
+
+func writeHeader(buf *bytes.Buffer, name, typ, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// Expose dumps all collected statistics in Prometheus text exposition format.
+func (m *Prometheus) Expose() []byte {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	buf := &bytes.Buffer{}
+	// BA related.
+	name := m.name("ba_period")
+	writeHeader(buf, name, "histogram",
+		"The period at which BA confirms a block.")
+	m.baPeriod.write(buf, name, "")
+	name = m.name("ba_height")
+	writeHeader(buf, name, "gauge", "The latest height confirmed by BA.")
+	fmt.Fprintf(buf, "%s %d\n", name, m.baHeight)
+	name = m.name("ba_confirm_latency_seconds")
+	writeHeader(buf, name, "histogram",
+		"Time from proposal to confirmation of a block.")
+	m.baConfirmLatency.write(buf, name, "")
+	name = m.name("ba_confirm_total")
+	writeHeader(buf, name, "counter",
+		"Count of blocks confirmed by BA via fast or slow path.")
+	fmt.Fprintf(buf, "%s{path=\"fast\"} %d\n", name, m.baFastConfirm)
+	fmt.Fprintf(buf, "%s{path=\"slow\"} %d\n", name, m.baSlowConfirm)
+	// DKG related.
+	name = m.name("dkg_phase_duration_seconds")
+	writeHeader(buf, name, "histogram", "Time spent in each DKG phase.")
+	phases := make([]int, 0, len(m.dkgPhase))
+	for phase := range m.dkgPhase {
+		phases = append(phases, phase)
+	}
+	sort.Ints(phases)
+	for _, phase := range phases {
+		m.dkgPhase[phase].write(buf, name, fmt.Sprintf("phase=\"%d\"", phase))
+	}
+	name = m.name("dkg_reset_total")
+	writeHeader(buf, name, "counter", "Count of DKG resets.")
+	fmt.Fprintf(buf, "%s %d\n", name, m.dkgReset)
+	name = m.name("dkg_reset_count")
+	writeHeader(buf, name, "gauge",
+		"The reset count of DKG of the latest reset round.")
+	fmt.Fprintf(buf, "%s{round=\"%d\"} %d\n",
+		name, m.dkgResetRound, m.dkgResetCount)
+	// Randomness related.
+	name = m.name("randomness_latency_seconds")
+	writeHeader(buf, name, "histogram",
+		"Time from confirmation of a block to readiness of its randomness.")
+	m.randLatency.write(buf, name, "")
+	// Queues.
+	name = m.name("queue_depth")
+	writeHeader(buf, name, "gauge", "Count of pending items in a queue.")
+	queues := make([]string, 0, len(m.queueDepth))
+	for queue := range m.queueDepth {
+		queues = append(queues, queue)
+	}
+	sort.Strings(queues)
+	for _, queue := range queues {
+		fmt.Fprintf(buf, "%s{queue=\"%s\"} %d\n",
+			name, queue, m.queueDepth[queue])
+	}
+	return buf.Bytes()
+}
+
+// ServeHTTP implements http.Handler interface.
+func (m *Prometheus) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Write(m.Expose())
+}
+
+// ListenAndServe serves collected statistics at "/metrics" on the given
+// address, it blocks until the server is stopped.
+func (m *Prometheus) ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	return http.ListenAndServe(addr, mux)
+}
+
+// Make sure Prometheus implements core.Metrics interface.
+var _ core.Metrics = (*Prometheus)(nil)
@@ -0,0 +1,104 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+type PrometheusTestSuite struct {
+	suite.Suite
+}
+
+func (s *PrometheusTestSuite) TestExpose() {
+	m := NewPrometheus("dexcon")
+	pos := types.Position{Round: 1, Height: 10}
+	m.ObserveBAPeriod(pos, 2)
+	m.ObserveBAPeriod(pos, 5)
+	m.ObserveBAConfirmLatency(pos, 200*time.Millisecond)
+	m.ReportBAConfirmPath(pos, true)
+	m.ReportBAConfirmPath(pos, true)
+	m.ReportBAConfirmPath(pos, false)
+	m.ObserveDKGPhase(2, 0, 3, 2*time.Second)
+	m.ObserveDKGPhase(2, 0, 1, 20*time.Millisecond)
+	m.ReportDKGReset(2, 1)
+	m.ObserveRandomnessLatency(pos, time.Second)
+	m.ReportQueueDepth(core.QueueMsg, 7)
+	m.ReportQueueDepth(core.QueuePriorityMsg, 1)
+	out := string(m.Expose())
+	for _, line := range []string{
+		"# TYPE dexcon_ba_period histogram",
+		`dexcon_ba_period_bucket{le="2"} 1`,
+		`dexcon_ba_period_bucket{le="5"} 2`,
+		`dexcon_ba_period_bucket{le="+Inf"} 2`,
+		"dexcon_ba_period_sum 7",
+		"dexcon_ba_period_count 2",
+		"dexcon_ba_height 10",
+		`dexcon_ba_confirm_latency_seconds_bucket{le="0.1"} 0`,
+		`dexcon_ba_confirm_latency_seconds_bucket{le="0.25"} 1`,
+		`dexcon_ba_confirm_total{path="fast"} 2`,
+		`dexcon_ba_confirm_total{path="slow"} 1`,
+		`dexcon_dkg_phase_duration_seconds_bucket{phase="1",le="0.1"} 1`,
+		`dexcon_dkg_phase_duration_seconds_bucket{phase="3",le="1"} 0`,
+		`dexcon_dkg_phase_duration_seconds_count{phase="3"} 1`,
+		"dexcon_dkg_reset_total 1",
+		`dexcon_dkg_reset_count{round="2"} 1`,
+		`dexcon_randomness_latency_seconds_sum 1`,
+		`dexcon_queue_depth{queue="msg"} 7`,
+		`dexcon_queue_depth{queue="priority-msg"} 1`,
+	} {
+		s.Require().Contains(out, line+"\n")
+	}
+	// Phases should be sorted.
+	s.Require().True(strings.Index(out, `phase="1"`) <
+		strings.Index(out, `phase="3"`))
+}
+
+func (s *PrometheusTestSuite) TestServeHTTP() {
+	m := NewPrometheus("")
+	m.ReportDKGReset(3, 2)
+	server := httptest.NewServer(m)
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Equal(ContentType, resp.Header.Get("Content-Type"))
+	body, err := ioutil.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Require().Contains(string(body), "dkg_reset_total 1\n")
+	// Only GET and HEAD are allowed.
+	resp, err = http.Post(server.URL, "text/plain", nil)
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Require().Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestPrometheus(t *testing.T) {
+	suite.Run(t, new(PrometheusTestSuite))
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+}
+
+func (s *MetricsTestSuite) TestProxy() {
+	var (
+		proxy    = newMetricsProxy()
+		recorder = &depthRecorder{maxDepth: make(map[string]int)}
+		wg       sync.WaitGroup
+	)
+	// Reporting via proxy is safe while the underlying instance is replaced.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			proxy.ReportQueueDepth(QueueMsg, i)
+		}
+	}()
+	proxy.set(recorder)
+	wg.Wait()
+	proxy.ReportQueueDepth(QueueApp, 1)
+	recorder.lock.Lock()
+	s.Equal(1, recorder.maxDepth[QueueApp])
+	recorder.lock.Unlock()
+	// Setting nil falls back to NopMetrics.
+	proxy.set(nil)
+	proxy.ReportQueueDepth(QueueApp, 2)
+	recorder.lock.Lock()
+	s.Equal(1, recorder.maxDepth[QueueApp])
+	recorder.lock.Unlock()
+}
+
+func TestMetrics(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
@@ -20,11 +20,43 @@ package core
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/dexon-foundation/dexon-consensus/common"
 	"github.com/dexon-foundation/dexon-consensus/core/types"
 )
 
+// DefaultAppQueueSize is the default count of events to application that
+// could be queued.
+const DefaultAppQueueSize = 1024
+
+// AppQueuePolicy decides how to react when the application could not consume
+// BlockConfirmed/BlockDelivered events in time.
+type AppQueuePolicy int
+
+// AppQueuePolicy enum.
+const (
+	// AppQueueBlock blocks the consensus core when the queue is full, no block
+	// would be proposed until the application catches up. The wait happens
+	// while locks of consensus core are held, ex. the lock of block chain when
+	// delivering blocks, so other routines needing those locks, like the one
+	// processing incoming messages, would be blocked, too.
+	AppQueueBlock AppQueuePolicy = iota
+	// AppQueueThrottle extends the interval to propose next block when the
+	// queue is more than half full, and blocks like AppQueueBlock when the
+	// queue is full.
+	AppQueueThrottle
+	// AppQueueFailFast never blocks the consensus core. When the queue is
+	// full, the event is still queued and Consensus stops with
+	// ErrAppQueueFull, which could be read from Consensus.Err. No event would
+	// be dropped.
+	AppQueueFailFast
+)
+
+// maxThrottleRatio is the ratio of extended interval to MinBlockInterval
+// when the queue is full under AppQueueThrottle policy.
+const maxThrottleRatio = 10
+
 type blockConfirmedEvent struct {
 	block *types.Block
 }
@@ -47,6 +79,11 @@ type nonBlocking struct {
 	events       []interface{}
 	eventsChange *sync.Cond
 	running      sync.WaitGroup
+	capacity     int
+	policy       AppQueuePolicy
+	metrics      Metrics
+	err          error
+	onFatal      func(error)
 }
 
 func newNonBlocking(app Application, debug Debug) *nonBlocking {
@@ -56,18 +93,88 @@ func newNonBlocking(app Application, debug Debug) *nonBlocking {
 		eventChan:    make(chan interface{}, 6),
 		events:       make([]interface{}, 0, 100),
 		eventsChange: sync.NewCond(&sync.Mutex{}),
+		capacity:     DefaultAppQueueSize,
+		policy:       AppQueueBlock,
+		metrics:      NopMetrics{},
 	}
 	go nonBlockingModule.run()
 	return nonBlockingModule
 }
 
+// setQueuePolicy changes the capacity of queue and the policy when it's full.
+func (nb *nonBlocking) setQueuePolicy(capacity int, policy AppQueuePolicy) {
+	if capacity <= 0 {
+		capacity = DefaultAppQueueSize
+	}
+	nb.eventsChange.L.Lock()
+	defer nb.eventsChange.L.Unlock()
+	nb.capacity = capacity
+	nb.policy = policy
+	nb.eventsChange.Broadcast()
+}
+
+func (nb *nonBlocking) setMetrics(metrics Metrics) {
+	nb.eventsChange.L.Lock()
+	defer nb.eventsChange.L.Unlock()
+	nb.metrics = metrics
+}
+
+// queueDepth returns the count of events not consumed by application yet.
+func (nb *nonBlocking) queueDepth() int {
+	nb.eventsChange.L.Lock()
+	defer nb.eventsChange.L.Unlock()
+	return len(nb.events)
+}
+
+// setFatalHandler sets the handler to be called once, in another go routine,
+// when the queue is full under AppQueueFailFast policy.
+func (nb *nonBlocking) setFatalHandler(handler func(error)) {
+	nb.eventsChange.L.Lock()
+	defer nb.eventsChange.L.Unlock()
+	nb.onFatal = handler
+}
+
+// throttle returns the extra time to wait before proposing next block.
+func (nb *nonBlocking) throttle(interval time.Duration) time.Duration {
+	nb.eventsChange.L.Lock()
+	defer nb.eventsChange.L.Unlock()
+	half := nb.capacity / 2
+	if nb.policy != AppQueueThrottle || len(nb.events) <= half {
+		return 0
+	}
+	return interval * time.Duration(
+		maxThrottleRatio*(len(nb.events)-half)) / time.Duration(
+		nb.capacity-half)
+}
+
 func (nb *nonBlocking) addEvent(event interface{}) {
 	nb.eventsChange.L.Lock()
 	defer nb.eventsChange.L.Unlock()
+	for len(nb.events) >= nb.capacity {
+		if nb.policy == AppQueueFailFast {
+			nb.fail(ErrAppQueueFull)
+			break
+		}
+		nb.eventsChange.Wait()
+	}
 	nb.events = append(nb.events, event)
+	nb.metrics.ReportQueueDepth(QueueApp, len(nb.events))
 	nb.eventsChange.Broadcast()
 }
 
+// fail records the first fatal error and reports it to the handler. The
+// caller may hold locks of consensus core, so the handler is called in another
+// go routine. It should be called with eventsChange.L held.
+func (nb *nonBlocking) fail(err error) {
+	if nb.err != nil {
+		return
+	}
+	nb.err = err
+	if nb.onFatal != nil {
+		go nb.onFatal(err)
+	}
+}
+
 func (nb *nonBlocking) run() {
 	// This go routine consume the first event from events and call the
 	// corresponding methods of Application/Debug/db.
@@ -80,7 +187,9 @@ func (nb *nonBlocking) run() {
 				nb.eventsChange.Wait()
 			}
 			event = nb.events[0]
+			nb.events[0] = nil
 			nb.events = nb.events[1:]
+			nb.metrics.ReportQueueDepth(QueueApp, len(nb.events))
 			nb.running.Add(1)
 		}()
 		switch e := event.(type) {
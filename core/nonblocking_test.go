@@ -18,6 +18,7 @@
 package core
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -104,6 +105,46 @@ func (app *noDebugApp) BlockDelivered(blockHash common.Hash,
 	app.blockDelivered[blockHash] = struct{}{}
 }
 
+// gateApp is an Application instance blocks in BlockConfirmed and
+// BlockDelivered until the gate is opened.
+type gateApp struct {
+	noDebugApp
+	gate chan struct{}
+}
+
+func newGateApp() *gateApp {
+	return &gateApp{
+		noDebugApp: *newNoDebugApp(),
+		gate:       make(chan struct{}),
+	}
+}
+
+func (app *gateApp) BlockConfirmed(block types.Block) {
+	<-app.gate
+	app.noDebugApp.BlockConfirmed(block)
+}
+
+func (app *gateApp) BlockDelivered(blockHash common.Hash,
+	blockPosition types.Position, rand []byte) {
+	<-app.gate
+	app.noDebugApp.BlockDelivered(blockHash, blockPosition, rand)
+}
+
+// depthRecorder is a Metrics instance records the max depth of queues.
+type depthRecorder struct {
+	NopMetrics
+	lock     sync.Mutex
+	maxDepth map[string]int
+}
+
+func (m *depthRecorder) ReportQueueDepth(queue string, depth int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if depth > m.maxDepth[queue] {
+		m.maxDepth[queue] = depth
+	}
+}
+
 type NonBlockingTestSuite struct {
 	suite.Suite
 }
@@ -155,6 +196,97 @@ func (s *NonBlockingTestSuite) TestNoDebug() {
 	s.Panics(func() { nbModule.VerifyBlock(nil) })
 }
 
+func (s *NonBlockingTestSuite) waitQueueDepth(nb *nonBlocking, depth int) {
+	for nb.queueDepth() != depth {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (s *NonBlockingTestSuite) TestBoundedQueue() {
+	var (
+		sleep    = 10 * time.Millisecond
+		capacity = 5
+		count    = 30
+		app      = newSlowApp(sleep)
+		nbModule = newNonBlocking(app, app)
+		metrics  = &depthRecorder{maxDepth: make(map[string]int)}
+	)
+	nbModule.setQueuePolicy(capacity, AppQueueBlock)
+	nbModule.setMetrics(metrics)
+	hashes := make(common.Hashes, count)
+	for idx := range hashes {
+		hashes[idx] = common.NewRandomHash()
+	}
+	begin := time.Now()
+	for _, hash := range hashes {
+		// Simulate blocks with big payload.
+		nbModule.BlockConfirmed(types.Block{
+			Hash:    hash,
+			Payload: make([]byte, 1024*1024),
+		})
+		s.Require().True(nbModule.queueDepth() <= capacity)
+	}
+	// The caller should be blocked until the application consumes enough
+	// events.
+	s.Require().True(
+		time.Since(begin) >= time.Duration(count-capacity-1)*sleep)
+	nbModule.wait()
+	s.Require().Equal(capacity, metrics.maxDepth[QueueApp])
+	s.Require().Len(app.blockConfirmed, count)
+}
+
+func (s *NonBlockingTestSuite) TestFailFast() {
+	app := newGateApp()
+	nbModule := newNonBlocking(app, nil)
+	errs := make(chan error, 10)
+	nbModule.setFatalHandler(func(err error) { errs <- err })
+	nbModule.setQueuePolicy(1, AppQueueFailFast)
+	// The first event is consumed and blocked in application.
+	nbModule.BlockConfirmed(types.Block{Hash: common.NewRandomHash()})
+	s.waitQueueDepth(nbModule, 0)
+	nbModule.BlockDelivered(common.NewRandomHash(), types.Position{}, nil)
+	// The queue is full, these events are still queued without blocking, and
+	// the error is reported only once.
+	for i := 0; i < 3; i++ {
+		nbModule.BlockDelivered(common.NewRandomHash(), types.Position{}, nil)
+	}
+	s.Require().Equal(ErrAppQueueFull, <-errs)
+	s.Require().Equal(4, nbModule.queueDepth())
+	close(app.gate)
+	nbModule.wait()
+	s.Require().Len(app.blockConfirmed, 1)
+	s.Require().Len(app.blockDelivered, 4)
+	s.Require().Len(errs, 0)
+}
+
+func (s *NonBlockingTestSuite) TestThrottle() {
+	interval := 100 * time.Millisecond
+	app := newGateApp()
+	nbModule := newNonBlocking(app, nil)
+	nbModule.setQueuePolicy(11, AppQueueThrottle)
+	// The first event is consumed and blocked in application.
+	nbModule.BlockConfirmed(types.Block{Hash: common.NewRandomHash()})
+	s.waitQueueDepth(nbModule, 0)
+	addEvents := func(count int) {
+		for i := 0; i < count; i++ {
+			nbModule.BlockConfirmed(types.Block{Hash: common.NewRandomHash()})
+		}
+	}
+	// No throttle when the queue is less than half full.
+	addEvents(5)
+	s.Require().Equal(time.Duration(0), nbModule.throttle(interval))
+	addEvents(3)
+	s.Require().Equal(5*interval, nbModule.throttle(interval))
+	addEvents(3)
+	s.Require().Equal(maxThrottleRatio*interval, nbModule.throttle(interval))
+	// Other policy won't throttle.
+	nbModule.setQueuePolicy(11, AppQueueBlock)
+	s.Require().Equal(time.Duration(0), nbModule.throttle(interval))
+	close(app.gate)
+	nbModule.wait()
+	s.Require().Len(app.blockConfirmed, 12)
+}
+
 func TestNonBlocking(t *testing.T) {
 	suite.Run(t, new(NonBlockingTestSuite))
 }
@@ -0,0 +1,155 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+// Package recovery implements core.Recovery with votes kept in a store shared
+// by nodes, without relying on any governance contract. It's designed for
+// tests and devnets whose nodes could reach the same storage.
+package recovery
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// Errors for recovery.
+var (
+	ErrEmptyVoter = errors.New("empty voter")
+)
+
+// Store keeps votes to skip blocks, it should be shared by all nodes and be
+// safe for concurrent use.
+type Store interface {
+	// AddVote records a vote from a voter to skip the block at a height, a
+	// voter is counted once for each height.
+	AddVote(height uint64, voter types.NodeID) error
+
+	// Votes returns the count of voters for a height.
+	Votes(height uint64) (uint64, error)
+}
+
+// Recovery implements core.Recovery for one node.
+type Recovery struct {
+	store Store
+	voter types.NodeID
+}
+
+// NewRecovery creates a Recovery voting as voter in store.
+func NewRecovery(store Store, voter types.NodeID) *Recovery {
+	return &Recovery{
+		store: store,
+		voter: voter,
+	}
+}
+
+// ProposeSkipBlock implements core.Recovery interface.
+func (r *Recovery) ProposeSkipBlock(height uint64) error {
+	if (r.voter == types.NodeID{}) {
+		return ErrEmptyVoter
+	}
+	return r.store.AddVote(height, r.voter)
+}
+
+// Votes implements core.Recovery interface.
+func (r *Recovery) Votes(height uint64) (uint64, error) {
+	return r.store.Votes(height)
+}
+
+// MemStore is a Store kept in memory, it could be shared by nodes in the same
+// process.
+type MemStore struct {
+	lock  sync.RWMutex
+	votes map[uint64]map[types.NodeID]struct{}
+}
+
+// NewMemStore creates a MemStore.
+func NewMemStore() *MemStore {
+	return &MemStore{
+		votes: make(map[uint64]map[types.NodeID]struct{}),
+	}
+}
+
+// AddVote implements Store interface.
+func (s *MemStore) AddVote(height uint64, voter types.NodeID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	voters, exists := s.votes[height]
+	if !exists {
+		voters = make(map[types.NodeID]struct{})
+		s.votes[height] = voters
+	}
+	voters[voter] = struct{}{}
+	return nil
+}
+
+// Votes implements Store interface.
+func (s *MemStore) Votes(height uint64) (uint64, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return uint64(len(s.votes[height])), nil
+}
+
+// DirStore is a Store kept in a directory, it could be shared by nodes on the
+// same host or a shared file system. Each vote is an empty file named by the
+// voter under the directory of its height, so no locking is required among
+// processes.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore creates a DirStore in dir, which would be created if not exists.
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+func (s *DirStore) heightDir(height uint64) string {
+	return filepath.Join(s.dir, strconv.FormatUint(height, 10))
+}
+
+// AddVote implements Store interface.
+func (s *DirStore) AddVote(height uint64, voter types.NodeID) error {
+	dir := s.heightDir(height)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, voter.Hash.String()),
+		os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Votes implements Store interface.
+func (s *DirStore) Votes(height uint64) (uint64, error) {
+	files, err := ioutil.ReadDir(s.heightDir(height))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return uint64(len(files)), nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package recovery
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+type RecoveryTestSuite struct {
+	suite.Suite
+}
+
+func (s *RecoveryTestSuite) newVoters(count int) []types.NodeID {
+	voters := make([]types.NodeID, 0, count)
+	for i := 0; i < count; i++ {
+		voters = append(voters, types.NodeID{Hash: common.NewRandomHash()})
+	}
+	return voters
+}
+
+// testStore checks votes are shared among recoveries backed by stores.
+func (s *RecoveryTestSuite) testStore(stores ...Store) {
+	voters := s.newVoters(4)
+	recs := make([]*Recovery, 0, len(voters))
+	for i, v := range voters {
+		recs = append(recs, NewRecovery(stores[i%len(stores)], v))
+	}
+	for _, r := range recs {
+		votes, err := r.Votes(10)
+		s.Require().NoError(err)
+		s.Require().Zero(votes)
+	}
+	for i, r := range recs[:3] {
+		s.Require().NoError(r.ProposeSkipBlock(10))
+		// A voter is counted once.
+		s.Require().NoError(r.ProposeSkipBlock(10))
+		for _, other := range recs {
+			votes, err := other.Votes(10)
+			s.Require().NoError(err)
+			s.Require().Equal(uint64(i+1), votes)
+		}
+	}
+	// Votes of heights are separated.
+	s.Require().NoError(recs[3].ProposeSkipBlock(11))
+	votes, err := recs[0].Votes(11)
+	s.Require().NoError(err)
+	s.Require().Equal(uint64(1), votes)
+	votes, err = recs[0].Votes(10)
+	s.Require().NoError(err)
+	s.Require().Equal(uint64(3), votes)
+	s.Require().Equal(ErrEmptyVoter,
+		NewRecovery(stores[0], types.NodeID{}).ProposeSkipBlock(10))
+}
+
+func (s *RecoveryTestSuite) TestMemStore() {
+	s.testStore(NewMemStore())
+}
+
+func (s *RecoveryTestSuite) TestDirStore() {
+	dir, err := ioutil.TempDir("", "dexon-recovery")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	// Stores opened on the same directory behave like those in different
+	// processes.
+	store1, err := NewDirStore(dir)
+	s.Require().NoError(err)
+	store2, err := NewDirStore(dir)
+	s.Require().NoError(err)
+	s.testStore(store1, store2)
+}
+
+func TestRecovery(t *testing.T) {
+	suite.Run(t, new(RecoveryTestSuite))
+}
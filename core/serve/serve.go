@@ -0,0 +1,291 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+// Package serve answers pull requests from peers with blocks stored in
+// db.Database and votes cached by the agreement manager. Requests are rate
+// limited per peer and replies are capped in size.
+package serve
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// Errors for responder.
+var (
+	ErrRateLimited = errors.New(
+		"rate limited")
+	ErrEmptyRequest = errors.New(
+		"empty request")
+	ErrInvalidRange = errors.New(
+		"invalid range")
+)
+
+// Network is the interface used by responder to reply peers.
+type Network interface {
+	// SendBlocks sends blocks to a peer.
+	SendBlocks(peer types.NodeID, blocks []*types.Block)
+
+	// SendVotes sends votes to a peer.
+	SendVotes(peer types.NodeID, votes []*types.Vote)
+}
+
+// VoteCache is the source of in-flight votes, it's implemented by
+// core.Consensus.
+type VoteCache interface {
+	// GetVotes returns votes of a position.
+	GetVotes(pos types.Position) []*types.Vote
+}
+
+// Config is the configuration of responder. Fields not greater than zero are
+// replaced by those from DefaultConfig.
+type Config struct {
+	// MaxBlocks is the maximum count of blocks in one reply.
+	MaxBlocks int
+
+	// MaxVotes is the maximum count of votes in one reply.
+	MaxVotes int
+
+	// MaxPayloadSize is the maximum sum of payload size of blocks in one
+	// reply. At least one block would be sent even if its payload exceeds
+	// the limit.
+	MaxPayloadSize int
+
+	// RequestsPerSecond is the rate of requests allowed from one peer.
+	RequestsPerSecond float64
+
+	// Burst is the count of requests allowed from one peer at once.
+	Burst int
+
+	// MaxPeers is the count of peers to track for rate limiting, the least
+	// recently seen peer is forgotten when exceeded.
+	MaxPeers int
+}
+
+// DefaultConfig returns the default configuration of responder.
+func DefaultConfig() Config {
+	return Config{
+		MaxBlocks:         128,
+		MaxVotes:          1024,
+		MaxPayloadSize:    4 * 1024 * 1024,
+		RequestsPerSecond: 10,
+		Burst:             20,
+		MaxPeers:          1024,
+	}
+}
+
+// withDefaults returns a copy of config with non-positive fields replaced by
+// default values.
+func (config Config) withDefaults() Config {
+	def := DefaultConfig()
+	if config.MaxBlocks <= 0 {
+		config.MaxBlocks = def.MaxBlocks
+	}
+	if config.MaxVotes <= 0 {
+		config.MaxVotes = def.MaxVotes
+	}
+	if config.MaxPayloadSize <= 0 {
+		config.MaxPayloadSize = def.MaxPayloadSize
+	}
+	if config.RequestsPerSecond <= 0 {
+		config.RequestsPerSecond = def.RequestsPerSecond
+	}
+	if config.Burst <= 0 {
+		config.Burst = def.Burst
+	}
+	if config.MaxPeers <= 0 {
+		config.MaxPeers = def.MaxPeers
+	}
+	return config
+}
+
+// bucket is a token bucket to limit requests from one peer.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Responder answers pull requests from peers.
+type Responder struct {
+	db        db.Reader
+	votes     VoteCache
+	network   Network
+	config    Config
+	logger    common.Logger
+	lock      sync.Mutex
+	buckets   *lru.Cache
+	timeNowFn func() time.Time
+}
+
+// NewResponder creates a responder, votes could be nil when the node doesn't
+// run agreement.
+func NewResponder(dbInst db.Reader, votes VoteCache, network Network,
+	config Config, logger common.Logger) *Responder {
+	config = config.withDefaults()
+	buckets, err := lru.New(config.MaxPeers)
+	if err != nil {
+		panic(err)
+	}
+	return &Responder{
+		db:        dbInst,
+		votes:     votes,
+		network:   network,
+		config:    config,
+		logger:    logger,
+		buckets:   buckets,
+		timeNowFn: time.Now,
+	}
+}
+
+// HandleBlockRequest replies blocks of hashes to a peer, unknown hashes are
+// ignored. Only the first MaxBlocks hashes are looked up.
+func (r *Responder) HandleBlockRequest(
+	peer types.NodeID, hashes common.Hashes) error {
+	if len(hashes) == 0 {
+		return ErrEmptyRequest
+	}
+	if !r.allow(peer) {
+		return ErrRateLimited
+	}
+	if len(hashes) > r.config.MaxBlocks {
+		hashes = hashes[:r.config.MaxBlocks]
+	}
+	blocks := []*types.Block{}
+	size := 0
+	for _, h := range hashes {
+		b, err := r.db.GetBlock(h)
+		if err != nil {
+			if err != db.ErrBlockDoesNotExist {
+				return err
+			}
+			continue
+		}
+		if !r.fit(len(blocks), &size, &b) {
+			break
+		}
+		blocks = append(blocks, &b)
+	}
+	r.sendBlocks(peer, blocks)
+	return nil
+}
+
+// HandleBlockRangeRequest replies blocks with heights in [from, to) to a peer,
+// in ascending order of height. The reply is truncated at the size caps, the
+// peer could request again from the height after the last block received.
+func (r *Responder) HandleBlockRangeRequest(
+	peer types.NodeID, from, to uint64) error {
+	if from >= to {
+		return ErrInvalidRange
+	}
+	if !r.allow(peer) {
+		return ErrRateLimited
+	}
+	iter, err := r.db.GetBlocksInRange(from, to)
+	if err != nil {
+		return err
+	}
+	blocks := []*types.Block{}
+	size := 0
+	for len(blocks) < r.config.MaxBlocks {
+		b, err := iter.NextBlock()
+		if err != nil {
+			if err == db.ErrIterationFinished {
+				break
+			}
+			return err
+		}
+		if !r.fit(len(blocks), &size, &b) {
+			break
+		}
+		blocks = append(blocks, &b)
+	}
+	r.sendBlocks(peer, blocks)
+	return nil
+}
+
+// HandleVoteRequest replies votes of a position to a peer.
+func (r *Responder) HandleVoteRequest(
+	peer types.NodeID, pos types.Position) error {
+	if !r.allow(peer) {
+		return ErrRateLimited
+	}
+	if r.votes == nil {
+		return nil
+	}
+	votes := r.votes.GetVotes(pos)
+	if len(votes) > r.config.MaxVotes {
+		votes = votes[:r.config.MaxVotes]
+	}
+	if len(votes) == 0 {
+		return nil
+	}
+	r.logger.Trace("Reply votes", "peer", peer, "position", pos,
+		"count", len(votes))
+	r.network.SendVotes(peer, votes)
+	return nil
+}
+
+// fit checks if a block could be appended to a reply with count blocks, the
+// payload size of the reply is accumulated in size when it fits.
+func (r *Responder) fit(count int, size *int, b *types.Block) bool {
+	s := len(b.Payload)
+	if count > 0 && *size+s > r.config.MaxPayloadSize {
+		return false
+	}
+	*size += s
+	return true
+}
+
+func (r *Responder) sendBlocks(peer types.NodeID, blocks []*types.Block) {
+	if len(blocks) == 0 {
+		return
+	}
+	r.logger.Trace("Reply blocks", "peer", peer, "count", len(blocks))
+	r.network.SendBlocks(peer, blocks)
+}
+
+// allow takes one token from the bucket of a peer.
+func (r *Responder) allow(peer types.NodeID) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.timeNowFn()
+	burst := float64(r.config.Burst)
+	var bk *bucket
+	if v, exists := r.buckets.Get(peer); exists {
+		bk = v.(*bucket)
+		bk.tokens += now.Sub(bk.last).Seconds() * r.config.RequestsPerSecond
+		if bk.tokens > burst {
+			bk.tokens = burst
+		}
+		bk.last = now
+	} else {
+		bk = &bucket{tokens: burst, last: now}
+		r.buckets.Add(peer, bk)
+	}
+	if bk.tokens < 1 {
+		return false
+	}
+	bk.tokens--
+	return true
+}
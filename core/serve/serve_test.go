@@ -0,0 +1,282 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package serve
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// fakeVoteCache is a VoteCache backed by a map.
+type fakeVoteCache map[types.Position][]*types.Vote
+
+func (c fakeVoteCache) GetVotes(pos types.Position) []*types.Vote {
+	return c[pos]
+}
+
+// recordNetwork records replies sent by responder.
+type recordNetwork struct {
+	blocks []*types.Block
+	votes  []*types.Vote
+}
+
+func (n *recordNetwork) SendBlocks(_ types.NodeID, blocks []*types.Block) {
+	n.blocks = append(n.blocks, blocks...)
+}
+
+func (n *recordNetwork) SendVotes(_ types.NodeID, votes []*types.Vote) {
+	n.votes = append(n.votes, votes...)
+}
+
+type ResponderTestSuite struct {
+	suite.Suite
+
+	db     db.Database
+	blocks []*types.Block
+	votes  fakeVoteCache
+}
+
+func (s *ResponderTestSuite) SetupTest() {
+	var err error
+	s.db, err = db.NewMemBackedDB()
+	s.Require().NoError(err)
+	s.blocks = nil
+	for i := uint64(1); i <= 20; i++ {
+		b := &types.Block{
+			Hash:     common.NewRandomHash(),
+			Position: types.Position{Height: i},
+			Payload:  make([]byte, 10),
+		}
+		s.Require().NoError(s.db.PutBlock(*b))
+		s.blocks = append(s.blocks, b)
+	}
+	pos := types.Position{Round: 1, Height: 5}
+	s.votes = fakeVoteCache{}
+	for _, nID := range test.GenerateRandomNodeIDs(4) {
+		v := types.NewVote(types.VoteCom, common.NewRandomHash(), 0)
+		v.ProposerID = nID
+		v.Position = pos
+		s.votes[pos] = append(s.votes[pos], v)
+	}
+}
+
+func (s *ResponderTestSuite) setupNetworks() (
+	requester, responder *test.Network) {
+	server := test.NewFakeTransportServer()
+	serverChannel, err := server.Host()
+	s.Require().NoError(err)
+	_, pubKeys, err := test.NewKeys(2)
+	s.Require().NoError(err)
+	networks := make([]*test.Network, 0, len(pubKeys))
+	wg := sync.WaitGroup{}
+	for _, key := range pubKeys {
+		n := test.NewNetwork(key, test.NetworkConfig{
+			Type:          test.NetworkTypeFake,
+			DirectLatency: &test.FixedLatencyModel{},
+			GossipLatency: &test.FixedLatencyModel{},
+			Marshaller:    test.NewDefaultMarshaller(nil)})
+		networks = append(networks, n)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Require().NoError(n.Setup(serverChannel))
+			go n.Run()
+		}()
+	}
+	s.Require().NoError(server.WaitForPeers(uint32(len(pubKeys))))
+	wg.Wait()
+	return networks[0], networks[1]
+}
+
+func (s *ResponderTestSuite) receive(n *test.Network, count int) (
+	msgs []interface{}) {
+	for len(msgs) < count {
+		select {
+		case msg := <-n.ReceiveChan():
+			msgs = append(msgs, msg.Payload)
+		case <-time.After(5 * time.Second):
+			s.FailNow("timeout waiting for replies")
+		}
+	}
+	return
+}
+
+func (s *ResponderTestSuite) TestServeThroughNetwork() {
+	requester, responder := s.setupNetworks()
+	defer requester.Close()
+	defer responder.Close()
+	r := NewResponder(s.db, s.votes, responder, DefaultConfig(),
+		&common.NullLogger{})
+	// Pull requests might be sent to the requester itself, ignore them to
+	// make sure all replies come from the responder.
+	requester.SetPullRequestHandler(func(*test.PullRequest) {})
+	responder.SetPullRequestHandler(func(req *test.PullRequest) {
+		var err error
+		switch req.Type {
+		case "block":
+			err = r.HandleBlockRequest(
+				req.Requester, req.Identity.(common.Hashes))
+		case "vote":
+			err = r.HandleVoteRequest(
+				req.Requester, req.Identity.(types.Position))
+		}
+		s.Require().NoError(err)
+	})
+	// Pull blocks by hashes, unknown hashes are ignored.
+	hashes := common.Hashes{
+		s.blocks[3].Hash, common.NewRandomHash(), s.blocks[7].Hash}
+	requester.PullBlocks(hashes)
+	received := make(map[common.Hash]struct{})
+	for _, msg := range s.receive(requester, 2) {
+		b, ok := msg.(*types.Block)
+		s.Require().True(ok)
+		received[b.Hash] = struct{}{}
+	}
+	s.Require().Equal(map[common.Hash]struct{}{
+		s.blocks[3].Hash: {},
+		s.blocks[7].Hash: {},
+	}, received)
+	// Pull votes.
+	pos := types.Position{Round: 1, Height: 5}
+	requester.PullVotes(pos)
+	votes := make(map[types.NodeID]struct{})
+	for _, msg := range s.receive(requester, len(s.votes[pos])) {
+		v, ok := msg.(*types.Vote)
+		s.Require().True(ok)
+		s.Require().Equal(pos, v.Position)
+		votes[v.ProposerID] = struct{}{}
+	}
+	s.Require().Len(votes, len(s.votes[pos]))
+	// Serve blocks by range, test.Network doesn't keep the order of messages.
+	s.Require().NoError(r.HandleBlockRangeRequest(requester.ID, 11, 15))
+	heights := make(map[uint64]struct{})
+	for _, msg := range s.receive(requester, 4) {
+		b, ok := msg.(*types.Block)
+		s.Require().True(ok)
+		heights[b.Position.Height] = struct{}{}
+	}
+	s.Require().Equal(map[uint64]struct{}{
+		11: {}, 12: {}, 13: {}, 14: {}}, heights)
+}
+
+func (s *ResponderTestSuite) TestSizeCaps() {
+	var (
+		n      = &recordNetwork{}
+		config = DefaultConfig()
+		peer   = test.GenerateRandomNodeIDs(1)[0]
+		pos    = types.Position{Round: 1, Height: 5}
+	)
+	config.MaxBlocks = 5
+	config.MaxVotes = 2
+	config.MaxPayloadSize = 35
+	r := NewResponder(s.db, s.votes, n, config, &common.NullLogger{})
+	// Truncated by payload size.
+	s.Require().NoError(r.HandleBlockRangeRequest(peer, 1, 21))
+	s.Require().Len(n.blocks, 3)
+	for i, b := range n.blocks {
+		s.Require().Equal(uint64(1+i), b.Position.Height)
+	}
+	// Truncated by count of blocks.
+	n.blocks = nil
+	config.MaxPayloadSize = 1000
+	r = NewResponder(s.db, s.votes, n, config, &common.NullLogger{})
+	hashes := common.Hashes{}
+	for _, b := range s.blocks {
+		hashes = append(hashes, b.Hash)
+	}
+	s.Require().NoError(r.HandleBlockRequest(peer, hashes))
+	s.Require().Len(n.blocks, 5)
+	// Hashes beyond the count limit are not looked up.
+	n.blocks = nil
+	unknown := common.Hashes{}
+	for i := 0; i < config.MaxBlocks; i++ {
+		unknown = append(unknown, common.NewRandomHash())
+	}
+	s.Require().NoError(r.HandleBlockRequest(peer, append(unknown, hashes...)))
+	s.Require().Empty(n.blocks)
+	// At least one block is sent even if it exceeds the payload limit.
+	n.blocks = nil
+	config.MaxPayloadSize = 1
+	r = NewResponder(s.db, s.votes, n, config, &common.NullLogger{})
+	s.Require().NoError(r.HandleBlockRangeRequest(peer, 10, 20))
+	s.Require().Len(n.blocks, 1)
+	s.Require().Equal(uint64(10), n.blocks[0].Position.Height)
+	// Truncated by count of votes.
+	s.Require().NoError(r.HandleVoteRequest(peer, pos))
+	s.Require().Len(n.votes, 2)
+	// Invalid requests.
+	s.Require().Equal(ErrEmptyRequest,
+		r.HandleBlockRequest(peer, common.Hashes{}))
+	s.Require().Equal(ErrInvalidRange, r.HandleBlockRangeRequest(peer, 5, 5))
+}
+
+func (s *ResponderTestSuite) TestZeroConfig() {
+	var (
+		n    = &recordNetwork{}
+		peer = test.GenerateRandomNodeIDs(1)[0]
+	)
+	r := NewResponder(s.db, s.votes, n, Config{}, &common.NullLogger{})
+	s.Require().Equal(DefaultConfig(), r.config)
+	s.Require().NoError(r.HandleBlockRangeRequest(peer, 1, 21))
+	s.Require().Len(n.blocks, len(s.blocks))
+}
+
+func (s *ResponderTestSuite) TestRateLimit() {
+	var (
+		n      = &recordNetwork{}
+		config = DefaultConfig()
+		peers  = test.GenerateRandomNodeIDs(2)
+		now    = time.Now()
+	)
+	config.RequestsPerSecond = 2
+	config.Burst = 3
+	r := NewResponder(s.db, nil, n, config, &common.NullLogger{})
+	r.timeNowFn = func() time.Time { return now }
+	hashes := common.Hashes{s.blocks[0].Hash}
+	for i := 0; i < config.Burst; i++ {
+		s.Require().NoError(r.HandleBlockRequest(peers[0], hashes))
+	}
+	s.Require().Equal(ErrRateLimited, r.HandleBlockRequest(peers[0], hashes))
+	s.Require().Equal(ErrRateLimited,
+		r.HandleVoteRequest(peers[0], types.Position{}))
+	// Other peers are not affected.
+	s.Require().NoError(r.HandleBlockRangeRequest(peers[1], 1, 2))
+	// Tokens are refilled by time.
+	now = now.Add(500 * time.Millisecond)
+	s.Require().NoError(r.HandleBlockRequest(peers[0], hashes))
+	s.Require().Equal(ErrRateLimited, r.HandleBlockRequest(peers[0], hashes))
+	// Refilled tokens never exceed the burst.
+	now = now.Add(time.Minute)
+	for i := 0; i < config.Burst; i++ {
+		s.Require().NoError(r.HandleBlockRequest(peers[0], hashes))
+	}
+	s.Require().Equal(ErrRateLimited, r.HandleBlockRequest(peers[0], hashes))
+	s.Require().Len(n.blocks, 2*config.Burst+2)
+}
+
+func TestResponder(t *testing.T) {
+	suite.Run(t, new(ResponderTestSuite))
+}
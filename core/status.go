@@ -0,0 +1,121 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// BAStatus is the status of the running agreement.
+type BAStatus struct {
+	// Running is false when the agreement is stopped, and all other fields
+	// are meaningless in this case.
+	Running  bool
+	Position types.Position
+	Period   uint64
+	State    string
+}
+
+// DKGStatus is the status of DKG protocol.
+type DKGStatus struct {
+	Round uint64
+	Reset uint64
+	// Step is the count of completed phases of the DKG protocol.
+	Step    int
+	Running bool
+	Final   bool
+	Success bool
+}
+
+// Status is a snapshot of the internal status of a Consensus instance.
+type Status struct {
+	// Round and Height are the round and height this node is working on.
+	Round  uint64
+	Height uint64
+	BA     BAStatus
+	// InNotarySet and InNextNotarySet report if this node is in the notary
+	// set of current and next round.
+	InNotarySet     bool
+	InNextNotarySet bool
+	// DKG is the status of the DKG protocol for next round.
+	DKG DKGStatus
+	// LastDelivered is the position of the last block delivered to the
+	// application, it would be nil if nothing is delivered yet.
+	LastDelivered *types.Position
+	// LastPending is the position of the first confirmed but not delivered
+	// block, it would be nil if no such block.
+	LastPending *types.Position
+	// AppQueueDepth is the count of events not consumed by the application.
+	AppQueueDepth int
+}
+
+// Status returns a snapshot of current status of this instance.
+func (con *Consensus) Status() Status {
+	status := Status{Round: con.bcModule.tipRound()}
+	if agr := con.baMgr.baModule; agr != nil {
+		pos, period, state := agr.status()
+		if !isStop(pos) {
+			status.BA = BAStatus{
+				Running:  true,
+				Position: pos,
+				Period:   period,
+				State:    state.String(),
+			}
+		}
+	}
+	if b := con.bcModule.lastDeliveredBlock(); b != nil {
+		pos := b.Position
+		status.LastDelivered = &pos
+		status.Height = pos.Height + 1
+	} else {
+		status.Height = types.GenesisHeight
+	}
+	if b := con.bcModule.lastPendingBlock(); b != nil {
+		pos := b.Position
+		status.LastPending = &pos
+	}
+	if nb, ok := con.app.(*nonBlocking); ok {
+		status.AppQueueDepth = nb.queueDepth()
+	}
+	if status.BA.Running {
+		status.Height = status.BA.Position.Height
+	}
+	isNotary := func(round uint64) bool {
+		notarySet, err := con.nodeSetCache.GetNotarySet(round)
+		if err != nil {
+			return false
+		}
+		_, exist := notarySet[con.ID]
+		return exist
+	}
+	status.InNotarySet = isNotary(status.Round)
+	status.InNextNotarySet = isNotary(status.Round + 1)
+	if dkg := con.cfgModule.dkgStatus(); dkg != nil {
+		status.DKG = *dkg
+	} else {
+		status.DKG = DKGStatus{
+			Round: status.Round + 1,
+			Reset: con.gov.DKGResetCount(status.Round + 1),
+		}
+	}
+	if status.DKG.Round >= con.params.DKGDelayRound {
+		status.DKG.Final = con.gov.IsDKGFinal(status.DKG.Round)
+		status.DKG.Success = con.gov.IsDKGSuccess(status.DKG.Round)
+	}
+	return status
+}
@@ -0,0 +1,269 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// DefaultSubscriptionBufferSize is the buffer size of a subscription when
+// a non-positive size is given.
+const DefaultSubscriptionBufferSize = 128
+
+// EventType is the type of events emitted to subscribers.
+type EventType int
+
+// EventType enum.
+const (
+	EventRoundSwitched EventType = iota
+	EventNotarySetChanged
+	EventDKGStarted
+	EventDKGReset
+	EventDKGSucceeded
+	EventCRSProposed
+	EventBlockConfirmed
+	EventBlockDelivered
+	EventForkVote
+	EventBAPeriodAdvanced
+)
+
+// Event is the interface for all events emitted to subscribers.
+type Event interface {
+	Type() EventType
+}
+
+// RoundSwitchedEvent is emitted when the first block of a round is delivered.
+type RoundSwitchedEvent struct {
+	Round       uint64
+	BeginHeight uint64
+}
+
+// Type implements Event interface.
+func (e *RoundSwitchedEvent) Type() EventType { return EventRoundSwitched }
+
+// NotarySetChangedEvent is emitted when the notary set of the newly switched
+// round is different from the one of previous round.
+type NotarySetChangedEvent struct {
+	Round     uint64
+	NotarySet map[types.NodeID]struct{}
+	// IsNotary is true when this node is in the new notary set.
+	IsNotary bool
+}
+
+// Type implements Event interface.
+func (e *NotarySetChangedEvent) Type() EventType { return EventNotarySetChanged }
+
+// DKGStartedEvent is emitted when this node starts running DKG protocol.
+type DKGStartedEvent struct {
+	Round uint64
+	Reset uint64
+}
+
+// Type implements Event interface.
+func (e *DKGStartedEvent) Type() EventType { return EventDKGStarted }
+
+// DKGResetEvent is emitted when the DKG of a round is reset.
+type DKGResetEvent struct {
+	Round uint64
+	Reset uint64
+}
+
+// Type implements Event interface.
+func (e *DKGResetEvent) Type() EventType { return EventDKGReset }
+
+// DKGSucceededEvent is emitted when this node finishes DKG protocol
+// successfully.
+type DKGSucceededEvent struct {
+	Round uint64
+	Reset uint64
+}
+
+// Type implements Event interface.
+func (e *DKGSucceededEvent) Type() EventType { return EventDKGSucceeded }
+
+// CRSProposedEvent is emitted when this node proposes the CRS of a round.
+type CRSProposedEvent struct {
+	Round     uint64
+	SignedCRS []byte
+}
+
+// Type implements Event interface.
+func (e *CRSProposedEvent) Type() EventType { return EventCRSProposed }
+
+// BlockConfirmedEvent is emitted when a block is confirmed, the same moment
+// Application.BlockConfirmed is called.
+type BlockConfirmedEvent struct {
+	Block *types.Block
+}
+
+// Type implements Event interface.
+func (e *BlockConfirmedEvent) Type() EventType { return EventBlockConfirmed }
+
+// BlockDeliveredEvent is emitted when a block is delivered, the same moment
+// Application.BlockDelivered is called.
+type BlockDeliveredEvent struct {
+	Hash       common.Hash
+	Position   types.Position
+	Randomness []byte
+}
+
+// Type implements Event interface.
+func (e *BlockDeliveredEvent) Type() EventType { return EventBlockDelivered }
+
+// ForkVoteEvent is emitted when two conflicting votes from the same proposer
+// are detected.
+type ForkVoteEvent struct {
+	Vote1 *types.Vote
+	Vote2 *types.Vote
+}
+
+// Type implements Event interface.
+func (e *ForkVoteEvent) Type() EventType { return EventForkVote }
+
+// BAPeriodAdvancedEvent is emitted when the running agreement enters a new
+// period.
+type BAPeriodAdvancedEvent struct {
+	Position types.Position
+	Period   uint64
+}
+
+// Type implements Event interface.
+func (e *BAPeriodAdvancedEvent) Type() EventType { return EventBAPeriodAdvanced }
+
+// Subscription receives events from a Consensus instance. Events would be
+// dropped instead of blocking consensus when its buffer is full.
+type Subscription struct {
+	ch      chan Event
+	types   map[EventType]struct{}
+	dropped uint64
+	bus     *eventBus
+}
+
+// Chan returns the channel to receive events, it would be closed when
+// unsubscribed or Consensus is stopped.
+func (s *Subscription) Chan() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns the count of events dropped due to full buffer.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Unsubscribe stops receiving events and closes the channel.
+func (s *Subscription) Unsubscribe() {
+	s.bus.unsubscribe(s)
+}
+
+func (s *Subscription) accept(t EventType) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+	_, exist := s.types[t]
+	return exist
+}
+
+// eventBus dispatches events to subscribers without blocking.
+type eventBus struct {
+	lock   sync.RWMutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *eventBus) subscribe(size int, eventTypes []EventType) *Subscription {
+	if size <= 0 {
+		size = DefaultSubscriptionBufferSize
+	}
+	s := &Subscription{
+		ch:    make(chan Event, size),
+		types: make(map[EventType]struct{}),
+		bus:   b,
+	}
+	for _, t := range eventTypes {
+		s.types[t] = struct{}{}
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		close(s.ch)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+func (b *eventBus) unsubscribe(s *Subscription) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if _, exist := b.subs[s]; !exist {
+		return
+	}
+	delete(b.subs, s)
+	close(s.ch)
+}
+
+// interested checks if any subscriber accepts this type of event, it's used
+// to skip preparing expensive events.
+func (b *eventBus) interested(t EventType) bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for s := range b.subs {
+		if s.accept(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *eventBus) publish(e Event) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for s := range b.subs {
+		if !s.accept(e.Type()) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
+
+func (b *eventBus) close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for s := range b.subs {
+		close(s.ch)
+	}
+	b.subs = nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+type SubscriptionTestSuite struct {
+	suite.Suite
+}
+
+func (s *SubscriptionTestSuite) TestFilterAndDrop() {
+	bus := newEventBus()
+	all := bus.subscribe(0, nil)
+	s.Require().Equal(DefaultSubscriptionBufferSize, cap(all.ch))
+	dkg := bus.subscribe(1, []EventType{EventDKGStarted, EventDKGReset})
+	s.Require().True(bus.interested(EventBlockDelivered))
+	bus.publish(&DKGStartedEvent{Round: 1})
+	bus.publish(&BlockDeliveredEvent{Position: types.Position{Height: 1}})
+	bus.publish(&DKGResetEvent{Round: 1, Reset: 1})
+	// The subscriber for DKG events only has buffer for one event.
+	s.Require().Equal(uint64(1), dkg.Dropped())
+	s.Require().Equal(uint64(0), all.Dropped())
+	s.Require().Equal(&DKGStartedEvent{Round: 1}, <-dkg.Chan())
+	s.Require().Len(all.Chan(), 3)
+	for _, t := range []EventType{
+		EventDKGStarted, EventBlockDelivered, EventDKGReset} {
+		s.Require().Equal(t, (<-all.Chan()).Type())
+	}
+	// Unsubscribed subscriber receives nothing.
+	all.Unsubscribe()
+	_, ok := <-all.Chan()
+	s.Require().False(ok)
+	s.Require().False(bus.interested(EventBlockDelivered))
+	// Unsubscribe twice should be fine.
+	all.Unsubscribe()
+	// Closing the bus closes all subscriptions.
+	bus.close()
+	_, ok = <-dkg.Chan()
+	s.Require().False(ok)
+	dkg.Unsubscribe()
+	// Subscribing a closed bus returns a closed subscription.
+	_, ok = <-bus.subscribe(1, nil).Chan()
+	s.Require().False(ok)
+	bus.publish(&DKGStartedEvent{Round: 2})
+}
+
+func TestSubscription(t *testing.T) {
+	suite.Run(t, new(SubscriptionTestSuite))
+}
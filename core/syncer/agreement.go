@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/dexon-foundation/dexon-consensus/common"
@@ -36,14 +37,17 @@ type agreement struct {
 	chainTip          uint64
 	cache             *utils.NodeSetCache
 	tsigVerifierCache *core.TSigVerifierCache
+	progress          *progressMeter
 	inputChan         chan interface{}
 	outputChan        chan<- *types.Block
 	pullChan          chan<- common.Hash
 	blocks            map[types.Position]map[common.Hash]*types.Block
 	agreementResults  map[common.Hash][]byte
 	latestCRSRound    uint64
+	pendingRound      uint64
 	pendingAgrs       map[uint64]map[common.Hash]*types.AgreementResult
 	pendingBlocks     map[uint64]map[common.Hash]*types.Block
+	params            types.Params
 	logger            common.Logger
 	confirmedBlocks   map[common.Hash]struct{}
 	ctx               context.Context
@@ -54,16 +58,19 @@ type agreement struct {
 func newAgreement(chainTip uint64,
 	ch chan<- *types.Block, pullChan chan<- common.Hash,
 	cache *utils.NodeSetCache, verifier *core.TSigVerifierCache,
+	progress *progressMeter, params types.Params,
 	logger common.Logger) *agreement {
 	a := &agreement{
 		chainTip:          chainTip,
 		cache:             cache,
 		tsigVerifierCache: verifier,
+		progress:          progress,
 		inputChan:         make(chan interface{}, 1000),
 		outputChan:        ch,
 		pullChan:          pullChan,
 		blocks:            make(map[types.Position]map[common.Hash]*types.Block),
 		agreementResults:  make(map[common.Hash][]byte),
+		params:            params,
 		logger:            logger,
 		pendingAgrs: make(
 			map[uint64]map[common.Hash]*types.AgreementResult),
@@ -88,7 +95,8 @@ func (a *agreement) run() {
 			}
 			switch v := val.(type) {
 			case *types.Block:
-				if v.Position.Round >= core.DKGDelayRound && v.IsFinalized() {
+				if v.Position.Round >= a.params.DKGDelayRound &&
+					v.IsFinalized() {
 					a.processFinalizedBlock(v)
 				} else {
 					a.processBlock(v)
@@ -126,6 +134,8 @@ func (a *agreement) processFinalizedBlock(block *types.Block) {
 		return
 	}
 	if block.Position.Round > a.latestCRSRound {
+		a.progress.observeUnverified(block.Position.Height)
+		a.markPendingRound(block.Position.Round)
 		pendingsForRound, exists := a.pendingBlocks[block.Position.Round]
 		if !exists {
 			pendingsForRound = make(map[common.Hash]*types.Block)
@@ -157,6 +167,7 @@ func (a *agreement) processFinalizedBlock(block *types.Block) {
 		a.logger.Error("incorrect block randomness", "block", block)
 		return
 	}
+	a.progress.observe(block.Position.Height)
 	a.confirm(block)
 }
 
@@ -167,6 +178,8 @@ func (a *agreement) processAgreementResult(r *types.AgreementResult) {
 		return
 	}
 	if r.Position.Round > a.latestCRSRound {
+		a.progress.observeUnverified(r.Position.Height)
+		a.markPendingRound(r.Position.Round)
 		pendingsForRound, exists := a.pendingAgrs[r.Position.Round]
 		if !exists {
 			pendingsForRound = make(map[common.Hash]*types.AgreementResult)
@@ -181,13 +194,14 @@ func (a *agreement) processAgreementResult(r *types.AgreementResult) {
 		a.logger.Error("unable to get notary set", "result", r, "error", err)
 		return
 	}
-	if err := core.VerifyAgreementResult(r, notarySet); err != nil {
+	if err := core.VerifyAgreementResult(
+		r, notarySet, a.params.DKGDelayRound); err != nil {
 		a.logger.Error("Agreement result verification failed",
 			"result", r,
 			"error", err)
 		return
 	}
-	if r.Position.Round >= core.DKGDelayRound {
+	if r.Position.Round >= a.params.DKGDelayRound {
 		verifier, ok, err := a.tsigVerifierCache.UpdateAndGet(r.Position.Round)
 		if err != nil {
 			a.logger.Error("error verifying agreement result randomness",
@@ -208,11 +222,12 @@ func (a *agreement) processAgreementResult(r *types.AgreementResult) {
 		}
 	} else {
 		// Special case for rounds before DKGDelayRound.
-		if bytes.Compare(r.Randomness, core.NoRand) != 0 {
+		if bytes.Compare(r.Randomness, a.params.NoRand) != 0 {
 			a.logger.Error("incorrect agreement result randomness", "result", r)
 			return
 		}
 	}
+	a.progress.observe(r.Position.Height)
 	if r.IsEmptyBlock {
 		b := &types.Block{
 			Position:   r.Position,
@@ -254,7 +269,7 @@ func (a *agreement) processNewCRS(round uint64) {
 		return
 	}
 	prevRound := a.latestCRSRound + 1
-	a.latestCRSRound = round
+	atomic.StoreUint64(&a.latestCRSRound, round)
 	// Verify all pending results.
 	for r := prevRound; r <= a.latestCRSRound; r++ {
 		notarySet, err := a.cache.GetNotarySet(r)
@@ -268,7 +283,8 @@ func (a *agreement) processNewCRS(round uint64) {
 		}
 		delete(a.pendingAgrs, r)
 		for _, res := range pendingsForRound {
-			if err := core.VerifyAgreementResult(res, notarySet); err != nil {
+			if err := core.VerifyAgreementResult(
+				res, notarySet, a.params.DKGDelayRound); err != nil {
 				a.logger.Error("Invalid agreement result",
 					"result", res,
 					"error", err)
@@ -281,6 +297,20 @@ func (a *agreement) processNewCRS(round uint64) {
 	}
 }
 
+// markPendingRound records the round of results or blocks cached for CRS.
+func (a *agreement) markPendingRound(round uint64) {
+	if round > a.pendingRound {
+		atomic.StoreUint64(&a.pendingRound, round)
+	}
+}
+
+// waitingCRS checks if there are results or blocks waiting for CRS, it's safe
+// to be called from other routines.
+func (a *agreement) waitingCRS() bool {
+	return atomic.LoadUint64(&a.pendingRound) >
+		atomic.LoadUint64(&a.latestCRSRound)
+}
+
 // confirm notifies consensus the confirmation of a block in BA.
 func (a *agreement) confirm(b *types.Block) {
 	if !b.IsFinalized() {
@@ -0,0 +1,234 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package syncer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+var (
+	// ErrCheckpointNotFinalized is reported when the block of checkpoint has
+	// no randomness.
+	ErrCheckpointNotFinalized = fmt.Errorf("checkpoint not finalized")
+	// ErrIncorrectCheckpointHash is reported when the hash of the block of
+	// checkpoint doesn't match its content.
+	ErrIncorrectCheckpointHash = fmt.Errorf("incorrect checkpoint hash")
+	// ErrIncorrectCheckpointSignature is reported when the checkpoint is not
+	// signed by the operator.
+	ErrIncorrectCheckpointSignature = fmt.Errorf(
+		"incorrect checkpoint signature")
+	// ErrIncorrectCheckpointRandomness is reported when the randomness of the
+	// block of checkpoint is not signed by DKG group of its round.
+	ErrIncorrectCheckpointRandomness = fmt.Errorf(
+		"incorrect checkpoint randomness")
+	// ErrCheckpointBeforeDKG is reported when verifying a checkpoint in rounds
+	// without DKG group by DKG group keys.
+	ErrCheckpointBeforeDKG = fmt.Errorf("checkpoint before DKG")
+	// ErrMismatchedRoundState is reported when the round state in checkpoint
+	// doesn't match the one in governance.
+	ErrMismatchedRoundState = fmt.Errorf("mismatched round state")
+	// ErrDatabaseNotEmpty is reported when seeding a database already having
+	// blocks with a checkpoint.
+	ErrDatabaseNotEmpty = fmt.Errorf("database not empty")
+)
+
+// Checkpoint is a trusted finalized block for syncer to start from, blocks
+// before it are not required.
+//
+// The governance instance passed to syncer is expected to be restored to the
+// state at the checkpoint by fullnode, the round state carried by checkpoint
+// is checked against it.
+type Checkpoint struct {
+	Block types.Block `json:"block"`
+	// RoundHeight is the height the round of the block begins with.
+	RoundHeight uint64 `json:"round_height"`
+	// CRS is the CRS of the round of the block.
+	CRS common.Hash `json:"crs"`
+	// Signature is signed by the operator, it's not required when the
+	// checkpoint is verified against DKG group keys.
+	Signature crypto.Signature `json:"signature"`
+}
+
+// NewCheckpoint creates a checkpoint from a finalized block with round state
+// from governance.
+func NewCheckpoint(b *types.Block, gov core.Governance) (*Checkpoint, error) {
+	if !b.IsFinalized() {
+		return nil, ErrCheckpointNotFinalized
+	}
+	return &Checkpoint{
+		Block:       *b.Clone(),
+		RoundHeight: gov.GetRoundHeight(b.Position.Round),
+		CRS:         gov.CRS(b.Position.Round),
+	}, nil
+}
+
+// Hash calculates the hash to be signed by the operator.
+func (cp *Checkpoint) Hash() common.Hash {
+	hashPosition := utils.HashPosition(cp.Block.Position)
+	binaryRoundHeight := make([]byte, 8)
+	binary.LittleEndian.PutUint64(binaryRoundHeight, cp.RoundHeight)
+	return crypto.Keccak256Hash(
+		cp.Block.Hash[:],
+		hashPosition[:],
+		cp.Block.Randomness,
+		binaryRoundHeight,
+		cp.CRS[:],
+	)
+}
+
+// Sign signs the checkpoint as the operator.
+func (cp *Checkpoint) Sign(prv crypto.PrivateKey) (err error) {
+	cp.Signature, err = prv.Sign(cp.Hash())
+	return
+}
+
+// VerifySignature verifies the checkpoint is signed by the operator.
+func (cp *Checkpoint) VerifySignature(operator crypto.PublicKey) error {
+	if err := cp.verifyBlock(); err != nil {
+		return err
+	}
+	if !operator.VerifySignature(cp.Hash(), cp.Signature) {
+		return ErrIncorrectCheckpointSignature
+	}
+	return nil
+}
+
+// VerifyRandomness verifies the randomness of the block of checkpoint is
+// signed by DKG group of its round, which is only available after
+// DKGDelayRound.
+func (cp *Checkpoint) VerifyRandomness(
+	gov core.Governance, params types.Params) error {
+	if err := cp.verifyBlock(); err != nil {
+		return err
+	}
+	if cp.Block.Position.Round < params.DKGDelayRound {
+		return ErrCheckpointBeforeDKG
+	}
+	verifier, ok, err := core.NewTSigVerifierCache(gov, 1).UpdateAndGet(
+		cp.Block.Position.Round)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrIncorrectCheckpointRandomness
+	}
+	if !verifier.VerifySignature(cp.Block.Hash, crypto.Signature{
+		Type:      "bls",
+		Signature: cp.Block.Randomness,
+	}) {
+		return ErrIncorrectCheckpointRandomness
+	}
+	return nil
+}
+
+// verifyBlock verifies the block is finalized and its hash matches its
+// content.
+func (cp *Checkpoint) verifyBlock() error {
+	b := &cp.Block
+	if !b.IsFinalized() {
+		return ErrCheckpointNotFinalized
+	}
+	if len(b.Payload) > 0 &&
+		crypto.Keccak256Hash(b.Payload) != b.PayloadHash {
+		return ErrIncorrectCheckpointHash
+	}
+	hash, err := utils.HashBlock(b)
+	if err != nil {
+		return err
+	}
+	if hash != b.Hash {
+		return ErrIncorrectCheckpointHash
+	}
+	return nil
+}
+
+// verifyRoundState checks the round state in checkpoint matches governance.
+func (cp *Checkpoint) verifyRoundState(gov core.Governance) error {
+	round := cp.Block.Position.Round
+	if gov.GetRoundHeight(round) != cp.RoundHeight ||
+		gov.CRS(round) != cp.CRS {
+		return ErrMismatchedRoundState
+	}
+	return nil
+}
+
+// NewConsensusFromCheckpoint creates a syncer seeding an empty database with a
+// checkpoint, blocks to sync should start from the next height of it. The
+// checkpoint is verified against the signature of operator, or against DKG
+// group keys when operator is nil. A database already seeded by the same
+// checkpoint is accepted, a database synced beyond it should be resumed by
+// NewConsensus instead.
+//
+// The application is expected to be at the state of the checkpoint already,
+// the block of checkpoint would not be delivered to it.
+func NewConsensusFromCheckpoint(
+	cp *Checkpoint,
+	operator crypto.PublicKey,
+	dMoment time.Time,
+	params types.Params,
+	app core.Application,
+	gov core.Governance,
+	dbInst db.Database,
+	network core.Network,
+	prv crypto.PrivateKey,
+	logger common.Logger) (*Consensus, error) {
+	var err error
+	if operator != nil {
+		err = cp.VerifySignature(operator)
+	} else {
+		err = cp.VerifyRandomness(gov, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = cp.verifyRoundState(gov); err != nil {
+		return nil, err
+	}
+	tipHash, tipHeight := dbInst.GetCompactionChainTipInfo()
+	switch {
+	case tipHeight == 0:
+		batch := dbInst.NewBatch()
+		if err = batch.PutBlock(cp.Block); err != nil {
+			batch.Rollback()
+			return nil, err
+		}
+		if err = batch.SeedCompactionChainTip(
+			cp.Block.Hash, cp.Block.Position.Height); err != nil {
+			batch.Rollback()
+			return nil, err
+		}
+		if err = batch.Commit(); err != nil {
+			return nil, err
+		}
+	case tipHash != cp.Block.Hash:
+		// The database is not seeded by this checkpoint.
+		return nil, ErrDatabaseNotEmpty
+	}
+	logger.Info("Syncer starts from checkpoint", "block", &cp.Block)
+	return NewConsensus(cp.Block.Position.Height, dMoment, params, app, gov,
+		dbInst, network, prv, logger), nil
+}
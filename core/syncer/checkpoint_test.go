@@ -0,0 +1,142 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package syncer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+type CheckpointTestSuite struct {
+	suite.Suite
+
+	params types.Params
+	gov    *test.Governance
+}
+
+func (s *CheckpointTestSuite) SetupTest() {
+	_, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	s.params = types.DefaultParams()
+	s.gov, err = test.NewGovernance(test.NewState(s.params, pubKeys,
+		100*time.Millisecond, &common.NullLogger{}, true),
+		core.ConfigRoundShift)
+	s.Require().NoError(err)
+}
+
+func (s *CheckpointTestSuite) newCheckpoint(height uint64) *Checkpoint {
+	b := &types.Block{
+		Position:   types.Position{Height: height},
+		ParentHash: common.NewRandomHash(),
+		Timestamp:  time.Now().UTC(),
+		Payload:    []byte{1, 2, 3},
+		Randomness: s.params.NoRand,
+	}
+	b.PayloadHash = crypto.Keccak256Hash(b.Payload)
+	var err error
+	b.Hash, err = utils.HashBlock(b)
+	s.Require().NoError(err)
+	cp, err := NewCheckpoint(b, s.gov)
+	s.Require().NoError(err)
+	return cp
+}
+
+func (s *CheckpointTestSuite) TestVerifySignature() {
+	operator, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	other, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	cp := s.newCheckpoint(10)
+	s.Require().NoError(cp.Sign(operator))
+	s.Require().NoError(cp.VerifySignature(operator.PublicKey()))
+	s.Require().Equal(ErrIncorrectCheckpointSignature,
+		cp.VerifySignature(other.PublicKey()))
+	// Round state is covered by signature.
+	cp.RoundHeight++
+	s.Require().Equal(ErrIncorrectCheckpointSignature,
+		cp.VerifySignature(operator.PublicKey()))
+	cp.RoundHeight--
+	// Block content is covered by block hash.
+	cp.Block.Payload = []byte{3, 2, 1}
+	s.Require().Equal(ErrIncorrectCheckpointHash,
+		cp.VerifySignature(operator.PublicKey()))
+	cp.Block.Payload = []byte{1, 2, 3}
+	cp.Block.Timestamp = cp.Block.Timestamp.Add(time.Second)
+	s.Require().Equal(ErrIncorrectCheckpointHash,
+		cp.VerifySignature(operator.PublicKey()))
+	// Blocks without randomness are not accepted.
+	cp = s.newCheckpoint(10)
+	cp.Block.Randomness = nil
+	s.Require().NoError(cp.Sign(operator))
+	s.Require().Equal(ErrCheckpointNotFinalized,
+		cp.VerifySignature(operator.PublicKey()))
+	_, err = NewCheckpoint(&cp.Block, s.gov)
+	s.Require().Equal(ErrCheckpointNotFinalized, err)
+	// There is no DKG group keys to verify checkpoints before DKGDelayRound.
+	s.Require().Equal(ErrCheckpointBeforeDKG,
+		s.newCheckpoint(10).VerifyRandomness(s.gov, s.params))
+}
+
+func (s *CheckpointTestSuite) TestSeedDatabase() {
+	operator, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	cp := s.newCheckpoint(10)
+	s.Require().NoError(cp.Sign(operator))
+	newSyncer := func(cp *Checkpoint, dbInst db.Database) error {
+		_, err := NewConsensusFromCheckpoint(cp, operator.PublicKey(),
+			time.Now(), s.params, nil, s.gov, dbInst, nil, operator,
+			&common.NullLogger{})
+		return err
+	}
+	// Mismatched round state.
+	dbInst, err := db.NewMemBackedDB()
+	s.Require().NoError(err)
+	cpBadCRS := *cp
+	cpBadCRS.CRS = common.NewRandomHash()
+	s.Require().NoError(cpBadCRS.Sign(operator))
+	s.Require().Equal(ErrMismatchedRoundState, newSyncer(&cpBadCRS, dbInst))
+	// Seed an empty database.
+	s.Require().NoError(newSyncer(cp, dbInst))
+	hash, height := dbInst.GetCompactionChainTipInfo()
+	s.Require().Equal(cp.Block.Hash, hash)
+	s.Require().Equal(cp.Block.Position.Height, height)
+	b, err := dbInst.GetBlock(hash)
+	s.Require().NoError(err)
+	s.Require().Equal(cp.Block.Hash, b.Hash)
+	// It's fine to start from the same checkpoint again.
+	s.Require().NoError(newSyncer(cp, dbInst))
+	// Another checkpoint is not accepted.
+	cp2 := s.newCheckpoint(20)
+	s.Require().NoError(cp2.Sign(operator))
+	s.Require().Equal(ErrDatabaseNotEmpty, newSyncer(cp2, dbInst))
+}
+
+func TestCheckpoint(t *testing.T) {
+	suite.Run(t, new(CheckpointTestSuite))
+}
@@ -20,6 +20,7 @@ package syncer
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"sort"
 	"sync"
 	"time"
@@ -52,6 +53,7 @@ type Consensus struct {
 	db           db.Database
 	gov          core.Governance
 	dMoment      time.Time
+	params       types.Params
 	logger       common.Logger
 	app          core.Application
 	prv          crypto.PrivateKey
@@ -59,11 +61,16 @@ type Consensus struct {
 	nodeSetCache *utils.NodeSetCache
 	tsigVerifier *core.TSigVerifierCache
 
+	// verifierWorkers is the count of workers verifying blocks passed to
+	// SyncBlocks, blocks are not verified when it's zero.
+	verifierWorkers int
+
 	blocks            types.BlocksByPosition
 	agreementModule   *agreement
 	agreementRoundCut uint64
 	heightEvt         *common.Event
 	roundEvt          *utils.RoundEvent
+	progress          *progressMeter
 
 	// lock for accessing all fields.
 	lock               sync.RWMutex
@@ -88,15 +95,76 @@ type Consensus struct {
 func NewConsensus(
 	initHeight uint64,
 	dMoment time.Time,
+	params types.Params,
 	app core.Application,
 	gov core.Governance,
 	db db.Database,
 	network core.Network,
 	prv crypto.PrivateKey,
 	logger common.Logger) *Consensus {
+	con, err := newConsensus(
+		initHeight, dMoment, params, app, gov, db, network, prv, logger)
+	if err != nil {
+		panic(err)
+	}
+	return con
+}
 
+// NewConsensusWithGovernanceV2 creates an instance of Consensus (not
+// core.Consensus) like NewConsensus, with governance accessed via
+// core.GovernanceV2 interface.
+//
+// The state required to bootstrap from the compaction chain tip in database
+// is fetched before constructing, an error is returned if it's not available
+// after retrying according to 'config'. Failed requests of a running instance
+// are retried until 'ctx' is done.
+func NewConsensusWithGovernanceV2(
+	ctx context.Context,
+	initHeight uint64,
+	dMoment time.Time,
+	params types.Params,
+	app core.Application,
+	gov core.GovernanceV2,
+	config core.RetryConfig,
+	db db.Database,
+	network core.Network,
+	prv crypto.PrivateKey,
+	logger common.Logger) (*Consensus, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	round := uint64(0)
+	if tipHash, tipHeight := db.GetCompactionChainTipInfo(); tipHeight > 0 {
+		tip, err := db.GetBlock(tipHash)
+		if err != nil {
+			return nil, err
+		}
+		round = tip.Position.Round
+	}
+	adapter, err := core.PrepareGovernanceV2(ctx, gov, round, config, logger)
+	if err != nil {
+		return nil, err
+	}
+	return newConsensus(
+		initHeight, dMoment, params, app, adapter, db, network, prv, logger)
+}
+
+func newConsensus(
+	initHeight uint64,
+	dMoment time.Time,
+	params types.Params,
+	app core.Application,
+	gov core.Governance,
+	db db.Database,
+	network core.Network,
+	prv crypto.PrivateKey,
+	logger common.Logger) (*Consensus, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
 	con := &Consensus{
 		dMoment:      dMoment,
+		params:       params,
 		app:          app,
 		gov:          gov,
 		db:           db,
@@ -111,12 +179,15 @@ func NewConsensus(
 	}
 	con.ctx, con.ctxCancel = context.WithCancel(context.Background())
 	_, con.initChainTipHeight = db.GetCompactionChainTipInfo()
+	con.progress = newProgressMeter(con.initChainTipHeight)
 	con.agreementModule = newAgreement(
 		con.initChainTipHeight,
 		con.receiveChan,
 		con.pullChan,
 		con.nodeSetCache,
 		con.tsigVerifier,
+		con.progress,
+		con.params,
 		con.logger)
 	con.agreementWaitGroup.Add(1)
 	go func() {
@@ -124,9 +195,11 @@ func NewConsensus(
 		con.agreementModule.run()
 	}()
 	if err := con.deliverPendingBlocks(initHeight); err != nil {
-		panic(err)
+		con.ctxCancel()
+		con.stopAgreement()
+		return nil, err
 	}
-	return con
+	return con, nil
 }
 
 func (con *Consensus) deliverPendingBlocks(height uint64) error {
@@ -177,12 +250,12 @@ func (con *Consensus) assureBuffering() {
 	)
 	if height == 0 {
 		con.roundEvt, err = utils.NewRoundEvent(con.ctx, con.gov, con.logger,
-			types.Position{}, core.ConfigRoundShift)
+			types.Position{}, con.params)
 	} else {
 		var b types.Block
 		if b, err = con.db.GetBlock(blockHash); err == nil {
 			con.roundEvt, err = utils.NewRoundEvent(con.ctx, con.gov,
-				con.logger, b.Position, core.ConfigRoundShift)
+				con.logger, b.Position, con.params)
 		}
 	}
 	if err != nil {
@@ -240,6 +313,7 @@ func (con *Consensus) assureBuffering() {
 	con.roundEvt.TriggerInitEvent()
 	con.startAgreement()
 	con.startNetwork()
+	con.progress.setPhase(SyncPhaseAgreement)
 }
 
 func (con *Consensus) checkIfSynced(blocks []*types.Block) (synced bool) {
@@ -302,12 +376,32 @@ func (con *Consensus) ForceSync(lastPos types.Position, skip bool) {
 			})
 	}
 	con.syncedSkipNext = skip
+	con.progress.setPhase(SyncPhaseSynced)
 	con.logger.Info("Force Sync", "block", &block, "skip", skip)
 }
 
+// SetBlockVerification sets if blocks passed to SyncBlocks are verified, which
+// is required when syncing from untrusted peers. Blocks are verified by
+// 'workers' workers in parallel, the count of CPUs is used when 'workers' is
+// not positive. It should be called before SyncBlocks.
+func (con *Consensus) SetBlockVerification(enabled bool, workers int) {
+	if !enabled {
+		con.verifierWorkers = 0
+		return
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	con.verifierWorkers = workers
+}
+
 // SyncBlocks syncs blocks from compaction chain, latest is true if the caller
 // regards the blocks are the latest ones. Notice that latest can be true for
 // many times.
+// When block verification is enabled, the parent hash, the signature of the
+// proposer and the randomness of blocks are verified, none of them would be
+// written when any of them is invalid, and *ErrInvalidBlock carrying the first
+// invalid block is returned.
 // NOTICE: parameter "blocks" should be consecutive in compaction height.
 // NOTICE: this method is not expected to be called concurrently.
 func (con *Consensus) SyncBlocks(
@@ -335,7 +429,7 @@ func (con *Consensus) SyncBlocks(
 	}
 	// Make sure the first block is the next block of current compaction chain
 	// tip in DB.
-	_, tipHeight := con.db.GetCompactionChainTipInfo()
+	tipHash, tipHeight := con.db.GetCompactionChainTipInfo()
 	if blocks[0].Position.Height != tipHeight+1 {
 		con.logger.Error("Mismatched block height",
 			"now", blocks[0].Position.Height,
@@ -349,23 +443,40 @@ func (con *Consensus) SyncBlocks(
 		"len", len(blocks),
 		"latest", latest,
 	)
+	if con.verifierWorkers > 0 {
+		if err = con.verifyBlocks(tipHash, blocks); err != nil {
+			con.logger.Error("Invalid block to sync", "error", err)
+			return
+		}
+	}
+	// Blocks and the tip of compaction chain are written atomically, a crash
+	// would never leave the tip pointing to a missing block.
+	batch := con.db.NewBatch()
 	for _, b := range blocks {
-		if err = con.db.PutBlock(*b); err != nil {
+		if err = batch.PutBlock(*b); err != nil {
 			// A block might be put into db when confirmed by BA, but not
 			// finalized yet.
 			if err == db.ErrBlockExists {
-				err = con.db.UpdateBlock(*b)
+				err = batch.UpdateBlock(*b)
 			}
 			if err != nil {
+				batch.Rollback()
 				return
 			}
 		}
-		if err = con.db.PutCompactionChainTipInfo(
+		if err = batch.PutCompactionChainTipInfo(
 			b.Hash, b.Position.Height); err != nil {
+			batch.Rollback()
 			return
 		}
+	}
+	if err = batch.Commit(); err != nil {
+		return
+	}
+	for _, b := range blocks {
 		con.heightEvt.NotifyHeight(b.Position.Height)
 	}
+	con.progress.sync(time.Now(), blocks[len(blocks)-1].Position.Height)
 	if latest {
 		con.assureBuffering()
 		con.buildAllEmptyBlocks()
@@ -375,12 +486,19 @@ func (con *Consensus) SyncBlocks(
 		if con.checkIfSynced(blocks) {
 			con.stopBuffering()
 			con.syncedLastBlock = blocks[len(blocks)-1]
+			con.progress.setPhase(SyncPhaseSynced)
 			synced = true
 		}
 	}
 	return
 }
 
+// Progress returns the progress of syncing, it's safe to be called
+// concurrently with other methods.
+func (con *Consensus) Progress() Progress {
+	return con.progress.progress(time.Now(), con.agreementModule.waitingCRS())
+}
+
 // GetSyncedConsensus returns the core.Consensus instance after synced.
 func (con *Consensus) GetSyncedConsensus() (*core.Consensus, error) {
 	con.lock.Lock()
@@ -400,6 +518,7 @@ func (con *Consensus) GetSyncedConsensus() (*core.Consensus, error) {
 		con.syncedLastBlock,
 		con.syncedSkipNext,
 		con.dMoment,
+		con.params,
 		con.app,
 		con.gov,
 		con.db,
@@ -0,0 +1,82 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package syncer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+var errGovernanceUnavailable = errors.New("governance unavailable")
+
+// unavailableGovernance fails all requests to configurations when set.
+type unavailableGovernance struct {
+	core.GovernanceV2
+	unavailable bool
+}
+
+func (g *unavailableGovernance) Configuration(
+	ctx context.Context, round uint64) (*types.Config, error) {
+	if g.unavailable {
+		return nil, errGovernanceUnavailable
+	}
+	return g.GovernanceV2.Configuration(ctx, round)
+}
+
+type ConsensusTestSuite struct {
+	suite.Suite
+}
+
+func (s *ConsensusTestSuite) TestWithGovernanceV2() {
+	prvKeys, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
+		pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
+		core.ConfigRoundShift)
+	s.Require().NoError(err)
+	govV2 := &unavailableGovernance{GovernanceV2: core.NewGovernanceV2(gov)}
+	dbInst, err := db.NewMemBackedDB()
+	s.Require().NoError(err)
+	newCon := func() (*Consensus, error) {
+		return NewConsensusWithGovernanceV2(context.Background(), 0,
+			time.Now().UTC(), types.DefaultParams(), nil, govV2,
+			core.RetryConfig{InitialBackoff: time.Millisecond, MaxAttempts: 3},
+			dbInst, nil, prvKeys[0], &common.NullLogger{})
+	}
+	con, err := newCon()
+	s.Require().NoError(err)
+	con.stopAgreement()
+	// An error is returned instead of panicking.
+	govV2.unavailable = true
+	_, err = newCon()
+	s.Require().Equal(errGovernanceUnavailable, err)
+}
+
+func TestConsensus(t *testing.T) {
+	suite.Run(t, new(ConsensusTestSuite))
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package syncer
+
+import (
+	"sync"
+	"time"
+)
+
+// progressWindow is the period of samples to estimate syncing rate.
+const progressWindow = time.Minute
+
+// maxProgressETA is the upper bound of estimated time remaining.
+const maxProgressETA = 7 * 24 * time.Hour
+
+// SyncPhase is the phase of syncer.
+type SyncPhase int
+
+// SyncPhase enums.
+const (
+	// SyncPhaseBuffering means blocks of compaction chain are written into
+	// database, agreement results are not followed yet.
+	SyncPhaseBuffering SyncPhase = iota
+	// SyncPhaseAgreement means agreement results are followed to catch up
+	// with BA.
+	SyncPhaseAgreement
+	// SyncPhaseWaitingCRS means some agreement results or blocks are not able
+	// to be verified until CRS of their rounds are ready.
+	SyncPhaseWaitingCRS
+	// SyncPhaseSynced means syncer is synced.
+	SyncPhaseSynced
+)
+
+func (p SyncPhase) String() string {
+	switch p {
+	case SyncPhaseBuffering:
+		return "buffering"
+	case SyncPhaseAgreement:
+		return "agreement"
+	case SyncPhaseWaitingCRS:
+		return "waiting-crs"
+	case SyncPhaseSynced:
+		return "synced"
+	}
+	return "unknown"
+}
+
+// Progress is a snapshot of the progress of syncer.
+type Progress struct {
+	Phase SyncPhase
+	// SyncedHeight is the height of compaction chain tip in database.
+	SyncedHeight uint64
+	// TargetHeight is the highest height of verified agreement results or
+	// finalized blocks received, it's never less than SyncedHeight.
+	TargetHeight uint64
+	// UnverifiedHeight is the highest height of agreement results or
+	// finalized blocks waiting for CRS of their rounds. They are not verified
+	// and might be forged, it's for reference only and never used to
+	// estimate ETA.
+	UnverifiedHeight uint64
+	// Rate is the count of blocks synced per second recently.
+	Rate float64
+	// ETA is the estimated time remaining to reach the target height, it's 0
+	// when there is nothing to sync or the rate is unknown. It's capped at
+	// one week.
+	ETA time.Duration
+}
+
+type progressSample struct {
+	when   time.Time
+	height uint64
+}
+
+// progressMeter records heights synced by syncer to estimate the progress.
+type progressMeter struct {
+	lock       sync.RWMutex
+	phase      SyncPhase
+	synced     uint64
+	target     uint64
+	unverified uint64
+	samples    []progressSample
+}
+
+func newProgressMeter(height uint64) *progressMeter {
+	return &progressMeter{
+		phase:  SyncPhaseBuffering,
+		synced: height,
+		target: height,
+	}
+}
+
+// setPhase sets the phase, the phase never goes backward.
+func (m *progressMeter) setPhase(phase SyncPhase) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if phase > m.phase {
+		m.phase = phase
+	}
+}
+
+// sync records the synced height.
+func (m *progressMeter) sync(now time.Time, height uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if height <= m.synced {
+		return
+	}
+	m.synced = height
+	if height > m.target {
+		m.target = height
+	}
+	m.samples = append(m.samples, progressSample{when: now, height: height})
+	m.purge(now)
+}
+
+// observe records a height confirmed by others, it should be verified.
+func (m *progressMeter) observe(height uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if height > m.target {
+		m.target = height
+	}
+}
+
+// observeUnverified records a height not able to be verified yet.
+func (m *progressMeter) observeUnverified(height uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if height > m.unverified {
+		m.unverified = height
+	}
+}
+
+// purge removes samples out of window, the latest one is always kept.
+func (m *progressMeter) purge(now time.Time) {
+	i := 0
+	for i < len(m.samples)-1 && now.Sub(m.samples[i].when) > progressWindow {
+		i++
+	}
+	m.samples = m.samples[i:]
+}
+
+func (m *progressMeter) progress(now time.Time, waitingCRS bool) Progress {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.purge(now)
+	p := Progress{
+		Phase:            m.phase,
+		SyncedHeight:     m.synced,
+		TargetHeight:     m.target,
+		UnverifiedHeight: m.unverified,
+	}
+	if p.Phase == SyncPhaseAgreement && waitingCRS {
+		p.Phase = SyncPhaseWaitingCRS
+	}
+	if len(m.samples) > 0 {
+		// The rate is calculated until now, so it drops when syncing stalls.
+		first := m.samples[0]
+		if elapsed := now.Sub(first.when); elapsed > 0 {
+			p.Rate = float64(m.synced-first.height) / elapsed.Seconds()
+		}
+	}
+	if p.Rate > 0 && p.TargetHeight > p.SyncedHeight {
+		// Compare in seconds to avoid overflowing time.Duration.
+		eta := float64(p.TargetHeight-p.SyncedHeight) / p.Rate
+		if eta >= maxProgressETA.Seconds() {
+			p.ETA = maxProgressETA
+		} else {
+			p.ETA = time.Duration(eta * float64(time.Second))
+		}
+	}
+	return p
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package syncer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+type ProgressTestSuite struct {
+	suite.Suite
+}
+
+func (s *ProgressTestSuite) TestMeter() {
+	var (
+		m   = newProgressMeter(10)
+		now = time.Now()
+	)
+	p := m.progress(now, false)
+	s.Require().Equal(Progress{
+		Phase:        SyncPhaseBuffering,
+		SyncedHeight: 10,
+		TargetHeight: 10,
+	}, p)
+	// Sync 100 blocks in 10 seconds, with 400 blocks left.
+	m.observe(510)
+	m.sync(now, 20)
+	now = now.Add(10 * time.Second)
+	m.sync(now, 110)
+	p = m.progress(now, false)
+	s.Require().Equal(uint64(110), p.SyncedHeight)
+	s.Require().Equal(uint64(510), p.TargetHeight)
+	s.Require().InDelta(9.0, p.Rate, 0.001)
+	s.Require().InDelta(400/9.0, p.ETA.Seconds(), 0.001)
+	// The rate drops when syncing stalls.
+	p = m.progress(now.Add(10*time.Second), false)
+	s.Require().InDelta(4.5, p.Rate, 0.001)
+	// Samples out of window are purged, the rate is unknown without progress
+	// in the window.
+	now = now.Add(2 * progressWindow)
+	p = m.progress(now, false)
+	s.Require().Zero(p.Rate)
+	s.Require().Zero(p.ETA)
+	// Synced height never goes backward, target height is never less than
+	// synced height.
+	m.sync(now, 100)
+	m.sync(now, 600)
+	p = m.progress(now, false)
+	s.Require().Equal(uint64(600), p.SyncedHeight)
+	s.Require().Equal(uint64(600), p.TargetHeight)
+	s.Require().Zero(p.ETA)
+	// ETA is capped.
+	m.observe(math.MaxUint64)
+	m.sync(now.Add(time.Second), 700)
+	p = m.progress(now.Add(time.Second), false)
+	s.Require().Equal(maxProgressETA, p.ETA)
+	// Phases.
+	m.setPhase(SyncPhaseAgreement)
+	s.Require().Equal(SyncPhaseAgreement, m.progress(now, false).Phase)
+	s.Require().Equal(SyncPhaseWaitingCRS, m.progress(now, true).Phase)
+	m.setPhase(SyncPhaseSynced)
+	m.setPhase(SyncPhaseAgreement)
+	s.Require().Equal(SyncPhaseSynced, m.progress(now, true).Phase)
+}
+
+func (s *ProgressTestSuite) TestSyncBlocks() {
+	_, pubKeys, err := test.NewKeys(4)
+	s.Require().NoError(err)
+	params := types.DefaultParams()
+	gov, err := test.NewGovernance(test.NewState(params, pubKeys,
+		100*time.Millisecond, &common.NullLogger{}, true),
+		core.ConfigRoundShift)
+	s.Require().NoError(err)
+	dbInst, err := db.NewMemBackedDB()
+	s.Require().NoError(err)
+	con := NewConsensus(0, time.Now(), params, nil, gov, dbInst, nil, nil,
+		&common.NullLogger{})
+	s.Require().Equal(Progress{Phase: SyncPhaseBuffering}, con.Progress())
+	blocks := []*types.Block{}
+	for h := uint64(1); h <= 5; h++ {
+		blocks = append(blocks, &types.Block{
+			Hash:       common.NewRandomHash(),
+			Position:   types.Position{Height: h},
+			Randomness: params.NoRand,
+		})
+	}
+	synced, err := con.SyncBlocks(blocks, false)
+	s.Require().NoError(err)
+	s.Require().False(synced)
+	p := con.Progress()
+	s.Require().Equal(SyncPhaseBuffering, p.Phase)
+	s.Require().Equal(uint64(5), p.SyncedHeight)
+	s.Require().Equal(uint64(5), p.TargetHeight)
+	// Forged results are not observed, results waiting for CRS are reported
+	// as unverified, the target is not affected by both. Inputs are processed
+	// in order, the forged one is processed when the unverified one is
+	// reported.
+	con.agreementModule.inputChan <- &types.AgreementResult{
+		BlockHash:  common.NewRandomHash(),
+		Position:   types.Position{Height: 1000},
+		Randomness: params.NoRand,
+	}
+	con.agreementModule.inputChan <- &types.AgreementResult{
+		BlockHash: common.NewRandomHash(),
+		Position:  types.Position{Round: 10, Height: math.MaxUint64},
+	}
+	deadline := time.Now().Add(time.Second)
+	for con.Progress().UnverifiedHeight != math.MaxUint64 &&
+		time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	p = con.Progress()
+	s.Require().Equal(uint64(math.MaxUint64), p.UnverifiedHeight)
+	s.Require().Equal(uint64(5), p.TargetHeight)
+	s.Require().Equal(SyncPhaseBuffering, p.Phase)
+}
+
+func TestProgress(t *testing.T) {
+	suite.Run(t, new(ProgressTestSuite))
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package syncer
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+var (
+	// ErrIncorrectParentHash is reported when a block doesn't link to the
+	// previous one.
+	ErrIncorrectParentHash = fmt.Errorf("incorrect parent hash")
+	// ErrProposerNotInNotarySet is reported when a block is proposed by a
+	// node not in the notary set of its round.
+	ErrProposerNotInNotarySet = fmt.Errorf("proposer not in notary set")
+	// ErrIncorrectRandomness is reported when the randomness of a block is
+	// not signed by DKG group of its round.
+	ErrIncorrectRandomness = fmt.Errorf("incorrect randomness")
+	// ErrDKGNotReady is reported when DKG group of a round is not ready to
+	// verify randomness.
+	ErrDKGNotReady = fmt.Errorf("DKG not ready")
+)
+
+// ErrInvalidBlock is reported by SyncBlocks when a block fails verification,
+// it's the first invalid one in the blocks passed in.
+type ErrInvalidBlock struct {
+	Block *types.Block
+	Err   error
+}
+
+func (e *ErrInvalidBlock) Error() string {
+	return fmt.Sprintf("invalid block %s: %v", e.Block, e.Err)
+}
+
+// verifyBlocks verifies blocks in parallel and checks they are linked one by
+// one from parentHash, the link to parentHash is not checked when it's empty.
+func (con *Consensus) verifyBlocks(
+	parentHash common.Hash, blocks []*types.Block) error {
+	var (
+		errs         = make([]error, len(blocks))
+		firstInvalid = int64(len(blocks))
+		indices      = make(chan int, len(blocks))
+		workers      = con.verifierWorkers
+		wg           sync.WaitGroup
+	)
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+	for i := range blocks {
+		indices <- i
+	}
+	close(indices)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				// Blocks after an invalid one are useless.
+				if int64(i) > atomic.LoadInt64(&firstInvalid) {
+					continue
+				}
+				if errs[i] = con.verifyBlock(blocks[i]); errs[i] == nil {
+					continue
+				}
+				for {
+					cur := atomic.LoadInt64(&firstInvalid)
+					if int64(i) >= cur || atomic.CompareAndSwapInt64(
+						&firstInvalid, cur, int64(i)) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for i, b := range blocks {
+		if (parentHash != common.Hash{}) && b.ParentHash != parentHash {
+			return &ErrInvalidBlock{Block: b, Err: ErrIncorrectParentHash}
+		}
+		if errs[i] != nil {
+			return &ErrInvalidBlock{Block: b, Err: errs[i]}
+		}
+		parentHash = b.Hash
+	}
+	return nil
+}
+
+// verifyBlock verifies the hash, proposer and randomness of a block.
+func (con *Consensus) verifyBlock(b *types.Block) error {
+	if b.IsEmpty() {
+		// Empty blocks are not signed by anyone, only their hashes are
+		// verified.
+		if len(b.Payload) > 0 &&
+			crypto.Keccak256Hash(b.Payload) != b.PayloadHash {
+			return utils.ErrIncorrectHash
+		}
+		hash, err := utils.HashBlock(b)
+		if err != nil {
+			return err
+		}
+		if hash != b.Hash {
+			return utils.ErrIncorrectHash
+		}
+	} else {
+		if err := utils.VerifyBlockSignature(b); err != nil {
+			return err
+		}
+		notarySet, err := con.nodeSetCache.GetNotarySet(b.Position.Round)
+		if err != nil {
+			return err
+		}
+		if _, exists := notarySet[b.ProposerID]; !exists {
+			return ErrProposerNotInNotarySet
+		}
+	}
+	if b.Position.Round < con.params.DKGDelayRound {
+		if !bytes.Equal(b.Randomness, con.params.NoRand) {
+			return ErrIncorrectRandomness
+		}
+		return nil
+	}
+	verifier, ok, err := con.tsigVerifier.UpdateAndGet(b.Position.Round)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDKGNotReady
+	}
+	if !verifier.VerifySignature(b.Hash, crypto.Signature{
+		Type:      "bls",
+		Signature: b.Randomness,
+	}) {
+		return ErrIncorrectRandomness
+	}
+	return nil
+}
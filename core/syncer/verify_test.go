@@ -0,0 +1,291 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package syncer
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+// verifyEnv prepares governance and signers to generate valid blocks.
+type verifyEnv struct {
+	params  types.Params
+	gov     *test.Governance
+	prvKeys []crypto.PrivateKey
+	signers []*utils.Signer
+	gs      *test.GroupSigner
+}
+
+func newVerifyEnv() (*verifyEnv, error) {
+	prvKeys, pubKeys, err := test.NewKeys(4)
+	if err != nil {
+		return nil, err
+	}
+	env := &verifyEnv{params: types.DefaultParams(), prvKeys: prvKeys}
+	env.gov, err = test.NewGovernance(test.NewState(env.params, pubKeys,
+		100*time.Millisecond, &common.NullLogger{}, true),
+		core.ConfigRoundShift)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range prvKeys {
+		env.signers = append(env.signers, utils.NewSigner(k))
+	}
+	if env.gs, err = test.PrepareDKG(env.gov, 1, prvKeys); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// newBlocks generates a chain of blocks following parent, blocks before
+// DKGDelayRound carry no randomness.
+func (env *verifyEnv) newBlocks(parent *types.Block, round uint64,
+	count int) ([]*types.Block, error) {
+	var (
+		blocks     = make([]*types.Block, 0, count)
+		height     = types.GenesisHeight
+		parentHash common.Hash
+	)
+	if parent != nil {
+		height = parent.Position.Height + 1
+		parentHash = parent.Hash
+	}
+	for i := 0; i < count; i++ {
+		b := &types.Block{
+			ParentHash: parentHash,
+			Position:   types.Position{Round: round, Height: height},
+			Timestamp:  time.Now().UTC(),
+			Payload:    common.NewRandomHash().Bytes(),
+		}
+		if err := env.signers[i%len(env.signers)].SignBlock(b); err != nil {
+			return nil, err
+		}
+		if err := env.finalize(b); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+		parentHash = b.Hash
+		height++
+	}
+	return blocks, nil
+}
+
+func (env *verifyEnv) finalize(b *types.Block) error {
+	if b.Position.Round < env.params.DKGDelayRound {
+		b.Randomness = env.params.NoRand
+		return nil
+	}
+	sig, err := env.gs.Sign(b.Hash)
+	if err != nil {
+		return err
+	}
+	b.Randomness = sig.Signature
+	return nil
+}
+
+func (env *verifyEnv) newSyncer(dbInst db.Database) *Consensus {
+	return NewConsensus(0, time.Now(), env.params, nil, env.gov, dbInst, nil,
+		env.prvKeys[0], &common.NullLogger{})
+}
+
+type VerifyTestSuite struct {
+	suite.Suite
+
+	env *verifyEnv
+}
+
+func (s *VerifyTestSuite) SetupSuite() {
+	var err error
+	s.env, err = newVerifyEnv()
+	s.Require().NoError(err)
+}
+
+func (s *VerifyTestSuite) newSyncer() *Consensus {
+	dbInst, err := db.NewMemBackedDB()
+	s.Require().NoError(err)
+	con := s.env.newSyncer(dbInst)
+	con.SetBlockVerification(true, 4)
+	return con
+}
+
+func (s *VerifyTestSuite) requireInvalid(
+	con *Consensus, blocks []*types.Block, invalid *types.Block, err error) {
+	_, tipHeight := con.db.GetCompactionChainTipInfo()
+	_, errSync := con.SyncBlocks(blocks, false)
+	s.Require().IsType(&ErrInvalidBlock{}, errSync)
+	s.Require().Equal(invalid.Hash, errSync.(*ErrInvalidBlock).Block.Hash)
+	s.Require().Equal(err, errSync.(*ErrInvalidBlock).Err)
+	// Nothing is written.
+	_, height := con.db.GetCompactionChainTipInfo()
+	s.Require().Equal(tipHeight, height)
+}
+
+func (s *VerifyTestSuite) TestValidBlocks() {
+	con := s.newSyncer()
+	defer con.stopAgreement()
+	blocks, err := s.env.newBlocks(nil, 0, 10)
+	s.Require().NoError(err)
+	_, err = con.SyncBlocks(blocks, false)
+	s.Require().NoError(err)
+	// Blocks with randomness signed by DKG group.
+	blocks, err = s.env.newBlocks(blocks[len(blocks)-1], 1, 10)
+	s.Require().NoError(err)
+	_, err = con.SyncBlocks(blocks, false)
+	s.Require().NoError(err)
+	// Empty blocks are not signed.
+	empty := &types.Block{
+		ParentHash: blocks[len(blocks)-1].Hash,
+		Position: types.Position{
+			Round: 1, Height: blocks[len(blocks)-1].Position.Height + 1},
+		Timestamp: time.Now().UTC(),
+	}
+	empty.Hash, err = utils.HashBlock(empty)
+	s.Require().NoError(err)
+	s.Require().NoError(s.env.finalize(empty))
+	_, err = con.SyncBlocks([]*types.Block{empty}, false)
+	s.Require().NoError(err)
+	hash, height := con.db.GetCompactionChainTipInfo()
+	s.Require().Equal(empty.Hash, hash)
+	s.Require().Equal(uint64(21), height)
+}
+
+func (s *VerifyTestSuite) TestInvalidBlocks() {
+	con := s.newSyncer()
+	defer con.stopAgreement()
+	genesis, err := s.env.newBlocks(nil, 0, 1)
+	s.Require().NoError(err)
+	_, err = con.SyncBlocks(genesis, false)
+	s.Require().NoError(err)
+	newBlocks := func() []*types.Block {
+		blocks, err := s.env.newBlocks(genesis[0], 1, 20)
+		s.Require().NoError(err)
+		return blocks
+	}
+	// Not linked to the tip in database.
+	blocks := newBlocks()
+	blocks[0].ParentHash = common.NewRandomHash()
+	s.Require().NoError(s.env.signers[0].SignBlock(blocks[0]))
+	s.Require().NoError(s.env.finalize(blocks[0]))
+	s.requireInvalid(con, blocks, blocks[0], ErrIncorrectParentHash)
+	// Not linked to the previous block.
+	blocks = newBlocks()
+	blocks[5].ParentHash = common.NewRandomHash()
+	s.Require().NoError(s.env.signers[0].SignBlock(blocks[5]))
+	s.Require().NoError(s.env.finalize(blocks[5]))
+	s.requireInvalid(con, blocks, blocks[5], ErrIncorrectParentHash)
+	// Tampered payload.
+	blocks = newBlocks()
+	blocks[3].Payload = []byte{1}
+	s.requireInvalid(con, blocks, blocks[3], utils.ErrIncorrectHash)
+	// Proposer not in notary set.
+	prvKey, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	blocks = newBlocks()
+	s.Require().NoError(utils.NewSigner(prvKey).SignBlock(blocks[7]))
+	s.Require().NoError(s.env.finalize(blocks[7]))
+	blocks[8].ParentHash = blocks[7].Hash
+	s.Require().NoError(s.env.signers[0].SignBlock(blocks[8]))
+	s.Require().NoError(s.env.finalize(blocks[8]))
+	s.requireInvalid(con, blocks, blocks[7], ErrProposerNotInNotarySet)
+	// Incorrect randomness.
+	blocks = newBlocks()
+	blocks[9].Randomness = blocks[8].Randomness
+	s.requireInvalid(con, blocks, blocks[9], ErrIncorrectRandomness)
+	// Randomness before DKGDelayRound should be NoRand.
+	blocks, err = s.env.newBlocks(genesis[0], 0, 3)
+	s.Require().NoError(err)
+	blocks[1].Randomness = []byte{1}
+	s.requireInvalid(con, blocks, blocks[1], ErrIncorrectRandomness)
+	// The first invalid block is reported.
+	blocks = newBlocks()
+	blocks[12].Payload = []byte{1}
+	blocks[15].Randomness = blocks[14].Randomness
+	blocks[4].Randomness = blocks[3].Randomness
+	s.requireInvalid(con, blocks, blocks[4], ErrIncorrectRandomness)
+	// Blocks are not verified when verification is disabled.
+	con.SetBlockVerification(false, 0)
+	_, err = con.SyncBlocks(blocks, false)
+	s.Require().NoError(err)
+}
+
+func TestVerify(t *testing.T) {
+	suite.Run(t, new(VerifyTestSuite))
+}
+
+// benchmarkBlocks is a chain of blocks shared by benchmarks, it's expensive to
+// generate.
+var benchmarkBlocks struct {
+	env    *verifyEnv
+	blocks []*types.Block
+}
+
+func benchmarkSyncBlocks(b *testing.B, verify bool, workers int) {
+	const count = 10000
+	if benchmarkBlocks.blocks == nil {
+		env, err := newVerifyEnv()
+		if err != nil {
+			b.Fatal(err)
+		}
+		blocks, err := env.newBlocks(nil, 1, count)
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchmarkBlocks.env, benchmarkBlocks.blocks = env, blocks
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		dbInst, err := db.NewMemBackedDB()
+		if err != nil {
+			b.Fatal(err)
+		}
+		con := benchmarkBlocks.env.newSyncer(dbInst)
+		con.SetBlockVerification(verify, workers)
+		b.StartTimer()
+		if _, err = con.SyncBlocks(benchmarkBlocks.blocks, false); err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+		con.stopAgreement()
+		b.StartTimer()
+	}
+}
+
+func BenchmarkSyncBlocks10kUnverified(b *testing.B) {
+	benchmarkSyncBlocks(b, false, 0)
+}
+
+func BenchmarkSyncBlocks10kSequential(b *testing.B) {
+	benchmarkSyncBlocks(b, true, 1)
+}
+
+func BenchmarkSyncBlocks10kParallel(b *testing.B) {
+	benchmarkSyncBlocks(b, true, runtime.NumCPU())
+}
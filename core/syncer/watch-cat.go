@@ -19,6 +19,7 @@ package syncer
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/dexon-foundation/dexon-consensus/common"
@@ -31,10 +32,95 @@ type configReader interface {
 	Configuration(round uint64) *types.Config
 }
 
+// ErrInvalidEscalationPolicy is reported when the escalation policy has no
+// stage, has stages not in ascending order of delays, or never proposes a skip
+// block.
+var ErrInvalidEscalationPolicy = fmt.Errorf("invalid escalation policy")
+
+// EscalationEvent is passed to callbacks of escalation policy.
+type EscalationEvent struct {
+	// Stage is the name of the stage entered, it's empty for recovered
+	// events.
+	Stage string
+	// Position is the last position fed to WatchCat.
+	Position types.Position
+	// Stalled is the duration since the last feed.
+	Stalled time.Duration
+	// Votes is the count of votes to skip the block after Position, it's
+	// only available for recovered events.
+	Votes uint64
+}
+
+// EscalationStage is a stage entered when WatchCat is not fed for a period.
+type EscalationStage struct {
+	// Name of this stage.
+	Name string
+	// After is the duration since the last feed to enter this stage.
+	After time.Duration
+	// ProposeSkip means a skip block is proposed via core.Recovery when
+	// entering this stage, it's proposed only once even if there are several
+	// such stages.
+	ProposeSkip bool
+	// OnEnter is called when entering this stage, it could be nil.
+	OnEnter func(EscalationEvent)
+}
+
+// EscalationPolicy decides how WatchCat escalates when it's not fed. Stages
+// before the first one proposing a skip block are alerts, they are reset when
+// WatchCat is fed again. After a skip block is proposed, WatchCat would not be
+// reset until Start is called again. All callbacks are called in the routine
+// of WatchCat and should not block.
+type EscalationPolicy struct {
+	// Stages should be in ascending order of After.
+	Stages []EscalationStage
+	// VoteThreshold returns the count of votes to be exceeded to skip the
+	// block, half of the notary set size is used when it's nil.
+	VoteThreshold func(config *types.Config) uint64
+	// OnRecovered is called when the votes exceed the threshold, right
+	// before the termination signal is produced. It could be nil.
+	OnRecovered func(EscalationEvent)
+}
+
+// DefaultEscalationPolicy proposes a skip block when WatchCat is not fed for
+// timeout, without any alert.
+func DefaultEscalationPolicy(timeout time.Duration) EscalationPolicy {
+	return EscalationPolicy{
+		Stages: []EscalationStage{{
+			Name:        "propose-skip",
+			After:       timeout,
+			ProposeSkip: true,
+		}},
+	}
+}
+
+func (p *EscalationPolicy) validate() error {
+	if len(p.Stages) == 0 {
+		return ErrInvalidEscalationPolicy
+	}
+	proposing := false
+	for i, stage := range p.Stages {
+		if i > 0 && stage.After < p.Stages[i-1].After {
+			return ErrInvalidEscalationPolicy
+		}
+		proposing = proposing || stage.ProposeSkip
+	}
+	if !proposing {
+		return ErrInvalidEscalationPolicy
+	}
+	return nil
+}
+
+func (p *EscalationPolicy) threshold(config *types.Config) uint64 {
+	if p.VoteThreshold != nil {
+		return p.VoteThreshold(config)
+	}
+	return uint64(config.NotarySetSize / 2)
+}
+
 // WatchCat is reponsible for signaling if syncer object should be terminated.
 type WatchCat struct {
 	recovery     core.Recovery
-	timeout      time.Duration
+	policy       EscalationPolicy
 	configReader configReader
 	feed         chan types.Position
 	lastPosition types.Position
@@ -44,7 +130,8 @@ type WatchCat struct {
 	logger       common.Logger
 }
 
-// NewWatchCat creats a new WatchCat 🐱 object.
+// NewWatchCat creats a new WatchCat 🐱 object, it proposes a skip block when
+// not fed for timeout.
 func NewWatchCat(
 	recovery core.Recovery,
 	configReader configReader,
@@ -53,7 +140,7 @@ func NewWatchCat(
 	logger common.Logger) *WatchCat {
 	wc := &WatchCat{
 		recovery:     recovery,
-		timeout:      timeout,
+		policy:       DefaultEscalationPolicy(timeout),
 		configReader: configReader,
 		feed:         make(chan types.Position),
 		polling:      polling,
@@ -62,6 +149,16 @@ func NewWatchCat(
 	return wc
 }
 
+// SetEscalationPolicy replaces the escalation policy, it should be called
+// before Start.
+func (wc *WatchCat) SetEscalationPolicy(policy EscalationPolicy) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+	wc.policy = policy
+	return nil
+}
+
 // Feed the WatchCat so it won't produce the termination signal.
 func (wc *WatchCat) Feed(position types.Position) {
 	wc.feed <- position
@@ -72,70 +169,113 @@ func (wc *WatchCat) Start() {
 	wc.Stop()
 	wc.lastPosition = types.Position{}
 	wc.ctx, wc.cancel = context.WithCancel(context.Background())
-	go func() {
-		var lastPos types.Position
-	MonitorLoop:
-		for {
-			select {
-			case <-wc.ctx.Done():
-				return
-			default:
+	go wc.run(wc.ctx, wc.policy)
+}
+
+// enterStage calls the callback of a stage.
+func (wc *WatchCat) enterStage(stage EscalationStage, lastPos types.Position,
+	lastFed time.Time) {
+	wc.logger.Warn("WatchCat escalates",
+		"stage", stage.Name,
+		"position", lastPos,
+		"stalled", time.Since(lastFed))
+	if stage.OnEnter != nil {
+		stage.OnEnter(EscalationEvent{
+			Stage:    stage.Name,
+			Position: lastPos,
+			Stalled:  time.Since(lastFed),
+		})
+	}
+}
+
+func (wc *WatchCat) run(ctx context.Context, policy EscalationPolicy) {
+	var (
+		lastPos types.Position
+		lastFed = time.Now()
+		stage   int
+	)
+MonitorLoop:
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case pos := <-wc.feed:
+			if !pos.Newer(lastPos) {
+				wc.logger.Warn("Feed with older height",
+					"pos", pos, "lastPos", lastPos)
+				continue
 			}
-			select {
-			case <-wc.ctx.Done():
-				return
-			case pos := <-wc.feed:
-				if !pos.Newer(lastPos) {
-					wc.logger.Warn("Feed with older height",
-						"pos", pos, "lastPos", lastPos)
-					continue
-				}
-				lastPos = pos
-			case <-time.After(wc.timeout):
+			lastPos = pos
+			lastFed = time.Now()
+			if stage > 0 {
+				wc.logger.Info("WatchCat is fed again", "position", pos)
+				stage = 0
+			}
+		case <-time.After(policy.Stages[stage].After - time.Since(lastFed)):
+			wc.enterStage(policy.Stages[stage], lastPos, lastFed)
+			stage++
+			if policy.Stages[stage-1].ProposeSkip {
 				break MonitorLoop
 			}
 		}
-		go func() {
-			for {
-				select {
-				case <-wc.ctx.Done():
-					return
-				case <-wc.feed:
-				}
-			}
-		}()
-		defer wc.cancel()
-		proposed := false
-		threshold := uint64(
-			utils.GetConfigWithPanic(wc.configReader, lastPos.Round, wc.logger).
-				NotarySetSize / 2)
-		wc.logger.Info("Threshold for recovery", "votes", threshold)
-	ResetLoop:
+	}
+	go func() {
 		for {
-			if !proposed {
-				wc.logger.Info("Calling Recovery.ProposeSkipBlock",
-					"height", lastPos.Height)
-				if err := wc.recovery.ProposeSkipBlock(lastPos.Height); err != nil {
-					wc.logger.Warn("Failed to proposeSkipBlock", "height", lastPos.Height, "error", err)
-				} else {
-					proposed = true
-				}
-			}
-			votes, err := wc.recovery.Votes(lastPos.Height)
-			if err != nil {
-				wc.logger.Error("Failed to get recovery votes", "height", lastPos.Height, "error", err)
-			} else if votes > threshold {
-				wc.logger.Info("Threshold for recovery reached!")
-				wc.lastPosition = lastPos
-				break ResetLoop
-			}
 			select {
-			case <-wc.ctx.Done():
+			case <-ctx.Done():
 				return
-			case <-time.After(wc.polling):
+			case <-wc.feed:
 			}
 		}
 	}()
+	defer wc.cancel()
+	proposed := false
+	threshold := policy.threshold(
+		utils.GetConfigWithPanic(wc.configReader, lastPos.Round, wc.logger))
+	wc.logger.Info("Threshold for recovery", "votes", threshold)
+ResetLoop:
+	for {
+		// Stages after proposing a skip block are still entered in time.
+		for stage < len(policy.Stages) &&
+			time.Since(lastFed) >= policy.Stages[stage].After {
+			wc.enterStage(policy.Stages[stage], lastPos, lastFed)
+			stage++
+		}
+		if !proposed {
+			wc.logger.Info("Calling Recovery.ProposeSkipBlock",
+				"height", lastPos.Height)
+			if err := wc.recovery.ProposeSkipBlock(lastPos.Height); err != nil {
+				wc.logger.Warn("Failed to proposeSkipBlock", "height", lastPos.Height, "error", err)
+			} else {
+				proposed = true
+			}
+		}
+		votes, err := wc.recovery.Votes(lastPos.Height)
+		if err != nil {
+			wc.logger.Error("Failed to get recovery votes", "height", lastPos.Height, "error", err)
+		} else if votes > threshold {
+			wc.logger.Info("Threshold for recovery reached!")
+			wc.lastPosition = lastPos
+			if policy.OnRecovered != nil {
+				policy.OnRecovered(EscalationEvent{
+					Position: lastPos,
+					Stalled:  time.Since(lastFed),
+					Votes:    votes,
+				})
+			}
+			break ResetLoop
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wc.polling):
+		}
+	}
 }
 
 // Stop the WatchCat.
@@ -17,6 +17,7 @@
 package syncer
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -24,6 +25,8 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/dexon-foundation/dexon-consensus/common"
+	recoverystore "github.com/dexon-foundation/dexon-consensus/core/recovery"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
 	"github.com/dexon-foundation/dexon-consensus/core/types"
 )
 
@@ -119,6 +122,105 @@ func (s *WatchCatTestSuite) TestBasicUsage() {
 	s.Equal(pos, watchCat.LastPosition())
 }
 
+func (s *WatchCatTestSuite) TestInvalidEscalationPolicy() {
+	watchCat, _ := s.newWatchCat(4, time.Second, time.Second)
+	// No stage.
+	s.Equal(ErrInvalidEscalationPolicy,
+		watchCat.SetEscalationPolicy(EscalationPolicy{}))
+	// No stage proposing skip block.
+	s.Equal(ErrInvalidEscalationPolicy,
+		watchCat.SetEscalationPolicy(EscalationPolicy{
+			Stages: []EscalationStage{{Name: "warn", After: time.Second}},
+		}))
+	// Stages not in ascending order.
+	s.Equal(ErrInvalidEscalationPolicy,
+		watchCat.SetEscalationPolicy(EscalationPolicy{
+			Stages: []EscalationStage{
+				{Name: "warn", After: 2 * time.Second},
+				{Name: "skip", After: time.Second, ProposeSkip: true},
+			},
+		}))
+	s.NoError(watchCat.SetEscalationPolicy(
+		DefaultEscalationPolicy(time.Second)))
+}
+
+func (s *WatchCatTestSuite) TestEscalationPolicy() {
+	var (
+		polling   = 10 * time.Millisecond
+		stage     = 100 * time.Millisecond
+		store     = recoverystore.NewMemStore()
+		notarySet = uint32(4)
+		lock      sync.Mutex
+		entered   []string
+		recovered *EscalationEvent
+	)
+	onEnter := func(e EscalationEvent) {
+		lock.Lock()
+		defer lock.Unlock()
+		entered = append(entered, e.Stage)
+	}
+	enteredStages := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), entered...)
+	}
+	cfg := &testConfigAccessor{notarySetSize: notarySet}
+	nID := types.NodeID{Hash: common.NewRandomHash()}
+	rec := test.NewRecovery(store, nID)
+	watchCat := NewWatchCat(rec, cfg, polling, time.Hour, &common.NullLogger{})
+	s.Require().NoError(watchCat.SetEscalationPolicy(EscalationPolicy{
+		Stages: []EscalationStage{
+			{Name: "warn", After: stage, OnEnter: onEnter},
+			{Name: "skip", After: 2 * stage, ProposeSkip: true, OnEnter: onEnter},
+			{Name: "page", After: 3 * stage, OnEnter: onEnter},
+		},
+		// Recover once the vote from this node is made.
+		VoteThreshold: func(*types.Config) uint64 { return 0 },
+		OnRecovered: func(e EscalationEvent) {
+			lock.Lock()
+			defer lock.Unlock()
+			recovered = &e
+		},
+	}))
+	rec.SetFailure(errors.New("store is unavailable"))
+	watchCat.Start()
+	defer watchCat.Stop()
+	pos := types.Position{Height: 10}
+	watchCat.Feed(pos)
+	// Feeding before proposing skip block resets alerts.
+	time.Sleep(stage * 3 / 2)
+	s.Require().Equal([]string{"warn"}, enteredStages())
+	pos.Height++
+	watchCat.Feed(pos)
+	time.Sleep(stage * 3 / 2)
+	s.Require().Equal([]string{"warn", "warn"}, enteredStages())
+	// Stages after proposing skip block are entered even if the proposing
+	// failed.
+	time.Sleep(2 * stage)
+	s.Require().Equal([]string{"warn", "warn", "skip", "page"},
+		enteredStages())
+	select {
+	case <-watchCat.Meow():
+		s.FailNow("unexpected terminated")
+	default:
+	}
+	votes, err := store.Votes(pos.Height)
+	s.Require().NoError(err)
+	s.Require().Zero(votes)
+	rec.SetFailure(nil)
+	select {
+	case <-watchCat.Meow():
+	case <-time.After(10 * polling):
+		s.FailNow("expecting terminated")
+	}
+	s.Equal(pos, watchCat.LastPosition())
+	lock.Lock()
+	defer lock.Unlock()
+	s.Require().NotNil(recovered)
+	s.Equal(pos, recovered.Position)
+	s.Equal(uint64(1), recovered.Votes)
+}
+
 func TestWatchCat(t *testing.T) {
 	suite.Run(t, new(WatchCatTestSuite))
 }
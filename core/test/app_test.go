@@ -65,7 +65,8 @@ func (s *AppTestSuite) SetupSuite() {
 
 func (s *AppTestSuite) prepareGov() *Governance {
 	gov, err := NewGovernance(
-		NewState(1, s.pubKeys, 100*time.Millisecond, s.logger, true),
+		NewState(types.DefaultParams(), s.pubKeys, 100*time.Millisecond,
+			s.logger, true),
 		core.ConfigRoundShift)
 	s.Require().NoError(err)
 	return gov
@@ -299,7 +300,7 @@ func (s *AppTestSuite) TestAttachedWithRoundEvent() {
 	s.proposeFinalize(gov, 22, 0, 3)
 	// Prepare utils.RoundEvent, starts from round#19, reset(for round#20)#1.
 	rEvt, err := utils.NewRoundEvent(context.Background(), gov, s.logger,
-		types.Position{Round: 19, Height: 2019}, core.ConfigRoundShift)
+		types.Position{Round: 19, Height: 2019}, types.DefaultParams())
 	s.Require().NoError(err)
 	// Register a handler to collects triggered events.
 	evts := make(chan evtParamToCheck, 3)
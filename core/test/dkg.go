@@ -0,0 +1,135 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	typesDKG "github.com/dexon-foundation/dexon-consensus/core/types/dkg"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+// GroupSigner signs threshold signatures with private shares of all nodes in
+// a DKG set.
+type GroupSigner struct {
+	shares map[dkg.ID]*dkg.PrivateKey
+}
+
+// Sign signs a hash with the group private key.
+func (gs *GroupSigner) Sign(hash common.Hash) (crypto.Signature, error) {
+	var (
+		psigs = make([]dkg.PartialSignature, 0, len(gs.shares))
+		IDs   = make(dkg.IDs, 0, len(gs.shares))
+	)
+	for ID, share := range gs.shares {
+		sig, err := share.Sign(hash)
+		if err != nil {
+			return crypto.Signature{}, err
+		}
+		psigs = append(psigs, dkg.PartialSignature(sig))
+		IDs = append(IDs, ID)
+	}
+	return dkg.RecoverSignature(psigs, IDs)
+}
+
+// PrepareDKG runs DKG for a round without any complaint among nodes owning
+// those private keys, and registers the results to governance. All nodes
+// should be in the notary set of that round.
+func PrepareDKG(gov *Governance, round uint64,
+	prvKeys []crypto.PrivateKey) (*GroupSigner, error) {
+	gov.CatchUpWithRound(round)
+	cfg := gov.Configuration(round)
+	if cfg == nil {
+		return nil, fmt.Errorf("configuration is not ready: %d", round)
+	}
+	var (
+		threshold = utils.GetDKGThreshold(cfg)
+		reset     = gov.DKGResetCount(round)
+		signers   = make([]*utils.Signer, 0, len(prvKeys))
+		IDs       = make(dkg.IDs, 0, len(prvKeys))
+		received  = make(map[dkg.ID]*dkg.PrivateKeyShares)
+	)
+	for _, prvKey := range prvKeys {
+		signers = append(signers, utils.NewSigner(prvKey))
+		ID := typesDKG.NewID(types.NewNodeID(prvKey.PublicKey()))
+		IDs = append(IDs, ID)
+		received[ID] = dkg.NewEmptyPrivateKeyShares()
+	}
+	for i, signer := range signers {
+		prvShares, pubShares := dkg.NewPrivateKeyShares(threshold)
+		prvShares.SetParticipants(IDs)
+		for _, ID := range IDs {
+			share, exists := prvShares.Share(ID)
+			if !exists {
+				return nil, fmt.Errorf("share not found: %v", ID)
+			}
+			if err := received[ID].AddShare(IDs[i], share); err != nil {
+				return nil, err
+			}
+		}
+		mpk := &typesDKG.MasterPublicKey{
+			Round:           round,
+			Reset:           reset,
+			DKGID:           IDs[i],
+			PublicKeyShares: *pubShares.Move(),
+		}
+		if err := signer.SignDKGMasterPublicKey(mpk); err != nil {
+			return nil, err
+		}
+		gov.AddDKGMasterPublicKey(mpk)
+	}
+	gs := &GroupSigner{
+		shares: make(map[dkg.ID]*dkg.PrivateKey, len(IDs)),
+	}
+	for _, ID := range IDs {
+		share, err := received[ID].RecoverPrivateKey(IDs)
+		if err != nil {
+			return nil, err
+		}
+		gs.shares[ID] = share
+	}
+	for _, signer := range signers {
+		ready := &typesDKG.MPKReady{Round: round, Reset: reset}
+		if err := signer.SignDKGMPKReady(ready); err != nil {
+			return nil, err
+		}
+		gov.AddDKGMPKReady(ready)
+	}
+	for _, signer := range signers {
+		final := &typesDKG.Finalize{Round: round, Reset: reset}
+		if err := signer.SignDKGFinalize(final); err != nil {
+			return nil, err
+		}
+		gov.AddDKGFinalize(final)
+	}
+	for _, signer := range signers {
+		success := &typesDKG.Success{Round: round, Reset: reset}
+		if err := signer.SignDKGSuccess(success); err != nil {
+			return nil, err
+		}
+		gov.AddDKGSuccess(success)
+	}
+	if !gov.IsDKGFinal(round) || !gov.IsDKGSuccess(round) {
+		return nil, fmt.Errorf("DKG is not prepared: %d", round)
+	}
+	return gs, nil
+}
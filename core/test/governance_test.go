@@ -39,12 +39,12 @@ func (s *GovernanceTestSuite) TestEqual() {
 	// Setup a base governance.
 	_, genesisNodes, err := NewKeys(20)
 	req.NoError(err)
-	g1, err := NewGovernance(NewState(
-		1, genesisNodes, 100*time.Millisecond, &common.NullLogger{}, true), 2)
+	g1, err := NewGovernance(NewState(types.DefaultParams(), genesisNodes,
+		100*time.Millisecond, &common.NullLogger{}, true), 2)
 	req.NoError(err)
 	// Create a governance with different lambda.
-	g2, err := NewGovernance(NewState(
-		1, genesisNodes, 50*time.Millisecond, &common.NullLogger{}, true), 2)
+	g2, err := NewGovernance(NewState(types.DefaultParams(), genesisNodes,
+		50*time.Millisecond, &common.NullLogger{}, true), 2)
 	req.NoError(err)
 	req.False(g1.Equal(g2, true))
 	// Create configs for 3 rounds for g1.
@@ -86,8 +86,8 @@ func (s *GovernanceTestSuite) TestRegisterChange() {
 	)
 	_, genesisNodes, err := NewKeys(20)
 	req.NoError(err)
-	g, err := NewGovernance(NewState(
-		1, genesisNodes, 100*time.Millisecond, &common.NullLogger{}, true), 2)
+	g, err := NewGovernance(NewState(types.DefaultParams(), genesisNodes,
+		100*time.Millisecond, &common.NullLogger{}, true), 2)
 	req.NoError(err)
 	req.NoError(g.State().RequestChange(StateChangeRoundLength,
 		uint64(roundLength)))
@@ -119,8 +119,8 @@ func (s *GovernanceTestSuite) TestProhibit() {
 	round := uint64(1)
 	prvKeys, genesisNodes, err := NewKeys(4)
 	s.Require().NoError(err)
-	gov, err := NewGovernance(NewState(
-		1, genesisNodes, 100*time.Millisecond, &common.NullLogger{}, true), 2)
+	gov, err := NewGovernance(NewState(types.DefaultParams(), genesisNodes,
+		100*time.Millisecond, &common.NullLogger{}, true), 2)
 	s.Require().NoError(err)
 	// Test MPK.
 	proposeMPK := func(k crypto.PrivateKey) {
@@ -199,6 +199,8 @@ type Network struct {
 	notarySetCaches      map[uint64]map[types.NodeID]struct{}
 	censor               NetworkCensor
 	censorLock           sync.RWMutex
+	pullHandlerLock      sync.RWMutex
+	pullHandler          func(*PullRequest)
 }
 
 // NewNetwork setup network stuffs for nodes, which provides an
@@ -261,6 +263,29 @@ func (n *Network) SetCensor(censorIn, censorOut NetworkCensor) {
 	}()
 }
 
+// SetPullRequestHandler replaces the default handler, which replies from
+// blocks and votes received by this network module, for incoming pull
+// requests. Passing nil restores the default one.
+func (n *Network) SetPullRequestHandler(h func(*PullRequest)) {
+	n.pullHandlerLock.Lock()
+	defer n.pullHandlerLock.Unlock()
+	n.pullHandler = h
+}
+
+// SendBlocks sends blocks to a peer.
+func (n *Network) SendBlocks(peer types.NodeID, blocks []*types.Block) {
+	for _, b := range blocks {
+		n.send(peer, b)
+	}
+}
+
+// SendVotes sends votes to a peer.
+func (n *Network) SendVotes(peer types.NodeID, votes []*types.Vote) {
+	for _, v := range votes {
+		n.send(peer, v)
+	}
+}
+
 // PullBlocks implements core.Network interface.
 func (n *Network) PullBlocks(hashes common.Hashes) {
 	go n.pullBlocksAsync(hashes)
@@ -431,6 +456,13 @@ func (n *Network) dispatchMsg(e *TransportEnvelope) {
 }
 
 func (n *Network) handlePullRequest(req *PullRequest) {
+	n.pullHandlerLock.RLock()
+	h := n.pullHandler
+	n.pullHandlerLock.RUnlock()
+	if h != nil {
+		h(req)
+		return
+	}
 	switch req.Type {
 	case "block":
 		hashes := req.Identity.(common.Hashes)
@@ -248,8 +248,8 @@ func (s *NetworkTestSuite) TestBroadcastToSet() {
 	)
 	_, pubKeys, err := NewKeys(peerCount)
 	req.NoError(err)
-	gov, err := NewGovernance(NewState(
-		1, pubKeys, time.Second, &common.NullLogger{}, true), 2)
+	gov, err := NewGovernance(NewState(types.DefaultParams(), pubKeys,
+		time.Second, &common.NullLogger{}, true), 2)
 	req.NoError(err)
 	req.NoError(gov.State().RequestChange(StateChangeNotarySetSize, uint32(1)))
 	gov.NotifyRound(round,
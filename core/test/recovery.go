@@ -0,0 +1,67 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"sync"
+
+	"github.com/dexon-foundation/dexon-consensus/core/recovery"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// Recovery implements core.Recovery for integration tests, votes are kept in
+// a recovery.Store shared by all nodes in a test. It could be set to fail to
+// simulate an unavailable store.
+type Recovery struct {
+	rec     *recovery.Recovery
+	lock    sync.RWMutex
+	failure error
+}
+
+// NewRecovery creates a Recovery for node nID voting in store.
+func NewRecovery(store recovery.Store, nID types.NodeID) *Recovery {
+	return &Recovery{rec: recovery.NewRecovery(store, nID)}
+}
+
+// SetFailure sets the error returned by all methods, passing nil recovers the
+// normal behavior.
+func (r *Recovery) SetFailure(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.failure = err
+}
+
+// ProposeSkipBlock implements core.Recovery interface.
+func (r *Recovery) ProposeSkipBlock(height uint64) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if r.failure != nil {
+		return r.failure
+	}
+	return r.rec.ProposeSkipBlock(height)
+}
+
+// Votes implements core.Recovery interface.
+func (r *Recovery) Votes(height uint64) (uint64, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if r.failure != nil {
+		return 0, r.failure
+	}
+	return r.rec.Votes(height)
+}
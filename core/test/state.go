@@ -123,17 +123,20 @@ type State struct {
 //  - node set
 //  - crs
 func NewState(
-	dkgDelayRound uint64,
+	params types.Params,
 	nodePubKeys []crypto.PublicKey,
 	lambda time.Duration,
 	logger common.Logger,
 	local bool) *State {
+	if err := params.Validate(); err != nil {
+		panic(err)
+	}
 	nodes := make(map[types.NodeID]crypto.PublicKey)
 	for _, key := range nodePubKeys {
 		nodes[types.NewNodeID(key)] = key
 	}
 	genesisCRS := crypto.Keccak256Hash([]byte("__ DEXON"))
-	crs := make([]common.Hash, dkgDelayRound+1)
+	crs := make([]common.Hash, params.DKGDelayRound+1)
 	for i := range crs {
 		crs[i] = genesisCRS
 		genesisCRS = crypto.Keccak256Hash(genesisCRS[:])
@@ -165,10 +165,12 @@ func (s *StateTestSuite) TestEqual() {
 	)
 	_, genesisNodes, err := NewKeys(20)
 	req.NoError(err)
-	st := NewState(1, genesisNodes, lambda, &common.NullLogger{}, true)
+	st := NewState(types.DefaultParams(), genesisNodes, lambda,
+		&common.NullLogger{}, true)
 	req.NoError(st.Equal(st))
 	// One node is missing.
-	st1 := NewState(1, genesisNodes, lambda, &common.NullLogger{}, true)
+	st1 := NewState(types.DefaultParams(), genesisNodes, lambda,
+		&common.NullLogger{}, true)
 	for nID := range st1.nodes {
 		delete(st1.nodes, nID)
 		break
@@ -234,7 +236,8 @@ func (s *StateTestSuite) TestPendingChangesEqual() {
 	// Setup a non-local mode State instance.
 	_, genesisNodes, err := NewKeys(20)
 	req.NoError(err)
-	st := NewState(1, genesisNodes, lambda, &common.NullLogger{}, false)
+	st := NewState(types.DefaultParams(), genesisNodes, lambda,
+		&common.NullLogger{}, false)
 	req.NoError(st.Equal(st))
 	// Apply some changes.
 	s.makeConfigChanges(st)
@@ -255,7 +258,8 @@ func (s *StateTestSuite) TestLocalMode() {
 	)
 	_, genesisNodes, err := NewKeys(20)
 	req.NoError(err)
-	st := NewState(1, genesisNodes, lambda, &common.NullLogger{}, true)
+	st := NewState(types.DefaultParams(), genesisNodes, lambda,
+		&common.NullLogger{}, true)
 	config1, nodes1 := st.Snapshot()
 	req.True(s.compareNodes(genesisNodes, nodes1))
 	// Check settings of config1 affected by genesisNodes and lambda.
@@ -335,7 +339,8 @@ func (s *StateTestSuite) TestPacking() {
 	// Make config changes.
 	_, genesisNodes, err := NewKeys(20)
 	req.NoError(err)
-	st := NewState(1, genesisNodes, lambda, &common.NullLogger{}, false)
+	st := NewState(types.DefaultParams(), genesisNodes, lambda,
+		&common.NullLogger{}, false)
 	s.makeConfigChanges(st)
 	// Add new CRS.
 	crs := common.NewRandomHash()
@@ -403,8 +408,10 @@ func (s *StateTestSuite) TestRequestBroadcastAndPack() {
 	)
 	_, genesisNodes, err := NewKeys(20)
 	req.NoError(err)
-	st := NewState(1, genesisNodes, lambda, &common.NullLogger{}, false)
-	st1 := NewState(1, genesisNodes, lambda, &common.NullLogger{}, false)
+	st := NewState(types.DefaultParams(), genesisNodes, lambda,
+		&common.NullLogger{}, false)
+	st1 := NewState(types.DefaultParams(), genesisNodes, lambda,
+		&common.NullLogger{}, false)
 	req.NoError(st.Equal(st1))
 	// Make configuration changes.
 	s.makeConfigChanges(st)
@@ -454,7 +461,7 @@ func (s *StateTestSuite) TestRequestBroadcastAndPack() {
 func (s *StateTestSuite) TestUnmatchedResetCount() {
 	_, genesisNodes, err := NewKeys(20)
 	s.Require().NoError(err)
-	st := NewState(1, genesisNodes, 100*time.Millisecond,
+	st := NewState(types.DefaultParams(), genesisNodes, 100*time.Millisecond,
 		&common.NullLogger{}, true)
 	// Make sure the case in older version without reset won't fail.
 	mpk := s.newDKGMasterPublicKey(1, 0)
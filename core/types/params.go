@@ -0,0 +1,77 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package types
+
+import "errors"
+
+// Errors for invalid Params.
+var (
+	ErrZeroConfigRoundShift = errors.New("config round shift should be positive")
+	ErrZeroDKGDelayRound    = errors.New("dkg delay round should be positive")
+	ErrEmptyNoRand          = errors.New("no rand should not be empty")
+)
+
+// Params stands for protocol parameters fixed for the lifetime of a network.
+// All nodes in the same network should share identical values.
+type Params struct {
+	// ConfigRoundShift refers to the difference between block's round and
+	// config round derived from its state.
+	//
+	// For example, when round shift is 2, a block in round 0 should derive
+	// config for round 2.
+	ConfigRoundShift uint64
+	// DKGDelayRound refers to the round that first DKG is run.
+	//
+	// For example, when delay round is 1, new DKG will run at round 1. Round 0
+	// will have neither DKG nor CRS.
+	DKGDelayRound uint64
+	// NoRand is the magic placeholder for randomness field in blocks for
+	// blocks proposed before DKGDelayRound.
+	NoRand []byte
+}
+
+// Default values of Params used by DEXON networks.
+const (
+	DefaultConfigRoundShift uint64 = 2
+	DefaultDKGDelayRound    uint64 = 1
+	DefaultNoRand                  = "norand"
+)
+
+// DefaultParams returns the parameters used by DEXON networks.
+func DefaultParams() Params {
+	return Params{
+		ConfigRoundShift: DefaultConfigRoundShift,
+		DKGDelayRound:    DefaultDKGDelayRound,
+		NoRand:           []byte(DefaultNoRand),
+	}
+}
+
+// Validate checks if the parameters are usable, a zero Params is not.
+func (p Params) Validate() error {
+	if p.ConfigRoundShift == 0 {
+		return ErrZeroConfigRoundShift
+	}
+	// Round 0 uses genesis CRS and has no DKG.
+	if p.DKGDelayRound == 0 {
+		return ErrZeroDKGDelayRound
+	}
+	if len(p.NoRand) == 0 {
+		return ErrEmptyNoRand
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ParamsTestSuite struct {
+	suite.Suite
+}
+
+func (s *ParamsTestSuite) TestValidate() {
+	s.Require().NoError(DefaultParams().Validate())
+	s.Require().Equal(ErrZeroConfigRoundShift, Params{}.Validate())
+	p := DefaultParams()
+	p.DKGDelayRound = 0
+	s.Require().Equal(ErrZeroDKGDelayRound, p.Validate())
+	p = DefaultParams()
+	p.NoRand = nil
+	s.Require().Equal(ErrEmptyNoRand, p.Validate())
+}
+
+func TestParams(t *testing.T) {
+	suite.Run(t, new(ParamsTestSuite))
+}
@@ -157,9 +157,9 @@ func HashConfigurationBlock(
 
 // VerifyAgreementResult perform sanity check against a types.AgreementResult
 // instance.
-func VerifyAgreementResult(
-	res *types.AgreementResult, notarySet map[types.NodeID]struct{}) error {
-	if res.Position.Round >= DKGDelayRound {
+func VerifyAgreementResult(res *types.AgreementResult,
+	notarySet map[types.NodeID]struct{}, dkgDelayRound uint64) error {
+	if res.Position.Round >= dkgDelayRound {
 		if len(res.Randomness) == 0 {
 			return ErrMissingRandomness
 		}
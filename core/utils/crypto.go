@@ -121,7 +121,8 @@ func VerifyVoteSignature(vote *types.Vote) (bool, error) {
 	return true, nil
 }
 
-func hashCRS(block *types.Block, crs common.Hash) common.Hash {
+func hashCRS(
+	block *types.Block, crs common.Hash, dkgDelayRound uint64) common.Hash {
 	hashPos := HashPosition(block.Position)
 	if block.Position.Round < dkgDelayRound {
 		return crypto.Keccak256Hash(crs[:], hashPos[:], block.ProposerID.Hash[:])
@@ -130,9 +131,9 @@ func hashCRS(block *types.Block, crs common.Hash) common.Hash {
 }
 
 // VerifyCRSSignature verifies the CRS signature of types.Block.
-func VerifyCRSSignature(
-	block *types.Block, crs common.Hash, npks *typesDKG.NodePublicKeys) bool {
-	hash := hashCRS(block, crs)
+func VerifyCRSSignature(block *types.Block, crs common.Hash,
+	npks *typesDKG.NodePublicKeys, dkgDelayRound uint64) bool {
+	hash := hashCRS(block, crs, dkgDelayRound)
 	if block.Position.Round < dkgDelayRound {
 		return bytes.Compare(block.CRSSignature.Signature[:], hash[:]) == 0
 	}
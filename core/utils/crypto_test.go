@@ -127,7 +127,7 @@ func (s *CryptoTestSuite) TestVoteSignature() {
 }
 
 func (s *CryptoTestSuite) TestCRSSignature() {
-	dkgDelayRound = 1
+	dkgDelayRound := uint64(1)
 	crs := common.NewRandomHash()
 	prv, err := ecdsa.NewPrivateKey()
 	s.Require().NoError(err)
@@ -136,12 +136,12 @@ func (s *CryptoTestSuite) TestCRSSignature() {
 	block := &types.Block{
 		ProposerID: nID,
 	}
-	hash := hashCRS(block, crs)
+	hash := hashCRS(block, crs, dkgDelayRound)
 	block.CRSSignature.Signature = hash[:]
-	ok := VerifyCRSSignature(block, crs, nil)
+	ok := VerifyCRSSignature(block, crs, nil, dkgDelayRound)
 	s.True(ok)
 	block.Position.Height++
-	ok = VerifyCRSSignature(block, crs, nil)
+	ok = VerifyCRSSignature(block, crs, nil, dkgDelayRound)
 	s.False(ok)
 }
 
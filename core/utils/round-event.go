@@ -164,7 +164,7 @@ type RoundEvent struct {
 	config                  RoundBasedConfig
 	lastTriggeredRound      uint64
 	lastTriggeredResetCount uint64
-	roundShift              uint64
+	params                  types.Params
 	gpkInvalid              bool
 	ctx                     context.Context
 	ctxCancel               context.CancelFunc
@@ -172,17 +172,20 @@ type RoundEvent struct {
 
 // NewRoundEvent creates an RoundEvent instance.
 func NewRoundEvent(parentCtx context.Context, gov governanceAccessor,
-	logger common.Logger, initPos types.Position, roundShift uint64) (
+	logger common.Logger, initPos types.Position, params types.Params) (
 	*RoundEvent, error) {
 	// We need to generate valid ending block height of this round (taken
 	// DKG reset count into consideration).
-	logger.Info("new RoundEvent", "position", initPos, "shift", roundShift)
+	logger.Info("new RoundEvent",
+		"position", initPos,
+		"shift", params.ConfigRoundShift,
+		"dkg-delay", params.DKGDelayRound)
 	initConfig := GetConfigWithPanic(gov, initPos.Round, logger)
 	e := &RoundEvent{
 		gov:                gov,
 		logger:             logger,
 		lastTriggeredRound: initPos.Round,
-		roundShift:         roundShift,
+		params:             params,
 	}
 	e.ctx, e.ctxCancel = context.WithCancel(parentCtx)
 	e.config = RoundBasedConfig{}
@@ -301,10 +304,10 @@ func (e *RoundEvent) check(blockHeight, startRound uint64) (
 		)
 	}()
 	nextRound := e.lastTriggeredRound + 1
-	if nextRound >= startRound+e.roundShift {
+	if nextRound >= startRound+e.params.ConfigRoundShift {
 		// Avoid access configuration newer than last confirmed one over
-		// 'roundShift' rounds. Fullnode might crash if we access it before it
-		// knows.
+		// 'ConfigRoundShift' rounds. Fullnode might crash if we access it
+		// before it knows.
 		return
 	}
 	nextCfg := GetConfigWithPanic(e.gov, nextRound, e.logger)
@@ -322,7 +325,7 @@ func (e *RoundEvent) check(blockHeight, startRound uint64) (
 		// group public key again.
 		return
 	}
-	if nextRound >= dkgDelayRound {
+	if nextRound >= e.params.DKGDelayRound {
 		var ok bool
 		ok, e.gpkInvalid = IsDKGValid(
 			e.gov, e.logger, nextRound, e.lastTriggeredResetCount)
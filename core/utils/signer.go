@@ -42,6 +42,7 @@ type Signer struct {
 	pubKey     crypto.PublicKey
 	proposerID types.NodeID
 	blsSign    blsSigner
+	protection *SlashingProtection
 }
 
 // NewSigner constructs an Signer instance.
@@ -59,6 +60,16 @@ func (s *Signer) SetBLSSigner(signer blsSigner) {
 	s.blsSign = signer
 }
 
+// SetSlashingProtection sets the slashing protection consulted before signing
+// votes and blocks.
+func (s *Signer) SetSlashingProtection(sp *SlashingProtection) error {
+	if sp != nil && sp.NodeID() != s.proposerID {
+		return ErrSlashingProtectionNodeMismatch
+	}
+	s.protection = sp
+	return nil
+}
+
 // SignBlock signs a types.Block.
 func (s *Signer) SignBlock(b *types.Block) (err error) {
 	b.ProposerID = s.proposerID
@@ -66,6 +77,11 @@ func (s *Signer) SignBlock(b *types.Block) (err error) {
 	if b.Hash, err = HashBlock(b); err != nil {
 		return
 	}
+	if s.protection != nil {
+		if err = s.protection.CheckBlock(b); err != nil {
+			return
+		}
+	}
 	if b.Signature, err = s.prvKey.Sign(b.Hash); err != nil {
 		return
 	}
@@ -75,18 +91,24 @@ func (s *Signer) SignBlock(b *types.Block) (err error) {
 // SignVote signs a types.Vote.
 func (s *Signer) SignVote(v *types.Vote) (err error) {
 	v.ProposerID = s.proposerID
+	if s.protection != nil {
+		if err = s.protection.CheckVote(v); err != nil {
+			return
+		}
+	}
 	v.Signature, err = s.prvKey.Sign(HashVote(v))
 	return
 }
 
 // SignCRS signs CRS signature of types.Block.
-func (s *Signer) SignCRS(b *types.Block, crs common.Hash) (err error) {
+func (s *Signer) SignCRS(
+	b *types.Block, crs common.Hash, dkgDelayRound uint64) (err error) {
 	if b.ProposerID != s.proposerID {
 		err = ErrInvalidProposerID
 		return
 	}
 	if b.Position.Round < dkgDelayRound {
-		hash := hashCRS(b, crs)
+		hash := hashCRS(b, crs, dkgDelayRound)
 		b.CRSSignature = crypto.Signature{
 			Type:      "bls",
 			Signature: hash[:],
@@ -97,7 +119,8 @@ func (s *Signer) SignCRS(b *types.Block, crs common.Hash) (err error) {
 		err = ErrNoBLSSigner
 		return
 	}
-	b.CRSSignature, err = s.blsSign(b.Position.Round, hashCRS(b, crs))
+	b.CRSSignature, err = s.blsSign(
+		b.Position.Round, hashCRS(b, crs, dkgDelayRound))
 	return
 }
 
@@ -66,7 +66,7 @@ func (s *SignerTestSuite) TestVote() {
 }
 
 func (s *SignerTestSuite) TestCRS() {
-	dkgDelayRound = 1
+	dkgDelayRound := uint64(1)
 	k := s.setupSigner()
 	b := &types.Block{
 		ParentHash: common.NewRandomHash(),
@@ -77,11 +77,11 @@ func (s *SignerTestSuite) TestCRS() {
 		Timestamp: time.Now().UTC(),
 	}
 	crs := common.NewRandomHash()
-	s.Error(k.SignCRS(b, crs))
+	s.Error(k.SignCRS(b, crs, dkgDelayRound))
 	// Hash block before hash CRS.
 	s.NoError(k.SignBlock(b))
-	s.NoError(k.SignCRS(b, crs))
-	ok := VerifyCRSSignature(b, crs, nil)
+	s.NoError(k.SignCRS(b, crs, dkgDelayRound))
+	ok := VerifyCRSSignature(b, crs, nil, dkgDelayRound)
 	s.True(ok)
 }
 
@@ -0,0 +1,272 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// Errors for slashing protection.
+var (
+	ErrSignOlderPosition = errors.New(
+		"refuse to sign for position older than signed ones")
+	ErrSignConflictingVote = errors.New(
+		"refuse to sign vote conflicting with signed one")
+	ErrSignConflictingBlock = errors.New(
+		"refuse to sign block conflicting with signed one")
+	ErrSlashingProtectionNodeMismatch = errors.New(
+		"slashing protection records belong to another node")
+)
+
+// signedVote is the record of a signed vote, votes with the same type and
+// period but different block hash are forking votes.
+type signedVote struct {
+	Type      types.VoteType `json:"type"`
+	Period    uint64         `json:"period"`
+	BlockHash common.Hash    `json:"block_hash"`
+}
+
+// slashingProtectionRecords is the persisted part of SlashingProtection, it's
+// also the format for import/export.
+type slashingProtectionRecords struct {
+	NodeID types.NodeID `json:"node_id"`
+	// Votes signed at the highest vote position.
+	VotePosition types.Position `json:"vote_position"`
+	Votes        []signedVote   `json:"votes"`
+	// The highest block position proposed.
+	BlockPosition types.Position `json:"block_position"`
+	BlockHash     common.Hash    `json:"block_hash"`
+}
+
+func (r *slashingProtectionRecords) clone() *slashingProtectionRecords {
+	c := *r
+	c.Votes = append([]signedVote(nil), r.Votes...)
+	return &c
+}
+
+// SlashingProtection records the highest positions of votes and blocks signed
+// by a node, and refuses to sign anything conflicting with them. Records are
+// persisted to a file before any signature is released when a file path is
+// provided.
+type SlashingProtection struct {
+	path    string
+	records *slashingProtectionRecords
+	lock    sync.Mutex
+}
+
+// NewSlashingProtection creates a SlashingProtection instance for a node,
+// records would be loaded from the file at 'path' if exists. Records are kept
+// in memory only when 'path' is empty.
+func NewSlashingProtection(nID types.NodeID, path string) (
+	*SlashingProtection, error) {
+	sp := &SlashingProtection{
+		path:    path,
+		records: &slashingProtectionRecords{NodeID: nID},
+	}
+	if path == "" {
+		return sp, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sp, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	if err = sp.Import(f); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// NodeID returns the ID of the node protected.
+func (sp *SlashingProtection) NodeID() types.NodeID {
+	return sp.records.NodeID
+}
+
+// CheckVote checks if a vote is safe to sign and records it.
+func (sp *SlashingProtection) CheckVote(v *types.Vote) error {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	if v.Position.Older(sp.records.VotePosition) {
+		return ErrSignOlderPosition
+	}
+	records := sp.records.clone()
+	if v.Position.Newer(records.VotePosition) {
+		records.VotePosition = v.Position
+		records.Votes = nil
+	}
+	for _, signed := range records.Votes {
+		if signed.Type != v.Type || signed.Period != v.Period {
+			continue
+		}
+		if signed.BlockHash != v.BlockHash {
+			return ErrSignConflictingVote
+		}
+		// The same vote is signed before.
+		return nil
+	}
+	records.Votes = append(records.Votes, signedVote{
+		Type:      v.Type,
+		Period:    v.Period,
+		BlockHash: v.BlockHash,
+	})
+	return sp.commit(records)
+}
+
+// CheckBlock checks if a block is safe to sign and records it, the hash of the
+// block should be ready.
+func (sp *SlashingProtection) CheckBlock(b *types.Block) error {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	if b.Position.Older(sp.records.BlockPosition) {
+		return ErrSignOlderPosition
+	}
+	if b.Position.Equal(sp.records.BlockPosition) &&
+		(sp.records.BlockHash != common.Hash{}) {
+		if b.Hash != sp.records.BlockHash {
+			return ErrSignConflictingBlock
+		}
+		return nil
+	}
+	records := sp.records.clone()
+	records.BlockPosition = b.Position
+	records.BlockHash = b.Hash
+	return sp.commit(records)
+}
+
+// commit persists records and replaces the current one.
+func (sp *SlashingProtection) commit(records *slashingProtectionRecords) error {
+	if sp.path != "" {
+		if err := writeRecords(sp.path, records); err != nil {
+			return err
+		}
+	}
+	sp.records = records
+	return nil
+}
+
+// Export writes all records in JSON.
+func (sp *SlashingProtection) Export(w io.Writer) error {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	return json.NewEncoder(w).Encode(sp.records)
+}
+
+// Import merges records exported by Export, the result is always at least as
+// restrictive as both of them.
+func (sp *SlashingProtection) Import(r io.Reader) error {
+	imported := &slashingProtectionRecords{}
+	if err := json.NewDecoder(r).Decode(imported); err != nil {
+		return err
+	}
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	if imported.NodeID != sp.records.NodeID {
+		return ErrSlashingProtectionNodeMismatch
+	}
+	records := sp.records.clone()
+	if imported.VotePosition.Newer(records.VotePosition) {
+		records.VotePosition = imported.VotePosition
+		records.Votes = imported.Votes
+	} else if imported.VotePosition.Equal(records.VotePosition) {
+		for _, v := range imported.Votes {
+			duplicated := false
+			for _, signed := range records.Votes {
+				if signed.Type != v.Type || signed.Period != v.Period {
+					continue
+				}
+				if signed.BlockHash != v.BlockHash {
+					return ErrSignConflictingVote
+				}
+				duplicated = true
+				break
+			}
+			if !duplicated {
+				records.Votes = append(records.Votes, v)
+			}
+		}
+	}
+	if imported.BlockPosition.Newer(records.BlockPosition) {
+		records.BlockPosition = imported.BlockPosition
+		records.BlockHash = imported.BlockHash
+	} else if imported.BlockPosition.Equal(records.BlockPosition) {
+		switch {
+		case imported.BlockHash == records.BlockHash:
+		case records.BlockHash == common.Hash{}:
+			records.BlockHash = imported.BlockHash
+		case imported.BlockHash != common.Hash{}:
+			return ErrSignConflictingBlock
+		}
+	}
+	return sp.commit(records)
+}
+
+// writeRecords writes records to a temporary file and renames it, to make sure
+// the file is either the old one or the new one. The directory is synced after
+// renaming, or the new file might be lost after a crash and a vote could be
+// signed twice.
+func writeRecords(path string, records *slashingProtectionRecords) error {
+	buf, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath)
+	if _, err = f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = dir.Sync()
+	if errClose := dir.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
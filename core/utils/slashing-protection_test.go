@@ -0,0 +1,232 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+type SlashingProtectionTestSuite struct {
+	suite.Suite
+}
+
+func (s *SlashingProtectionTestSuite) newSigner(path string) (
+	*Signer, *SlashingProtection) {
+	prvKey, err := ecdsa.NewPrivateKey()
+	s.Require().NoError(err)
+	signer := NewSigner(prvKey)
+	sp, err := NewSlashingProtection(signer.proposerID, path)
+	s.Require().NoError(err)
+	s.Require().NoError(signer.SetSlashingProtection(sp))
+	return signer, sp
+}
+
+func (s *SlashingProtectionTestSuite) newVote(vType types.VoteType,
+	hash common.Hash, period uint64, height uint64) *types.Vote {
+	v := types.NewVote(vType, hash, period)
+	v.Position = types.Position{Round: 1, Height: height}
+	return v
+}
+
+func (s *SlashingProtectionTestSuite) newBlock(height uint64) *types.Block {
+	return &types.Block{
+		ParentHash: common.NewRandomHash(),
+		Position:   types.Position{Round: 1, Height: height},
+		Timestamp:  time.Now().UTC(),
+	}
+}
+
+func (s *SlashingProtectionTestSuite) TestVote() {
+	signer, _ := s.newSigner("")
+	hash := common.NewRandomHash()
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VoteInit, hash, 1, 10)))
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VotePreCom, hash, 1, 10)))
+	// Signing the same vote again is fine.
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VoteInit, hash, 1, 10)))
+	// Conflicting votes are refused.
+	s.Equal(ErrSignConflictingVote, signer.SignVote(
+		s.newVote(types.VoteInit, common.NewRandomHash(), 1, 10)))
+	s.Equal(ErrSignConflictingVote, signer.SignVote(
+		s.newVote(types.VotePreCom, types.SkipBlockHash, 1, 10)))
+	// Votes with different period or type are fine.
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VotePreCom, types.SkipBlockHash, 2, 10)))
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VoteCom, common.NewRandomHash(), 1, 10)))
+	// Votes for newer position are fine.
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VoteInit, common.NewRandomHash(), 1, 11)))
+	// Votes for older position are refused.
+	s.Equal(ErrSignOlderPosition, signer.SignVote(
+		s.newVote(types.VoteCom, common.NewRandomHash(), 3, 10)))
+	v := s.newVote(types.VoteInit, common.NewRandomHash(), 1, 11)
+	v.Position.Round = 0
+	s.Equal(ErrSignOlderPosition, signer.SignVote(v))
+	s.Empty(v.Signature.Signature)
+}
+
+func (s *SlashingProtectionTestSuite) TestBlock() {
+	signer, _ := s.newSigner("")
+	b := s.newBlock(10)
+	s.Require().NoError(signer.SignBlock(b))
+	// Signing the same block again is fine.
+	s.Require().NoError(signer.SignBlock(b))
+	// Another block at the same position is refused.
+	b2 := s.newBlock(10)
+	s.Equal(ErrSignConflictingBlock, signer.SignBlock(b2))
+	s.Empty(b2.Signature.Signature)
+	// Blocks at older position are refused.
+	s.Equal(ErrSignOlderPosition, signer.SignBlock(s.newBlock(9)))
+	s.Require().NoError(signer.SignBlock(s.newBlock(11)))
+	s.Equal(ErrSignConflictingBlock, signer.SignBlock(s.newBlock(11)))
+}
+
+func (s *SlashingProtectionTestSuite) TestPersistence() {
+	dir, err := ioutil.TempDir("", "dexon-slashing-protection")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "records.json")
+	signer, _ := s.newSigner(path)
+	hash := common.NewRandomHash()
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VoteInit, hash, 1, 10)))
+	b := s.newBlock(10)
+	s.Require().NoError(signer.SignBlock(b))
+	// Restart with the same file.
+	sp, err := NewSlashingProtection(signer.proposerID, path)
+	s.Require().NoError(err)
+	s.Require().NoError(signer.SetSlashingProtection(sp))
+	s.Equal(ErrSignConflictingVote, signer.SignVote(
+		s.newVote(types.VoteInit, common.NewRandomHash(), 1, 10)))
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VoteInit, hash, 1, 10)))
+	s.Equal(ErrSignConflictingBlock, signer.SignBlock(s.newBlock(10)))
+	s.Require().NoError(signer.SignBlock(b))
+	// No temporary file left.
+	files, err := ioutil.ReadDir(dir)
+	s.Require().NoError(err)
+	s.Len(files, 1)
+	// Records of another node can't be loaded.
+	_, err = NewSlashingProtection(
+		types.NodeID{Hash: common.NewRandomHash()}, path)
+	s.Equal(ErrSlashingProtectionNodeMismatch, err)
+	other, _ := s.newSigner("")
+	s.Equal(ErrSlashingProtectionNodeMismatch,
+		other.SetSlashingProtection(sp))
+}
+
+func (s *SlashingProtectionTestSuite) TestImportExport() {
+	signer, sp := s.newSigner("")
+	hash := common.NewRandomHash()
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VoteInit, hash, 1, 10)))
+	s.Require().NoError(signer.SignBlock(s.newBlock(10)))
+	buf := &bytes.Buffer{}
+	s.Require().NoError(sp.Export(buf))
+	exported := buf.Bytes()
+	// Migrate to another machine.
+	sp2, err := NewSlashingProtection(signer.proposerID, "")
+	s.Require().NoError(err)
+	s.Require().NoError(sp2.Import(bytes.NewReader(exported)))
+	s.Require().NoError(signer.SetSlashingProtection(sp2))
+	s.Equal(ErrSignConflictingVote, signer.SignVote(
+		s.newVote(types.VoteInit, common.NewRandomHash(), 1, 10)))
+	s.Equal(ErrSignConflictingBlock, signer.SignBlock(s.newBlock(10)))
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VotePreCom, hash, 1, 10)))
+	// Importing older records keeps newer ones.
+	s.Require().NoError(signer.SignBlock(s.newBlock(12)))
+	s.Require().NoError(sp2.Import(bytes.NewReader(exported)))
+	s.Equal(ErrSignOlderPosition, signer.SignBlock(s.newBlock(11)))
+	s.Equal(ErrSignConflictingVote, signer.SignVote(
+		s.newVote(types.VotePreCom, types.SkipBlockHash, 1, 10)))
+	// Merging conflicting records fails.
+	sp3, err := NewSlashingProtection(signer.proposerID, "")
+	s.Require().NoError(err)
+	s.Require().NoError(signer.SetSlashingProtection(sp3))
+	s.Require().NoError(signer.SignVote(
+		s.newVote(types.VoteInit, common.NewRandomHash(), 1, 10)))
+	s.Equal(ErrSignConflictingVote, sp3.Import(bytes.NewReader(exported)))
+	// Records of another node can't be imported.
+	_, sp4 := s.newSigner("")
+	s.Equal(ErrSlashingProtectionNodeMismatch,
+		sp4.Import(bytes.NewReader(exported)))
+}
+
+func TestSlashingProtection(t *testing.T) {
+	suite.Run(t, new(SlashingProtectionTestSuite))
+}
+
+func benchmarkSignVote(b *testing.B, persisted bool) {
+	prvKey, err := ecdsa.NewPrivateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	signer := NewSigner(prvKey)
+	path := ""
+	if persisted {
+		dir, err := ioutil.TempDir("", "dexcon-slashing")
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		path = filepath.Join(dir, "slashing.json")
+	}
+	sp, err := NewSlashingProtection(signer.proposerID, path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err = signer.SetSlashingProtection(sp); err != nil {
+		b.Fatal(err)
+	}
+	// Each iteration signs votes of one period, like what a notary does in
+	// a fast path BA.
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash := common.NewRandomHash()
+		for _, vType := range []types.VoteType{
+			types.VoteInit, types.VotePreCom, types.VoteCom} {
+			v := types.NewVote(vType, hash, 1)
+			v.Position = types.Position{Round: 1, Height: uint64(i)}
+			if err = signer.SignVote(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSignVote(b *testing.B) {
+	benchmarkSignVote(b, false)
+}
+func BenchmarkSignVotePersisted(b *testing.B) {
+	benchmarkSignVote(b, true)
+}
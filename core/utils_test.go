@@ -48,7 +48,7 @@ func (s *UtilsTestSuite) TestRemoveFromSortedUint32Slice() {
 func (s *UtilsTestSuite) TestVerifyAgreementResult() {
 	prvKeys, pubKeys, err := test.NewKeys(4)
 	s.Require().NoError(err)
-	gov, err := test.NewGovernance(test.NewState(DKGDelayRound,
+	gov, err := test.NewGovernance(test.NewState(types.DefaultParams(),
 		pubKeys, time.Second, &common.NullLogger{}, true), ConfigRoundShift)
 	s.Require().NoError(err)
 	cache := utils.NewNodeSetCache(gov)
@@ -74,52 +74,62 @@ func (s *UtilsTestSuite) TestVerifyAgreementResult() {
 	nSet, err := cache.GetNotarySet(pos.Round)
 	s.Require().NoError(err)
 	s.Require().NotEmpty(nSet)
-	s.Require().NoError(VerifyAgreementResult(baResult, nSet))
+	s.Require().NoError(VerifyAgreementResult(baResult, nSet, DKGDelayRound))
 
 	// Test negative case.
 	// All period should be the same.
 	baResult.Votes[1].Period++
-	s.Equal(ErrIncorrectVotePeriod, VerifyAgreementResult(baResult, nSet))
+	s.Equal(ErrIncorrectVotePeriod,
+		VerifyAgreementResult(baResult, nSet, DKGDelayRound))
 	baResult.Votes[1].Period--
 
 	// Blockhash should match the one in votes.
 	baResult.BlockHash = common.NewRandomHash()
-	s.Equal(ErrIncorrectVoteBlockHash, VerifyAgreementResult(baResult, nSet))
+	s.Equal(ErrIncorrectVoteBlockHash,
+		VerifyAgreementResult(baResult, nSet, DKGDelayRound))
 	baResult.BlockHash = hash
 
 	// Position should match.
 	baResult.Position.Height++
-	s.Equal(ErrIncorrectVotePosition, VerifyAgreementResult(baResult, nSet))
+	s.Equal(ErrIncorrectVotePosition,
+		VerifyAgreementResult(baResult, nSet, DKGDelayRound))
 	baResult.Position = pos
 
 	// types.VotePreCom is not accepted in agreement result.
 	baResult.Votes[0].Type = types.VotePreCom
-	s.Equal(ErrIncorrectVoteType, VerifyAgreementResult(baResult, nSet))
+	s.Equal(ErrIncorrectVoteType,
+		VerifyAgreementResult(baResult, nSet, DKGDelayRound))
 	baResult.Votes[0].Type = types.VoteCom
 
 	// Vote type should be the same.
 	baResult.Votes[1].Type = types.VoteFastCom
-	s.Equal(ErrIncorrectVoteType, VerifyAgreementResult(baResult, nSet))
+	s.Equal(ErrIncorrectVoteType,
+		VerifyAgreementResult(baResult, nSet, DKGDelayRound))
 	baResult.Votes[1].Type = types.VoteCom
 
 	// Only vote proposed by notarySet is valid.
 	baResult.Votes[0].ProposerID = types.NodeID{Hash: common.NewRandomHash()}
-	s.Equal(ErrIncorrectVoteProposer, VerifyAgreementResult(baResult, nSet))
+	s.Equal(ErrIncorrectVoteProposer,
+		VerifyAgreementResult(baResult, nSet, DKGDelayRound))
 	baResult.Votes[0].ProposerID = types.NewNodeID(pubKeys[0])
 
 	// Vote shuold have valid signature.
 	baResult.Votes[0].Signature, err = prvKeys[0].Sign(common.NewRandomHash())
 	s.Require().NoError(err)
-	s.Equal(ErrIncorrectVoteSignature, VerifyAgreementResult(baResult, nSet))
+	s.Equal(ErrIncorrectVoteSignature,
+		VerifyAgreementResult(baResult, nSet, DKGDelayRound))
 	s.Require().NoError(signers[0].SignVote(&baResult.Votes[0]))
 
 	// Unique votes shuold be more than threshold.
 	baResult.Votes = baResult.Votes[:1]
-	s.Equal(ErrNotEnoughVotes, VerifyAgreementResult(baResult, nSet))
+	s.Equal(ErrNotEnoughVotes,
+		VerifyAgreementResult(baResult, nSet, DKGDelayRound))
 	for range signers {
 		baResult.Votes = append(baResult.Votes, baResult.Votes[0])
 	}
-	s.Equal(ErrNotEnoughVotes, VerifyAgreementResult(baResult, nSet))
+	s.Equal(ErrNotEnoughVotes,
+		VerifyAgreementResult(baResult, nSet, DKGDelayRound))
+
 }
 
 func TestUtils(t *testing.T) {
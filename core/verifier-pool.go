@@ -0,0 +1,238 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+// signatureCacheSize is the count of verified signatures remembered by
+// signatureCache.
+const signatureCacheSize = 8192
+
+// signatureCache remembers signatures already verified, so modules processing
+// messages sequentially could skip expensive cryptographic operations for
+// messages verified by verifierPool.
+//
+// All methods are safe to call on a nil instance, which verifies everything
+// directly.
+type signatureCache struct {
+	cache *lru.Cache
+}
+
+func newSignatureCache(size int) *signatureCache {
+	cache, err := lru.New(size)
+	if err != nil {
+		panic(err)
+	}
+	return &signatureCache{cache: cache}
+}
+
+func (c *signatureCache) exist(key common.Hash) bool {
+	if c == nil {
+		return false
+	}
+	return c.cache.Contains(key)
+}
+
+func (c *signatureCache) add(key common.Hash) {
+	if c == nil {
+		return
+	}
+	c.cache.Add(key, struct{}{})
+}
+
+func voteSignatureKey(vote *types.Vote) common.Hash {
+	hash := utils.HashVote(vote)
+	return crypto.Keccak256Hash(hash[:], vote.Signature.Signature)
+}
+
+func blockSignatureKey(block *types.Block) common.Hash {
+	return crypto.Keccak256Hash(block.Hash[:], block.Signature.Signature)
+}
+
+func randomnessKey(hash common.Hash, randomness []byte) common.Hash {
+	return crypto.Keccak256Hash(hash[:], randomness)
+}
+
+// verifyVoteSignature works like utils.VerifyVoteSignature.
+func (c *signatureCache) verifyVoteSignature(vote *types.Vote) (bool, error) {
+	key := voteSignatureKey(vote)
+	if c.exist(key) {
+		return true, nil
+	}
+	ok, err := utils.VerifyVoteSignature(vote)
+	if err == nil && ok {
+		c.add(key)
+	}
+	return ok, err
+}
+
+// verifyBlockSignature works like utils.VerifyBlockSignature.
+func (c *signatureCache) verifyBlockSignature(block *types.Block) error {
+	key := blockSignatureKey(block)
+	if !c.exist(key) {
+		if err := utils.VerifyBlockSignature(block); err != nil {
+			return err
+		}
+		c.add(key)
+		return nil
+	}
+	// The signature is verified against block hash, make sure the content of
+	// this block still matches that hash.
+	if crypto.Keccak256Hash(block.Payload) != block.PayloadHash {
+		return utils.ErrIncorrectHash
+	}
+	hash, err := utils.HashBlock(block)
+	if err != nil {
+		return err
+	}
+	if hash != block.Hash {
+		return utils.ErrIncorrectHash
+	}
+	return nil
+}
+
+func (c *signatureCache) isRandomnessVerified(
+	hash common.Hash, randomness []byte) bool {
+	return c.exist(randomnessKey(hash, randomness))
+}
+
+func (c *signatureCache) addRandomness(hash common.Hash, randomness []byte) {
+	c.add(randomnessKey(hash, randomness))
+}
+
+type verifyJob struct {
+	msg  types.Msg
+	done chan struct{}
+}
+
+// verifierPool verifies signatures of incoming messages with a bounded set of
+// workers in parallel. Messages are handed out in the same order they are
+// submitted, no matter they pass verification or not. The verification
+// results are kept in signatureCache for the sequential processing stage.
+type verifierPool struct {
+	workers          int
+	jobs             chan *verifyJob
+	pending          chan *verifyJob
+	sigCache         *signatureCache
+	verifyRandomness func(common.Hash, uint64, []byte) (bool, error)
+}
+
+func newVerifierPool(workers, capacity int, sigCache *signatureCache,
+	verifyRandomness func(common.Hash, uint64, []byte) (bool, error)) (
+	p *verifierPool) {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &verifierPool{
+		workers:          workers,
+		jobs:             make(chan *verifyJob, capacity),
+		pending:          make(chan *verifyJob, capacity),
+		sigCache:         sigCache,
+		verifyRandomness: verifyRandomness,
+	}
+}
+
+// submit a message to be verified, it would block when the pool is full.
+func (p *verifierPool) submit(ctx context.Context, msg types.Msg) bool {
+	job := &verifyJob{msg: msg, done: make(chan struct{})}
+	select {
+	case p.pending <- job:
+	case <-ctx.Done():
+		return false
+	}
+	select {
+	case p.jobs <- job:
+	case <-ctx.Done():
+		return false
+	}
+	return true
+}
+
+// run launches workers and forwards messages to output in order, it blocks
+// until ctx is done.
+func (p *verifierPool) run(ctx context.Context, output chan<- types.Msg) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	wg.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case job := <-p.jobs:
+					p.verify(job.msg.Payload)
+					close(job.done)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	for {
+		var job *verifyJob
+		select {
+		case job = <-p.pending:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case output <- job.msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// verify signatures in a message, results are recorded in signatureCache.
+func (p *verifierPool) verify(msg interface{}) {
+	switch val := msg.(type) {
+	case *types.Vote:
+		p.sigCache.verifyVoteSignature(val)
+	case *types.Block:
+		if val.IsEmpty() {
+			// Empty blocks are not signed.
+			return
+		}
+		if err := p.sigCache.verifyBlockSignature(val); err != nil {
+			return
+		}
+		if val.IsFinalized() {
+			p.verifyRandomness(val.Hash, val.Position.Round, val.Randomness)
+		}
+	case *types.AgreementResult:
+		if len(val.Randomness) > 0 {
+			p.verifyRandomness(
+				val.BlockHash, val.Position.Round, val.Randomness)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+// newSignedVotes generates votes signed by 'count' nodes for the same block.
+func newSignedVotes(count int) ([]*types.Vote, error) {
+	prvKeys, _, err := test.NewKeys(count)
+	if err != nil {
+		return nil, err
+	}
+	hash := common.NewRandomHash()
+	votes := make([]*types.Vote, 0, count)
+	for _, k := range prvKeys {
+		v := types.NewVote(types.VoteCom, hash, 0)
+		v.Position = types.Position{Height: types.GenesisHeight}
+		if err := utils.NewSigner(k).SignVote(v); err != nil {
+			return nil, err
+		}
+		votes = append(votes, v)
+	}
+	return votes, nil
+}
+
+type VerifierPoolTestSuite struct {
+	suite.Suite
+}
+
+func (s *VerifierPoolTestSuite) TestSignatureCache() {
+	var (
+		req   = s.Require()
+		cache = newSignatureCache(signatureCacheSize)
+	)
+	votes, err := newSignedVotes(2)
+	req.NoError(err)
+	// A valid vote would be cached.
+	ok, err := cache.verifyVoteSignature(votes[0])
+	req.NoError(err)
+	req.True(ok)
+	req.True(cache.exist(voteSignatureKey(votes[0])))
+	// A vote with others' signature would not pass.
+	forged := *votes[1]
+	forged.Signature = votes[0].Signature
+	ok, _ = cache.verifyVoteSignature(&forged)
+	req.False(ok)
+	req.False(cache.exist(voteSignatureKey(&forged)))
+	// A block modified after verified should not pass.
+	prvKeys, _, err := test.NewKeys(1)
+	req.NoError(err)
+	b := &types.Block{
+		Position: types.Position{Height: types.GenesisHeight},
+		Payload:  []byte{1, 2, 3},
+	}
+	req.NoError(utils.NewSigner(prvKeys[0]).SignBlock(b))
+	req.NoError(cache.verifyBlockSignature(b))
+	req.True(cache.exist(blockSignatureKey(b)))
+	req.NoError(cache.verifyBlockSignature(b))
+	b.Payload = []byte{3, 2, 1}
+	req.Equal(utils.ErrIncorrectHash, cache.verifyBlockSignature(b))
+	b.PayloadHash = crypto.Keccak256Hash(b.Payload)
+	req.Equal(utils.ErrIncorrectHash, cache.verifyBlockSignature(b))
+	// Randomness.
+	hash := common.NewRandomHash()
+	req.False(cache.isRandomnessVerified(hash, []byte{1}))
+	cache.addRandomness(hash, []byte{1})
+	req.True(cache.isRandomnessVerified(hash, []byte{1}))
+	req.False(cache.isRandomnessVerified(hash, []byte{2}))
+	// A nil cache verifies everything directly.
+	var nilCache *signatureCache
+	ok, err = nilCache.verifyVoteSignature(votes[1])
+	req.NoError(err)
+	req.True(ok)
+	ok, _ = nilCache.verifyVoteSignature(&forged)
+	req.False(ok)
+	nilCache.addRandomness(hash, []byte{1})
+	req.False(nilCache.isRandomnessVerified(hash, []byte{1}))
+}
+
+func (s *VerifierPoolTestSuite) TestOrder() {
+	var (
+		req    = s.Require()
+		cache  = newSignatureCache(signatureCacheSize)
+		output = make(chan types.Msg, 100)
+		hash   = common.NewRandomHash()
+		rand   = []byte("randomness")
+	)
+	votes, err := newSignedVotes(64)
+	req.NoError(err)
+	// Break some of them.
+	broken := make(map[int]struct{})
+	for i := 0; i < len(votes); i += 5 {
+		votes[i].Period++
+		broken[i] = struct{}{}
+	}
+	pool := newVerifierPool(8, 10, cache,
+		func(h common.Hash, _ uint64, r []byte) (bool, error) {
+			if h == hash {
+				cache.addRandomness(h, r)
+				return true, nil
+			}
+			return false, nil
+		})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.run(ctx, output)
+	}()
+	go func() {
+		for i, v := range votes {
+			pool.submit(ctx, types.Msg{PeerID: i, Payload: v})
+		}
+		pool.submit(ctx, types.Msg{Payload: &types.AgreementResult{
+			BlockHash:  hash,
+			Position:   types.Position{Round: 1},
+			Randomness: rand,
+		}})
+	}()
+	for i := range votes {
+		msg := <-output
+		req.Equal(i, msg.PeerID)
+		_, isBroken := broken[i]
+		req.Equal(!isBroken, cache.exist(voteSignatureKey(votes[i])))
+	}
+	msg := <-output
+	req.IsType(&types.AgreementResult{}, msg.Payload)
+	req.True(cache.isRandomnessVerified(hash, rand))
+	cancel()
+	<-done
+}
+
+func TestVerifierPool(t *testing.T) {
+	suite.Run(t, new(VerifierPoolTestSuite))
+}
+
+func benchmarkVerifyVotes(b *testing.B, notarySetSize int, workers int) {
+	votes, err := newSignedVotes(notarySetSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if workers == 0 {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, v := range votes {
+				if ok, err := utils.VerifyVoteSignature(v); err != nil || !ok {
+					b.Fatal("unable to verify vote", v, err)
+				}
+			}
+		}
+		return
+	}
+	// Verify without cache to measure throughput of signature verification.
+	output := make(chan types.Msg, notarySetSize)
+	pool := newVerifierPool(workers, notarySetSize, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.run(ctx, output)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		go func() {
+			for _, v := range votes {
+				pool.submit(ctx, types.Msg{Payload: v})
+			}
+		}()
+		for range votes {
+			<-output
+		}
+	}
+}
+
+func BenchmarkVerifyVotesSequential7(b *testing.B) {
+	benchmarkVerifyVotes(b, 7, 0)
+}
+func BenchmarkVerifyVotesSequential31(b *testing.B) {
+	benchmarkVerifyVotes(b, 31, 0)
+}
+func BenchmarkVerifyVotesSequential101(b *testing.B) {
+	benchmarkVerifyVotes(b, 101, 0)
+}
+func BenchmarkVerifyVotesParallel7(b *testing.B) {
+	benchmarkVerifyVotes(b, 7, runtime.NumCPU())
+}
+func BenchmarkVerifyVotesParallel31(b *testing.B) {
+	benchmarkVerifyVotes(b, 31, runtime.NumCPU())
+}
+func BenchmarkVerifyVotesParallel101(b *testing.B) {
+	benchmarkVerifyVotes(b, 101, runtime.NumCPU())
+}
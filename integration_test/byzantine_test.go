@@ -111,6 +111,7 @@ func (s *ByzantineTestSuite) setupNodes(
 		// Now is the consensus module.
 		node.con = core.NewConsensus(
 			dMoment,
+			types.DefaultParams(),
 			node.app,
 			node.gov,
 			node.db,
@@ -153,7 +154,7 @@ func (s *ByzantineTestSuite) TestOneSlowNodeOneDeadNode() {
 	// run faster.
 	lambda := 100 * time.Millisecond
 	seedGov, err := test.NewGovernance(
-		test.NewState(core.DKGDelayRound,
+		test.NewState(types.DefaultParams(),
 			pubKeys, lambda, &common.NullLogger{}, true),
 		core.ConfigRoundShift)
 	req.NoError(err)
@@ -224,7 +225,7 @@ func (s *ByzantineTestSuite) TestOneNodeWithoutVote() {
 	// run faster.
 	lambda := 100 * time.Millisecond
 	seedGov, err := test.NewGovernance(
-		test.NewState(core.DKGDelayRound,
+		test.NewState(types.DefaultParams(),
 			pubKeys, lambda, &common.NullLogger{}, true),
 		core.ConfigRoundShift)
 	req.NoError(err)
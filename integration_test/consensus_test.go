@@ -20,8 +20,11 @@ package integration
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -29,7 +32,9 @@ import (
 	"github.com/dexon-foundation/dexon-consensus/common"
 	"github.com/dexon-foundation/dexon-consensus/core"
 	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/remote"
 	"github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/recovery"
 	"github.com/dexon-foundation/dexon-consensus/core/syncer"
 	"github.com/dexon-foundation/dexon-consensus/core/test"
 	"github.com/dexon-foundation/dexon-consensus/core/types"
@@ -77,6 +82,7 @@ type node struct {
 	rEvt    *utils.RoundEvent
 	db      db.Database
 	network *test.Network
+	params  types.Params
 	logger  common.Logger
 }
 
@@ -99,6 +105,7 @@ func unprohibitDKG(gov *test.Governance) {
 
 func (s *ConsensusTestSuite) setupNodes(
 	dMoment time.Time,
+	params types.Params,
 	prvKeys []crypto.PrivateKey,
 	seedGov *test.Governance) map[types.NodeID]*node {
 	var (
@@ -132,7 +139,7 @@ func (s *ConsensusTestSuite) setupNodes(
 		}
 		logger := common.NewCustomLogger(log.New(f, "", log.LstdFlags|log.Lmicroseconds))
 		rEvt, err := utils.NewRoundEvent(context.Background(), gov, logger,
-			types.Position{Height: types.GenesisHeight}, core.ConfigRoundShift)
+			types.Position{Height: types.GenesisHeight}, params)
 		s.Require().NoError(err)
 		nID := types.NewNodeID(k.PublicKey())
 		nodes[nID] = &node{
@@ -143,6 +150,7 @@ func (s *ConsensusTestSuite) setupNodes(
 			logger:  logger,
 			rEvt:    rEvt,
 			network: networkModule,
+			params:  params,
 		}
 		go func() {
 			defer wg.Done()
@@ -158,6 +166,7 @@ func (s *ConsensusTestSuite) setupNodes(
 		// Now is the consensus module.
 		node.con = core.NewConsensus(
 			dMoment,
+			node.params,
 			node.app,
 			node.gov,
 			node.db,
@@ -214,7 +223,7 @@ func (s *ConsensusTestSuite) syncBlocksWithSomeNode(
 			syncNode.app.BlockDelivered(b.Hash, b.Position, b.Randomness)
 			// Sync gov.
 			syncNode.gov.CatchUpWithRound(
-				b.Position.Round + core.ConfigRoundShift)
+				b.Position.Round + syncNode.params.ConfigRoundShift)
 		}
 		var synced bool
 		synced, err = syncerObj.SyncBlocks(compactionChainBlocks, true)
@@ -277,14 +286,14 @@ func (s *ConsensusTestSuite) TestSimple() {
 	// Setup seed governance instance. Give a short latency to make this test
 	// run faster.
 	seedGov, err := test.NewGovernance(
-		test.NewState(core.DKGDelayRound,
+		test.NewState(types.DefaultParams(),
 			pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
 		core.ConfigRoundShift)
 	req.NoError(err)
 	req.NoError(seedGov.State().RequestChange(
 		test.StateChangeRoundLength, uint64(100)))
 	// A short round interval.
-	nodes := s.setupNodes(dMoment, prvKeys, seedGov)
+	nodes := s.setupNodes(dMoment, types.DefaultParams(), prvKeys, seedGov)
 	for _, n := range nodes {
 		go n.con.Run()
 		defer n.con.Stop()
@@ -305,6 +314,149 @@ Loop:
 	s.verifyNodes(nodes)
 }
 
+func (s *ConsensusTestSuite) TestDifferentParams() {
+	if testing.Short() {
+		return
+	}
+	// Run two networks with different protocol parameters side by side in
+	// the same process, they should not interfere with each other.
+	var (
+		req        = s.Require()
+		peerCount  = 4
+		dMoment    = time.Now().UTC()
+		untilRound = uint64(4)
+		networks   = []types.Params{
+			types.DefaultParams(),
+			types.Params{
+				ConfigRoundShift: 3,
+				DKGDelayRound:    2,
+				NoRand:           []byte("devnet-norand"),
+			},
+		}
+		nodes = make([]map[types.NodeID]*node, 0, len(networks))
+	)
+	for _, params := range networks {
+		prvKeys, pubKeys, err := test.NewKeys(peerCount)
+		req.NoError(err)
+		seedGov, err := test.NewGovernance(
+			test.NewState(params,
+				pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
+			params.ConfigRoundShift)
+		req.NoError(err)
+		req.NoError(seedGov.State().RequestChange(
+			test.StateChangeRoundLength, uint64(100)))
+		nodes = append(nodes, s.setupNodes(dMoment, params, prvKeys, seedGov))
+	}
+	for _, ns := range nodes {
+		for _, n := range ns {
+			go n.con.Run()
+			defer n.con.Stop()
+		}
+	}
+Loop:
+	for {
+		<-time.After(5 * time.Second)
+		for _, ns := range nodes {
+			for _, n := range ns {
+				latestPos := n.app.GetLatestDeliveredPosition()
+				fmt.Println("latestPos", n.ID, &latestPos)
+				if latestPos.Round < untilRound {
+					continue Loop
+				}
+			}
+		}
+		break
+	}
+	for i, ns := range nodes {
+		s.verifyNodes(ns)
+		// Blocks before DKGDelayRound should carry the placeholder of their
+		// own network.
+		for _, n := range ns {
+			n.app.WithLock(func(app *test.App) {
+				for _, r := range app.Delivered {
+					if r.Pos.Round < networks[i].DKGDelayRound {
+						req.Equal(networks[i].NoRand, r.Rand)
+					} else {
+						req.NotEqual(networks[i].NoRand, r.Rand)
+					}
+				}
+			})
+		}
+	}
+}
+
+func (s *ConsensusTestSuite) TestRemoteSigner() {
+	if testing.Short() {
+		return
+	}
+	// Each node signs via a remote signer listening on a local Unix socket,
+	// node keys and DKG private shares are only held by remote signers.
+	var (
+		req        = s.Require()
+		peerCount  = 4
+		dMoment    = time.Now().UTC()
+		untilRound = uint64(3)
+	)
+	dir, err := ioutil.TempDir("", "dexon-remote-signer")
+	req.NoError(err)
+	defer os.RemoveAll(dir)
+	prvKeys, pubKeys, err := test.NewKeys(peerCount)
+	req.NoError(err)
+	remoteKeys := make([]crypto.PrivateKey, 0, len(prvKeys))
+	clients := make(map[types.NodeID]*remote.Client)
+	for i, k := range prvKeys {
+		address := filepath.Join(dir, fmt.Sprintf("signer.%d.sock", i))
+		l, err := net.Listen("unix", address)
+		req.NoError(err)
+		dkgKeys, err := remote.NewDKGKeyDir(
+			filepath.Join(dir, fmt.Sprintf("dkg.%d", i)))
+		req.NoError(err)
+		server := remote.NewServer(k, dkgKeys, &common.NullLogger{})
+		go server.Serve(l)
+		defer server.Close()
+		client, err := remote.Dial("unix", address, time.Second)
+		req.NoError(err)
+		defer client.Close()
+		remoteKey, err := client.PrivateKey()
+		req.NoError(err)
+		remoteKeys = append(remoteKeys, remoteKey)
+		clients[types.NewNodeID(k.PublicKey())] = client
+	}
+	seedGov, err := test.NewGovernance(
+		test.NewState(types.DefaultParams(),
+			pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
+		core.ConfigRoundShift)
+	req.NoError(err)
+	req.NoError(seedGov.State().RequestChange(
+		test.StateChangeRoundLength, uint64(100)))
+	nodes := s.setupNodes(dMoment, types.DefaultParams(), remoteKeys, seedGov)
+	for _, n := range nodes {
+		n.con.SetDKGSigner(clients[n.ID])
+		go n.con.Run()
+		defer n.con.Stop()
+	}
+Loop:
+	for {
+		<-time.After(5 * time.Second)
+		for _, n := range nodes {
+			latestPos := n.app.GetLatestDeliveredPosition()
+			fmt.Println("latestPos", n.ID, &latestPos)
+			if latestPos.Round < untilRound {
+				continue Loop
+			}
+		}
+		break
+	}
+	s.verifyNodes(nodes)
+	// DKG private shares are not saved by consensus.
+	for _, n := range nodes {
+		for round := core.DKGDelayRound; round < untilRound; round++ {
+			_, err := n.db.GetDKGPrivateKey(round, 0)
+			req.Equal(db.ErrDKGPrivateKeyDoesNotExist, err)
+		}
+	}
+}
+
 func (s *ConsensusTestSuite) TestSetSizeChange() {
 	var (
 		req        = s.Require()
@@ -321,7 +473,8 @@ func (s *ConsensusTestSuite) TestSetSizeChange() {
 	req.NoError(err)
 	// Setup seed governance instance.
 	seedGov, err := test.NewGovernance(
-		test.NewState(core.DKGDelayRound, pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
+		test.NewState(types.DefaultParams(), pubKeys, 100*time.Millisecond,
+			&common.NullLogger{}, true),
 		core.ConfigRoundShift)
 	req.NoError(err)
 	req.NoError(seedGov.State().RequestChange(
@@ -348,7 +501,7 @@ func (s *ConsensusTestSuite) TestSetSizeChange() {
 		test.StateChangeNotarySetSize, uint32(4)))
 	seedGov.CatchUpWithRound(3)
 	// Setup nodes.
-	nodes := s.setupNodes(dMoment, prvKeys, seedGov)
+	nodes := s.setupNodes(dMoment, types.DefaultParams(), prvKeys, seedGov)
 	// Pick master node, and register changes on it.
 	var pickedNode *node
 	for _, pickedNode = range nodes {
@@ -406,7 +559,7 @@ func (s *ConsensusTestSuite) TestSync() {
 	// Setup seed governance instance. Give a short latency to make this test
 	// run faster.
 	seedGov, err := test.NewGovernance(
-		test.NewState(core.DKGDelayRound,
+		test.NewState(types.DefaultParams(),
 			pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
 		core.ConfigRoundShift)
 	req.NoError(err)
@@ -415,7 +568,7 @@ func (s *ConsensusTestSuite) TestSync() {
 	seedGov.CatchUpWithRound(0)
 	seedGov.CatchUpWithRound(1)
 	// A short round interval.
-	nodes := s.setupNodes(dMoment, prvKeys, seedGov)
+	nodes := s.setupNodes(dMoment, types.DefaultParams(), prvKeys, seedGov)
 	// Choose the first node as "syncNode" that its consensus' Run() is called
 	// later.
 	syncNode := nodes[types.NewNodeID(pubKeys[0])]
@@ -476,6 +629,7 @@ ReachAlive:
 	syncerObj := syncer.NewConsensus(
 		0,
 		dMoment,
+		syncNode.params,
 		syncNode.app,
 		syncNode.gov,
 		syncNode.db,
@@ -575,7 +729,7 @@ func (s *ConsensusTestSuite) TestForceSync() {
 	// Setup seed governance instance. Give a short latency to make this test
 	// run faster.
 	seedGov, err := test.NewGovernance(
-		test.NewState(core.DKGDelayRound,
+		test.NewState(types.DefaultParams(),
 			pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
 		core.ConfigRoundShift)
 	req.NoError(err)
@@ -584,7 +738,7 @@ func (s *ConsensusTestSuite) TestForceSync() {
 	seedGov.CatchUpWithRound(0)
 	seedGov.CatchUpWithRound(1)
 	// A short round interval.
-	nodes := s.setupNodes(dMoment, prvKeys, seedGov)
+	nodes := s.setupNodes(dMoment, types.DefaultParams(), prvKeys, seedGov)
 	for _, n := range nodes {
 		go n.con.Run()
 	}
@@ -642,6 +796,7 @@ ReachStop:
 		syncerCon[nID] = syncer.NewConsensus(
 			latestHeight,
 			dMoment,
+			node.params,
 			node.app,
 			node.gov,
 			node.db,
@@ -710,6 +865,364 @@ Loop:
 	s.verifyNodes(nodes)
 }
 
+func (s *ConsensusTestSuite) TestSyncFromCheckpoint() {
+	// The checkpoint sync test case:
+	// - No configuration change.
+	// - One node does not run when all others starts until they pass a
+	//   checkpoint in the middle of round 3.
+	// - That node starts with an empty database seeded at the checkpoint, and
+	//   never syncs blocks before it.
+	var (
+		req        = s.Require()
+		peerCount  = 4
+		dMoment    = time.Now().UTC()
+		roundLen   = uint64(100)
+		cpRound    = uint64(3)
+		untilRound = uint64(5)
+		errChan    = make(chan error, 100)
+	)
+	prvKeys, pubKeys, err := test.NewKeys(peerCount)
+	req.NoError(err)
+	// Setup seed governance instance. Give a short latency to make this test
+	// run faster.
+	seedGov, err := test.NewGovernance(
+		test.NewState(types.DefaultParams(),
+			pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
+		core.ConfigRoundShift)
+	req.NoError(err)
+	req.NoError(seedGov.State().RequestChange(
+		test.StateChangeRoundLength, roundLen))
+	seedGov.CatchUpWithRound(0)
+	seedGov.CatchUpWithRound(1)
+	nodes := s.setupNodes(dMoment, types.DefaultParams(), prvKeys, seedGov)
+	syncNode := nodes[types.NewNodeID(pubKeys[0])]
+	syncNode.con = nil
+	sourceNode := nodes[types.NewNodeID(pubKeys[1])]
+	for _, n := range nodes {
+		n.rEvt.Register(purgeHandlerGen(n.network))
+		if n.ID != syncNode.ID {
+			go n.con.Run()
+			defer n.con.Stop()
+		}
+	}
+	// Clean syncNode's network receive channel, or it might exceed the limit
+	// and block other go routines.
+	dummyReceiverCtxCancel, dummyFinished := utils.LaunchDummyReceiver(
+		context.Background(), syncNode.network.ReceiveChan(), nil)
+	// There is no DKG reset in this test, round 3 begins at a fixed height.
+	cpHeight := types.GenesisHeight + cpRound*roundLen + roundLen/2
+ReachCheckpoint:
+	for {
+		select {
+		case err := <-errChan:
+			req.NoError(err)
+		case <-time.After(5 * time.Second):
+		}
+		for id, n := range nodes {
+			if id == syncNode.ID {
+				continue
+			}
+			pos := n.app.GetLatestDeliveredPosition()
+			if pos.Height <= cpHeight {
+				fmt.Println("latestPos", n.ID, &pos)
+				continue ReachCheckpoint
+			}
+		}
+		dummyReceiverCtxCancel()
+		<-dummyFinished
+		break
+	}
+	// Restore the state of syncNode's application and governance to the
+	// checkpoint. This action should be performed by fullnode in production
+	// mode, ex. downloading a state snapshot.
+	var cpBlock types.Block
+	for h := types.GenesisHeight; h <= cpHeight; h++ {
+		b, err := sourceNode.db.GetBlockByHeight(h)
+		req.NoError(err)
+		if err = syncNode.gov.State().Apply(b.Payload); err != nil {
+			req.Equal(test.ErrDuplicatedChange, err)
+		}
+		syncNode.app.BlockConfirmed(b)
+		syncNode.app.BlockDelivered(b.Hash, b.Position, b.Randomness)
+		syncNode.gov.CatchUpWithRound(
+			b.Position.Round + syncNode.params.ConfigRoundShift)
+		cpBlock = b
+	}
+	req.Equal(cpRound, cpBlock.Position.Round)
+	// The checkpoint is provided by another node and verified against DKG
+	// group keys of round 3.
+	cp, err := syncer.NewCheckpoint(&cpBlock, sourceNode.gov)
+	req.NoError(err)
+	f, err := os.Create("log.sync.log")
+	if err != nil {
+		panic(err)
+	}
+	logger := common.NewCustomLogger(log.New(f, "", log.LstdFlags|log.Lmicroseconds))
+	syncerObj, err := syncer.NewConsensusFromCheckpoint(
+		cp,
+		nil,
+		dMoment,
+		syncNode.params,
+		syncNode.app,
+		syncNode.gov,
+		syncNode.db,
+		syncNode.network,
+		prvKeys[0],
+		logger,
+	)
+	req.NoError(err)
+	runnerCtx, runnerCtxCancel := context.WithCancel(context.Background())
+	defer runnerCtxCancel()
+	go func() {
+		var (
+			syncedHeight = cpHeight + 1
+			err          error
+			syncedCon    *core.Consensus
+		)
+	SyncLoop:
+		for {
+			syncedCon, syncedHeight, err = s.syncBlocksWithSomeNode(
+				sourceNode, syncNode, syncerObj, syncedHeight)
+			if syncedCon != nil {
+				syncNode.con = syncedCon
+				go syncNode.con.Run()
+				go func() {
+					<-runnerCtx.Done()
+					syncNode.con.Stop()
+				}()
+				break SyncLoop
+			}
+			if err != nil {
+				errChan <- err
+				break SyncLoop
+			}
+			select {
+			case <-runnerCtx.Done():
+				break SyncLoop
+			case <-time.After(4 * time.Second):
+			}
+		}
+	}()
+	// Wait until all nodes, including syncNode, reach 'untilRound'.
+	go func() {
+	ReachFinished:
+		for {
+			time.Sleep(5 * time.Second)
+			for _, n := range nodes {
+				pos := n.app.GetLatestDeliveredPosition()
+				if pos.Round < untilRound {
+					fmt.Println("latestPos", n.ID, &pos)
+					continue ReachFinished
+				}
+			}
+			break
+		}
+		runnerCtxCancel()
+	}()
+	select {
+	case err := <-errChan:
+		req.NoError(err)
+	case <-runnerCtx.Done():
+	}
+	// Blocks before the checkpoint never reach syncNode's database.
+	_, err = syncNode.db.GetBlockByHeight(cpHeight - 1)
+	req.Equal(db.ErrBlockDoesNotExist, err)
+	_, err = syncNode.db.GetBlockByHeight(cpHeight)
+	req.NoError(err)
+	for _, n := range nodes {
+		if n.ID == syncNode.ID {
+			continue
+		}
+		req.NoError(syncNode.app.Compare(n.app))
+	}
+}
+
+func (s *ConsensusTestSuite) TestRecovery() {
+	// The recovery test case:
+	// - All nodes start and run until stallRound reached.
+	// - Votes are censored to stall the network, each node would escalate by
+	//   its WatchCat and vote to skip the stalled block in a shared store.
+	// - When votes are enough, all nodes restart with syncer.
+	var (
+		req        = s.Require()
+		peerCount  = 4
+		dMoment    = time.Now().UTC()
+		untilRound = uint64(3)
+		stallRound = uint64(1)
+		store      = recovery.NewMemStore()
+		eventsLock sync.Mutex
+		events     = make(map[types.NodeID][]string)
+	)
+	prvKeys, pubKeys, err := test.NewKeys(peerCount)
+	req.NoError(err)
+	// Setup seed governance instance. Give a short latency to make this test
+	// run faster.
+	seedGov, err := test.NewGovernance(
+		test.NewState(types.DefaultParams(),
+			pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
+		core.ConfigRoundShift)
+	req.NoError(err)
+	req.NoError(seedGov.State().RequestChange(
+		test.StateChangeRoundLength, uint64(100)))
+	seedGov.CatchUpWithRound(0)
+	seedGov.CatchUpWithRound(1)
+	nodes := s.setupNodes(dMoment, types.DefaultParams(), prvKeys, seedGov)
+	for _, n := range nodes {
+		go n.con.Run()
+	}
+ReachStall:
+	for {
+		<-time.After(5 * time.Second)
+		for _, n := range nodes {
+			pos := n.app.GetLatestDeliveredPosition()
+			fmt.Println("latestPos", n.ID, &pos)
+			if pos.Round < stallRound {
+				continue ReachStall
+			}
+		}
+		break
+	}
+	// Setup WatchCat for each node, the feeder would stop feeding when the
+	// network stalls.
+	stopFeeding := make(chan struct{})
+	watchCats := make(map[types.NodeID]*syncer.WatchCat, len(nodes))
+	for nID, n := range nodes {
+		nID := nID
+		record := func(e syncer.EscalationEvent) {
+			eventsLock.Lock()
+			defer eventsLock.Unlock()
+			events[nID] = append(events[nID], e.Stage)
+		}
+		wc := syncer.NewWatchCat(test.NewRecovery(store, nID), n.gov,
+			500*time.Millisecond, 6*time.Second, n.logger)
+		req.NoError(wc.SetEscalationPolicy(syncer.EscalationPolicy{
+			Stages: []syncer.EscalationStage{
+				{Name: "warn", After: 3 * time.Second, OnEnter: record},
+				{Name: "skip", After: 6 * time.Second, ProposeSkip: true,
+					OnEnter: record},
+			},
+			OnRecovered: func(e syncer.EscalationEvent) {
+				record(syncer.EscalationEvent{Stage: "recovered"})
+			},
+		}))
+		wc.Start()
+		defer wc.Stop()
+		watchCats[nID] = wc
+		go func(n *node) {
+			var lastPos types.Position
+			for {
+				select {
+				case <-stopFeeding:
+					return
+				case <-time.After(100 * time.Millisecond):
+				}
+				if pos := n.app.GetLatestDeliveredPosition(); pos.Newer(lastPos) {
+					lastPos = pos
+					wc.Feed(pos)
+				}
+			}
+		}(n)
+	}
+	for _, n := range nodes {
+		n.network.SetCensor(&voteCensor{}, &voteCensor{})
+	}
+	for nID, wc := range watchCats {
+		select {
+		case <-wc.Meow():
+		case <-time.After(time.Minute):
+			req.FailNow("not recovered", "node %s", nID)
+		}
+		fmt.Println("Recovered", nID, wc.LastPosition())
+	}
+	close(stopFeeding)
+	for nID := range nodes {
+		req.Equal([]string{"warn", "skip", "recovered"}, events[nID])
+	}
+	// Restart all nodes with syncer, like what a fullnode would do after
+	// recovery.
+	var latestHeight uint64
+	var latestNodeID types.NodeID
+	for _, n := range nodes {
+		n.con.Stop()
+		n.network.SetCensor(nil, nil)
+	}
+	for nID, n := range nodes {
+		_, height := n.db.GetCompactionChainTipInfo()
+		if height > latestHeight {
+			latestNodeID = nID
+			latestHeight = height
+		}
+	}
+	fmt.Println("Latest node", latestNodeID, latestHeight)
+	for nID, n := range nodes {
+		if nID == latestNodeID {
+			continue
+		}
+		n.app.ClearUndeliveredBlocks()
+	}
+	syncerCon := make(map[types.NodeID]*syncer.Consensus, len(nodes))
+	for _, prvKey := range prvKeys {
+		nID := types.NewNodeID(prvKey.PublicKey())
+		n := nodes[nID]
+		syncerCon[nID] = syncer.NewConsensus(
+			latestHeight,
+			dMoment,
+			n.params,
+			n.app,
+			n.gov,
+			n.db,
+			n.network,
+			prvKey,
+			n.logger,
+		)
+	}
+	targetNode := nodes[latestNodeID]
+	for nID, n := range nodes {
+		if nID == latestNodeID {
+			continue
+		}
+		syncedHeight := n.app.GetLatestDeliveredPosition().Height + 1
+		for syncedHeight <= latestHeight {
+			_, syncedHeight, err = s.syncBlocksWithSomeNode(
+				targetNode, n, syncerCon[nID], syncedHeight)
+			req.NoError(err)
+		}
+		fmt.Println("Synced", nID, syncedHeight)
+	}
+	latestPos := targetNode.app.GetLatestDeliveredPosition()
+	for _, n := range nodes {
+		_, height := n.db.GetCompactionChainTipInfo()
+		req.Equal(latestHeight, height)
+		req.Equal(latestPos, n.app.GetLatestDeliveredPosition())
+	}
+	for _, con := range syncerCon {
+		con.ForceSync(latestPos, true)
+	}
+	for nID, n := range nodes {
+		con, err := syncerCon[nID].GetSyncedConsensus()
+		req.NoError(err)
+		n.con = con
+	}
+	for _, n := range nodes {
+		go n.con.Run()
+		defer n.con.Stop()
+	}
+Loop:
+	for {
+		<-time.After(5 * time.Second)
+		for _, n := range nodes {
+			latestPos := n.app.GetLatestDeliveredPosition()
+			fmt.Println("latestPos", n.ID, &latestPos)
+			if latestPos.Round < untilRound {
+				continue Loop
+			}
+		}
+		// Oh ya.
+		break
+	}
+	s.verifyNodes(nodes)
+}
+
 func (s *ConsensusTestSuite) TestResetDKG() {
 	var (
 		req        = s.Require()
@@ -722,7 +1235,7 @@ func (s *ConsensusTestSuite) TestResetDKG() {
 	// Setup seed governance instance. Give a short latency to make this test
 	// run faster.
 	seedGov, err := test.NewGovernance(
-		test.NewState(core.DKGDelayRound,
+		test.NewState(types.DefaultParams(),
 			pubKeys, 100*time.Millisecond, &common.NullLogger{}, true),
 		core.ConfigRoundShift)
 	req.NoError(err)
@@ -730,7 +1243,7 @@ func (s *ConsensusTestSuite) TestResetDKG() {
 		test.StateChangeRoundLength, uint64(100)))
 	req.NoError(seedGov.State().RequestChange(
 		test.StateChangeNotarySetSize, uint32(4)))
-	nodes := s.setupNodes(dMoment, prvKeys, seedGov)
+	nodes := s.setupNodes(dMoment, types.DefaultParams(), prvKeys, seedGov)
 	for _, n := range nodes {
 		n.rEvt.Register(purgeHandlerGen(n.network))
 		// Round Height reference table:
@@ -65,7 +65,8 @@ func (s *RoundEventTestSuite) SetupSuite() {
 
 func (s *RoundEventTestSuite) prepareGov() *test.Governance {
 	gov, err := test.NewGovernance(
-		test.NewState(1, s.pubKeys, 100*time.Millisecond, s.logger, true),
+		test.NewState(types.DefaultParams(), s.pubKeys, 100*time.Millisecond,
+			s.logger, true),
 		core.ConfigRoundShift)
 	s.Require().NoError(err)
 	return gov
@@ -115,7 +116,7 @@ func (s *RoundEventTestSuite) TestFromRound0() {
 	gov.CatchUpWithRound(1)
 	// Prepare utils.RoundEvent, starts from genesis.
 	rEvt, err := utils.NewRoundEvent(context.Background(), gov, s.logger,
-		types.Position{Height: types.GenesisHeight}, core.ConfigRoundShift)
+		types.Position{Height: types.GenesisHeight}, types.DefaultParams())
 	s.Require().NoError(err)
 	// Register a handler to collects triggered events.
 	var evts []evtParamToCheck
@@ -175,7 +176,7 @@ func (s *RoundEventTestSuite) TestFromRoundN() {
 	s.proposeFinalize(gov, 22, 0, 3)
 	// Prepare utils.RoundEvent, starts from round#19, reset(for round#20)#1.
 	rEvt, err := utils.NewRoundEvent(context.Background(), gov, s.logger,
-		types.Position{Round: 19, Height: 2019}, core.ConfigRoundShift)
+		types.Position{Round: 19, Height: 2019}, types.DefaultParams())
 	s.Require().NoError(err)
 	// Register a handler to collects triggered events.
 	var evts []evtParamToCheck
@@ -213,7 +214,7 @@ func (s *RoundEventTestSuite) TestLastPeriod() {
 	gov.CatchUpWithRound(1)
 	// Prepare utils.RoundEvent, starts from genesis.
 	rEvt, err := utils.NewRoundEvent(context.Background(), gov, s.logger,
-		types.Position{Height: types.GenesisHeight}, core.ConfigRoundShift)
+		types.Position{Height: types.GenesisHeight}, types.DefaultParams())
 	s.Require().NoError(err)
 	begin, length := rEvt.LastPeriod()
 	s.Require().Equal(begin, uint64(1))
@@ -243,7 +244,7 @@ func (s *RoundEventTestSuite) TestTriggerInitEvent() {
 	gov.CatchUpWithRound(1)
 	// Prepare utils.RoundEvent, starts from genesis.
 	rEvt, err := utils.NewRoundEvent(context.Background(), gov, s.logger,
-		types.Position{Height: types.GenesisHeight}, core.ConfigRoundShift)
+		types.Position{Height: types.GenesisHeight}, types.DefaultParams())
 	s.Require().NoError(err)
 	// Register a handler to collects triggered events.
 	var evts []evtParamToCheck
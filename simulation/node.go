@@ -62,6 +62,7 @@ type node struct {
 	ID        types.NodeID
 	prvKey    crypto.PrivateKey
 	logger    common.Logger
+	params    types.Params
 	consensus *core.Consensus
 	cfg       *config.Config
 }
@@ -89,10 +90,11 @@ func newNode(prvKey crypto.PrivateKey, logger common.Logger,
 		panic(err)
 	}
 	// Sync config to state in governance.
+	params := types.DefaultParams()
 	gov, err := test.NewGovernance(
-		test.NewState(core.DKGDelayRound,
+		test.NewState(params,
 			[]crypto.PublicKey{pubKey}, time.Millisecond, logger, true),
-		core.ConfigRoundShift)
+		params.ConfigRoundShift)
 	if err != nil {
 		panic(err)
 	}
@@ -100,6 +102,7 @@ func newNode(prvKey crypto.PrivateKey, logger common.Logger,
 		ID:        id,
 		prvKey:    prvKey,
 		logger:    logger,
+		params:    params,
 		app:       newSimApp(id, netModule, gov.State()),
 		gov:       gov,
 		db:        dbInst,
@@ -151,7 +154,7 @@ readyLoop:
 			n.logger.Info(
 				"Receive 'selected-as-master' notification from server")
 			for _, c := range n.cfg.Node.Changes {
-				if c.Round <= core.ConfigRoundShift+1 {
+				if c.Round <= n.params.ConfigRoundShift+1 {
 					continue
 				}
 				n.logger.Info("Register config change", "change", c)
@@ -166,6 +169,7 @@ readyLoop:
 	// Setup Consensus.
 	n.consensus = core.NewConsensusForSimulation(
 		dMoment,
+		n.params,
 		n.app,
 		n.gov,
 		n.db,
@@ -216,7 +220,7 @@ func (n *node) prepareConfigs() {
 	n.gov.State().ProposeCRS(0, crypto.Keccak256Hash([]byte(cConfig.GenesisCRS))) // #nosec G104
 	// These rounds are not safe to be registered as pending state change
 	// requests.
-	for i := uint64(0); i <= core.ConfigRoundShift+1; i++ {
+	for i := uint64(0); i <= n.params.ConfigRoundShift+1; i++ {
 		n.logger.Info("Prepare config", "round", i)
 		prepareConfigs(i, n.cfg.Node.Changes, n.gov)
 	}